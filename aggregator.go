@@ -0,0 +1,240 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CandleCloseCallback func(instrumentKey string, interval Interval, candle Candle)
+
+// CandleAggregator builds OHLCV candles from ticks. Base candles are built
+// from ticks directly; every higher timeframe is cascaded from completed base
+// candles, so all timeframes agree with each other. Buckets are aligned to
+// the instrument's session open in IST (09:15 for NSE/BSE), so a 15m candle
+// covers 09:15-09:30 and the last hourly candle of the day is 15:15-15:30.
+//
+// Volume is the sum of last traded quantities seen, so it is only as complete
+// as the ticks delivered. A candle closes when a tick for a later bucket
+// arrives, or on Flush; call Flush periodically, or Run, so illiquid
+// instruments still close on time.
+type CandleAggregator struct {
+	base    Interval
+	baseDur time.Duration
+	higher  []aggTimeframe
+
+	mu      sync.Mutex
+	bars    map[barKey]*Candle
+	onClose []CandleCloseCallback
+}
+
+type aggTimeframe struct {
+	interval Interval
+	dur      time.Duration
+}
+
+type barKey struct {
+	instrumentKey string
+	interval      Interval
+}
+
+type closedCandle struct {
+	instrumentKey string
+	interval      Interval
+	candle        Candle
+}
+
+// NewCandleAggregator aggregates ticks into base candles and cascades them
+// into each higher interval, which must be a whole multiple of base.
+func NewCandleAggregator(base Interval, higher ...Interval) (*CandleAggregator, error) {
+	baseDur, ok := base.Duration()
+	if !ok || !base.SupportedBy(SourceAggregator) {
+		return nil, fmt.Errorf("interval %q cannot be aggregated", base)
+	}
+
+	a := &CandleAggregator{base: base, baseDur: baseDur, bars: make(map[barKey]*Candle)}
+	for _, h := range higher {
+		d, ok := h.Duration()
+		if !ok || !h.SupportedBy(SourceAggregator) {
+			return nil, fmt.Errorf("interval %q cannot be aggregated", h)
+		}
+		if d <= baseDur || d%baseDur != 0 {
+			return nil, fmt.Errorf("interval %q is not a multiple of base %q", h, base)
+		}
+		a.higher = append(a.higher, aggTimeframe{interval: h, dur: d})
+	}
+	return a, nil
+}
+
+// OnCandleClose registers fn for every completed candle at every interval.
+// Callbacks run on the goroutine that delivered the closing tick or Flush.
+func (a *CandleAggregator) OnCandleClose(fn CandleCloseCallback) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onClose = append(a.onClose, fn)
+}
+
+func (a *CandleAggregator) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(a.OnTick)
+}
+
+func (a *CandleAggregator) OnTick(tick Tick) {
+	t := tick.ReceivedAt
+	if tick.LTT > 0 {
+		t = time.UnixMilli(tick.LTT)
+	}
+	start := bucketStart(tick.InstrumentKey, t, a.baseDur)
+
+	a.mu.Lock()
+	var closed []closedCandle
+	k := barKey{tick.InstrumentKey, a.base}
+	bar := a.bars[k]
+	if bar != nil && start.Before(bar.Timestamp) {
+		// A late tick for a candle that has already closed.
+		a.mu.Unlock()
+		return
+	}
+	if bar != nil && start.After(bar.Timestamp) {
+		closed = a.closeBase(tick.InstrumentKey, *bar, closed)
+		bar = nil
+	}
+	if bar == nil {
+		bar = &Candle{Timestamp: start, Open: tick.LTP, High: tick.LTP, Low: tick.LTP}
+		a.bars[k] = bar
+	}
+	bar.High = max(bar.High, tick.LTP)
+	bar.Low = min(bar.Low, tick.LTP)
+	bar.Close = tick.LTP
+	bar.Volume += tick.LTQ
+	callbacks := a.onClose
+	a.mu.Unlock()
+
+	emit(callbacks, closed)
+}
+
+// Flush closes every candle whose bucket has ended by now, base candles
+// first so they still cascade into higher timeframes.
+func (a *CandleAggregator) Flush(now time.Time) {
+	a.mu.Lock()
+	var closed []closedCandle
+	for k, bar := range a.bars {
+		if k.interval != a.base {
+			continue
+		}
+		if !now.Before(bucketEnd(k.instrumentKey, bar.Timestamp, a.baseDur)) {
+			closed = a.closeBase(k.instrumentKey, *bar, closed)
+		}
+	}
+	for _, tf := range a.higher {
+		for k, bar := range a.bars {
+			if k.interval == tf.interval && !now.Before(bucketEnd(k.instrumentKey, bar.Timestamp, tf.dur)) {
+				closed = append(closed, closedCandle{k.instrumentKey, k.interval, *bar})
+				delete(a.bars, k)
+			}
+		}
+	}
+	callbacks := a.onClose
+	a.mu.Unlock()
+
+	emit(callbacks, closed)
+}
+
+// Run flushes until ctx is done, closing each candle once grace has passed
+// since its bucket ended. grace leaves room for ticks delivered late, which
+// are dropped once their candle has closed.
+func (a *CandleAggregator) Run(ctx context.Context, grace time.Duration) {
+	ticker := time.NewTicker(min(a.baseDur, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.Flush(now.Add(-grace))
+		}
+	}
+}
+
+// Current returns the candle still being built for an instrument and interval.
+func (a *CandleAggregator) Current(instrumentKey string, interval Interval) (Candle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bar, ok := a.bars[barKey{instrumentKey, interval}]
+	if !ok {
+		return Candle{}, false
+	}
+	return *bar, true
+}
+
+func emit(callbacks []CandleCloseCallback, closed []closedCandle) {
+	for _, c := range closed {
+		for _, fn := range callbacks {
+			fn(c.instrumentKey, c.interval, c.candle)
+		}
+	}
+}
+
+// closeBase closes a base candle and folds it into each higher timeframe,
+// closing those whose bucket it completes. Called with a.mu held.
+func (a *CandleAggregator) closeBase(instrumentKey string, bar Candle, closed []closedCandle) []closedCandle {
+	delete(a.bars, barKey{instrumentKey, a.base})
+	closed = append(closed, closedCandle{instrumentKey, a.base, bar})
+	barEnd := bucketEnd(instrumentKey, bar.Timestamp, a.baseDur)
+
+	for _, tf := range a.higher {
+		k := barKey{instrumentKey, tf.interval}
+		start := bucketStart(instrumentKey, bar.Timestamp, tf.dur)
+
+		hb := a.bars[k]
+		if hb != nil && !hb.Timestamp.Equal(start) {
+			// The previous bucket never saw its final base candle.
+			closed = append(closed, closedCandle{instrumentKey, tf.interval, *hb})
+			hb = nil
+		}
+		if hb == nil {
+			hb = &Candle{Timestamp: start, Open: bar.Open, High: bar.High, Low: bar.Low}
+			a.bars[k] = hb
+		}
+		hb.High = max(hb.High, bar.High)
+		hb.Low = min(hb.Low, bar.Low)
+		hb.Close = bar.Close
+		hb.Volume += bar.Volume
+
+		if !barEnd.Before(bucketEnd(instrumentKey, start, tf.dur)) {
+			closed = append(closed, closedCandle{instrumentKey, tf.interval, *hb})
+			delete(a.bars, k)
+		}
+	}
+	return closed
+}
+
+// bucketStart aligns t to a bucket of length d counted from the instrument's
+// session open on t's IST date. Daily buckets start at the open.
+func bucketStart(instrumentKey string, t time.Time, d time.Duration) time.Time {
+	t = t.In(IST)
+	y, mo, dd := t.Date()
+	open := time.Date(y, mo, dd, 0, 0, 0, 0, IST).Add(hoursFor(instrumentKey).open)
+	if d >= 24*time.Hour {
+		return open
+	}
+
+	off := t.Sub(open)
+	n := off / d
+	if off < 0 && off%d != 0 {
+		n--
+	}
+	return open.Add(n * d)
+}
+
+// bucketEnd is start+d, cut short at the session close.
+func bucketEnd(instrumentKey string, start time.Time, d time.Duration) time.Time {
+	s := start.In(IST)
+	y, mo, dd := s.Date()
+	closeAt := time.Date(y, mo, dd, 0, 0, 0, 0, IST).Add(hoursFor(instrumentKey).close)
+	end := start.Add(d)
+	if start.Before(closeAt) && end.After(closeAt) {
+		return closeAt
+	}
+	return end
+}
@@ -0,0 +1,172 @@
+package upstox
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrAuditChainBroken = errors.New("audit log chain broken")
+
+// AuditEntry records one order request sent to the API and what came back.
+// Hash covers every other field, including the previous entry's hash, so
+// editing, dropping or reordering entries breaks the chain.
+type AuditEntry struct {
+	Seq        uint64    `json:"seq"`
+	SentAt     time.Time `json:"sent_at"`
+	ReceivedAt time.Time `json:"received_at"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Request    string    `json:"request,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Response   string    `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+func (e AuditEntry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLog appends hash-chained entries to a writer as JSON lines. Any
+// io.Writer works; use OpenAuditLog to continue a chain in a file across
+// restarts. Request headers are never recorded, so the log holds no tokens.
+type AuditLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	last string
+}
+
+// NewAuditLog starts a new chain on w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog appends to the log file at path, verifying what is already
+// there and continuing its chain. The caller closes the returned file.
+func OpenAuditLog(path string) (*AuditLog, *os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	last, err := VerifyAuditLog(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return &AuditLog{w: f, seq: last.Seq, last: last.Hash}, f, nil
+}
+
+// Append chains e onto the log, filling in Seq, PrevHash and Hash.
+func (l *AuditLog) Append(e AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	e.PrevHash = l.last
+	e.Hash = e.computeHash()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if s, ok := l.w.(interface{ Sync() error }); ok {
+		if err := s.Sync(); err != nil {
+			return fmt.Errorf("failed to sync audit log: %w", err)
+		}
+	}
+
+	l.seq, l.last = e.Seq, e.Hash
+	return nil
+}
+
+// VerifyAuditLog reads a log from r and checks every hash and link, returning
+// the last entry. Errors wrap ErrAuditChainBroken with the offending line.
+func VerifyAuditLog(r io.Reader) (last AuditEntry, err error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return last, fmt.Errorf("%w: line %d: %v", ErrAuditChainBroken, line, err)
+		}
+		switch {
+		case e.Seq != last.Seq+1:
+			return last, fmt.Errorf("%w: line %d: sequence %d follows %d", ErrAuditChainBroken, line, e.Seq, last.Seq)
+		case e.PrevHash != last.Hash:
+			return last, fmt.Errorf("%w: line %d: does not link to previous entry", ErrAuditChainBroken, line)
+		case e.Hash != e.computeHash():
+			return last, fmt.Errorf("%w: line %d: hash mismatch", ErrAuditChainBroken, line)
+		}
+		last = e
+	}
+	if err := sc.Err(); err != nil {
+		return last, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return last, nil
+}
+
+// WithAuditLog records every order placement, modification and cancellation,
+// including GTT orders, sent through the Manager. A failure to write the log
+// is logged but does not fail the order, which has already been sent.
+func WithAuditLog(l *AuditLog) ManagerOption {
+	return func(m *Manager) {
+		m.audit = l
+	}
+}
+
+// audited reports whether req changes an order and so belongs in the audit log.
+func audited(req *http.Request) bool {
+	return req.Method != http.MethodGet && strings.Contains(req.URL.Path, "/order")
+}
+
+// roundTripAudited sends req like client.Do, recording the request body and
+// the full response. The response body is buffered and handed back intact.
+func (m *Manager) roundTripAudited(client *http.Client, req *http.Request) (*http.Response, error) {
+	e := AuditEntry{Method: req.Method, URL: req.URL.String()}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			e.Request = string(data)
+		}
+	}
+
+	e.SentAt = time.Now()
+	resp, err := client.Do(req)
+	e.ReceivedAt = time.Now()
+	if err != nil {
+		e.Error = err.Error()
+	} else {
+		e.StatusCode = resp.StatusCode
+		data, rerr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		e.Response = string(data)
+		if rerr != nil {
+			e.Error = rerr.Error()
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if aerr := m.audit.Append(e); aerr != nil {
+		m.logger.Error("audit log: append failed", "error", aerr)
+	}
+	return resp, err
+}
@@ -0,0 +1,188 @@
+package upstox
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Interval labels for bars that are not time based. They are passed to
+// OnCandleClose callbacks but are not valid API intervals.
+const (
+	Renko    Interval = "renko"
+	RangeBar Interval = "range"
+)
+
+// HeikinAshi wraps fn so it receives Heikin-Ashi candles instead of the
+// regular ones, smoothed separately per instrument and interval:
+//
+//	agg.OnCandleClose(upstox.HeikinAshi(onCandle))
+func HeikinAshi(fn CandleCloseCallback) CandleCloseCallback {
+	var mu sync.Mutex
+	prev := make(map[barKey]Candle)
+
+	return func(instrumentKey string, interval Interval, c Candle) {
+		k := barKey{instrumentKey, interval}
+
+		mu.Lock()
+		ha := Candle{
+			Timestamp:    c.Timestamp,
+			Close:        (c.Open + c.High + c.Low + c.Close) / 4,
+			Volume:       c.Volume,
+			OpenInterest: c.OpenInterest,
+		}
+		if p, ok := prev[k]; ok {
+			ha.Open = (p.Open + p.Close) / 2
+		} else {
+			ha.Open = (c.Open + c.Close) / 2
+		}
+		ha.High = max(c.High, ha.Open, ha.Close)
+		ha.Low = min(c.Low, ha.Open, ha.Close)
+		prev[k] = ha
+		mu.Unlock()
+
+		fn(instrumentKey, interval, ha)
+	}
+}
+
+type brickKind int
+
+const (
+	brickRenko brickKind = iota
+	brickRange
+)
+
+// BrickAggregator builds price-driven bars from ticks: Renko bricks or range
+// bars. Completed bars are passed to OnCandleClose callbacks labelled Renko
+// or RangeBar, with Timestamp set to the first tick of the bar and Volume to
+// the traded quantity seen while it formed.
+type BrickAggregator struct {
+	kind brickKind
+	size float64
+
+	mu      sync.Mutex
+	bars    map[string]*brickState
+	onClose []CandleCloseCallback
+}
+
+type brickState struct {
+	bar     Candle
+	started bool
+}
+
+// NewRenkoAggregator emits a brick each time price moves boxSize beyond the
+// previous brick; a reversal needs a move of boxSize past the previous
+// brick's open. A gap emits several bricks at once.
+func NewRenkoAggregator(boxSize float64) (*BrickAggregator, error) {
+	if boxSize <= 0 {
+		return nil, fmt.Errorf("renko box size must be positive, got %v", boxSize)
+	}
+	return &BrickAggregator{kind: brickRenko, size: boxSize, bars: make(map[string]*brickState)}, nil
+}
+
+// NewRangeAggregator emits a bar once its high-low range reaches size. The
+// next bar opens on the following tick, so a gap can leave a bar wider than
+// size.
+func NewRangeAggregator(size float64) (*BrickAggregator, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("range bar size must be positive, got %v", size)
+	}
+	return &BrickAggregator{kind: brickRange, size: size, bars: make(map[string]*brickState)}, nil
+}
+
+func (b *BrickAggregator) OnCandleClose(fn CandleCloseCallback) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onClose = append(b.onClose, fn)
+}
+
+func (b *BrickAggregator) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(b.OnTick)
+}
+
+func (b *BrickAggregator) OnTick(tick Tick) {
+	t := tick.ReceivedAt
+	if tick.LTT > 0 {
+		t = time.UnixMilli(tick.LTT)
+	}
+
+	b.mu.Lock()
+	s := b.bars[tick.InstrumentKey]
+	if s == nil {
+		// The first tick only anchors the first brick or bar.
+		s = &brickState{bar: Candle{Timestamp: t, Open: tick.LTP, High: tick.LTP, Low: tick.LTP, Close: tick.LTP}}
+		b.bars[tick.InstrumentKey] = s
+		if b.kind == brickRange {
+			s.started = true
+			s.bar.Volume = tick.LTQ
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	var closed []closedCandle
+	switch b.kind {
+	case brickRenko:
+		closed = b.renko(tick.InstrumentKey, s, tick, t)
+	case brickRange:
+		closed = b.rangeBar(tick.InstrumentKey, s, tick, t)
+	}
+	callbacks := b.onClose
+	b.mu.Unlock()
+
+	emit(callbacks, closed)
+}
+
+// renko keeps the last completed brick in s.bar; started tracks whether
+// ticks have arrived since, so the next brick's timestamp and volume are
+// accumulated in place.
+func (b *BrickAggregator) renko(key string, s *brickState, tick Tick, t time.Time) []closedCandle {
+	if !s.started {
+		s.started = true
+		s.bar.Timestamp = t
+		s.bar.Volume = 0
+	}
+	s.bar.Volume += tick.LTQ
+
+	var closed []closedCandle
+	for {
+		top := math.Max(s.bar.Open, s.bar.Close)
+		bottom := math.Min(s.bar.Open, s.bar.Close)
+		var brick Candle
+		switch {
+		case tick.LTP >= top+b.size:
+			brick = Candle{Open: top, Close: top + b.size}
+		case tick.LTP <= bottom-b.size:
+			brick = Candle{Open: bottom, Close: bottom - b.size}
+		default:
+			return closed
+		}
+		brick.High = math.Max(brick.Open, brick.Close)
+		brick.Low = math.Min(brick.Open, brick.Close)
+		brick.Timestamp = s.bar.Timestamp
+		if len(closed) == 0 {
+			brick.Volume = s.bar.Volume
+		}
+		closed = append(closed, closedCandle{key, Renko, brick})
+		s.bar = brick
+		s.started = false
+	}
+}
+
+func (b *BrickAggregator) rangeBar(key string, s *brickState, tick Tick, t time.Time) []closedCandle {
+	if !s.started {
+		s.bar = Candle{Timestamp: t, Open: tick.LTP, High: tick.LTP, Low: tick.LTP}
+		s.started = true
+	}
+	s.bar.High = max(s.bar.High, tick.LTP)
+	s.bar.Low = min(s.bar.Low, tick.LTP)
+	s.bar.Close = tick.LTP
+	s.bar.Volume += tick.LTQ
+
+	if s.bar.High-s.bar.Low < b.size {
+		return nil
+	}
+	s.started = false
+	return []closedCandle{{key, RangeBar, s.bar}}
+}
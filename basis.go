@@ -0,0 +1,183 @@
+package upstox
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// BasisPair configures one future/spot pair for a BasisMonitor.
+type BasisPair struct {
+	Name   string
+	Future string
+	Spot   string
+	Expiry time.Time
+
+	// Rate and DividendYield are annual, continuously compounded rates used
+	// for the fair value; leave both zero to skip it.
+	Rate          float64
+	DividendYield float64
+
+	// MinBasisPct and MaxBasisPct bound the normal basis as a percentage of
+	// spot. Leaving the band, in either direction, fires the blowout callback
+	// once; it re-arms when the basis returns inside. Both zero disables it.
+	MinBasisPct float64
+	MaxBasisPct float64
+}
+
+type BasisUpdate struct {
+	Pair   string
+	Future float64
+	Spot   float64
+	Basis  float64
+
+	// BasisPct is the basis as a percentage of spot, and AnnualisedCarry the
+	// same spread annualised over the time to expiry, in percent.
+	BasisPct        float64
+	AnnualisedCarry float64
+	DaysToExpiry    float64
+
+	// FairValue is zero unless the pair has a rate; Mispricing is the future
+	// less its fair value.
+	FairValue  float64
+	Mispricing float64
+
+	At time.Time
+}
+
+// BasisMonitor streams the basis and carry of configured future/spot pairs
+// from the feed. Callbacks run on the goroutine that delivers ticks and must
+// return quickly.
+type BasisMonitor struct {
+	mu        sync.RWMutex
+	pairs     []*basisState
+	byKey     map[string][]*basisState
+	prices    map[string]float64
+	onUpdate  func(BasisUpdate)
+	onBlowout func(BasisUpdate)
+}
+
+type basisState struct {
+	pair    BasisPair
+	last    BasisUpdate
+	outside bool
+}
+
+func NewBasisMonitor(onUpdate func(BasisUpdate)) *BasisMonitor {
+	return &BasisMonitor{
+		byKey:    make(map[string][]*basisState),
+		prices:   make(map[string]float64),
+		onUpdate: onUpdate,
+	}
+}
+
+func (b *BasisMonitor) AddPair(pair BasisPair) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := &basisState{pair: pair}
+	b.pairs = append(b.pairs, st)
+	b.byKey[pair.Future] = append(b.byKey[pair.Future], st)
+	b.byKey[pair.Spot] = append(b.byKey[pair.Spot], st)
+}
+
+// OnBlowout registers fn for basis moves outside a pair's configured band.
+func (b *BasisMonitor) OnBlowout(fn func(BasisUpdate)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onBlowout = fn
+}
+
+func (b *BasisMonitor) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(b.OnTick)
+}
+
+func (b *BasisMonitor) OnTick(tick Tick) {
+	var updates, blowouts []BasisUpdate
+
+	b.mu.Lock()
+	states, ok := b.byKey[tick.InstrumentKey]
+	if !ok {
+		b.mu.Unlock()
+		return
+	}
+	b.prices[tick.InstrumentKey] = tick.LTP
+
+	at := tick.ReceivedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	for _, st := range states {
+		fut, okF := b.prices[st.pair.Future]
+		spot, okS := b.prices[st.pair.Spot]
+		if !okF || !okS || spot <= 0 {
+			continue
+		}
+
+		u := computeBasis(st.pair, fut, spot, at)
+		st.last = u
+		updates = append(updates, u)
+
+		p := st.pair
+		if p.MinBasisPct == 0 && p.MaxBasisPct == 0 {
+			continue
+		}
+		outside := u.BasisPct < p.MinBasisPct || u.BasisPct > p.MaxBasisPct
+		if outside && !st.outside {
+			blowouts = append(blowouts, u)
+		}
+		st.outside = outside
+	}
+	onUpdate, onBlowout := b.onUpdate, b.onBlowout
+	b.mu.Unlock()
+
+	for _, u := range updates {
+		if onUpdate != nil {
+			onUpdate(u)
+		}
+	}
+	for _, u := range blowouts {
+		if onBlowout != nil {
+			onBlowout(u)
+		}
+	}
+}
+
+// Basis returns the latest update for the named pair.
+func (b *BasisMonitor) Basis(name string) (BasisUpdate, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, st := range b.pairs {
+		if st.pair.Name == name && !st.last.At.IsZero() {
+			return st.last, true
+		}
+	}
+	return BasisUpdate{}, false
+}
+
+func computeBasis(pair BasisPair, future, spot float64, at time.Time) BasisUpdate {
+	u := BasisUpdate{
+		Pair:     pair.Name,
+		Future:   future,
+		Spot:     spot,
+		Basis:    future - spot,
+		BasisPct: (future - spot) / spot * 100,
+		At:       at,
+	}
+
+	days := pair.Expiry.Sub(at).Hours() / 24
+	if days > 0 {
+		u.DaysToExpiry = days
+		u.AnnualisedCarry = u.BasisPct * 365 / days
+	}
+	if pair.Rate != 0 || pair.DividendYield != 0 {
+		u.FairValue = FairFuturePrice(spot, pair.Rate, pair.DividendYield, math.Max(days, 0)/365)
+		u.Mispricing = future - u.FairValue
+	}
+	return u
+}
+
+// FairFuturePrice is the cost-of-carry price of a future: spot compounded
+// continuously at rate less dividendYield over years.
+func FairFuturePrice(spot, rate, dividendYield, years float64) float64 {
+	return spot * math.Exp((rate-dividendYield)*years)
+}
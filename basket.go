@@ -0,0 +1,247 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const (
+	multiPlaceURL = "https://api.upstox.com/v2/order/multi/place"
+
+	// maxMultiOrderLegs is the most orders the multi-order API accepts in
+	// one request.
+	maxMultiOrderLegs = 25
+)
+
+// BasketMode picks how a basket is sent.
+type BasketMode int
+
+const (
+	// BasketSequential places legs one by one through ExecuteLegs, stopping
+	// at the first failure and applying OnFailure to the legs before it.
+	BasketSequential BasketMode = iota
+	// BasketMulti sends every leg in a single multi-order request. The
+	// exchange does not make it atomic: some legs may be placed while others
+	// fail, and nothing is unwound.
+	BasketMulti
+)
+
+type BasketOptions struct {
+	Mode BasketMode
+
+	// OnFailure applies to BasketSequential.
+	OnFailure LegFailureAction
+
+	// SkipMarginCheck submits without comparing the basket's margin to the
+	// available funds first.
+	SkipMarginCheck bool
+
+	// Segment is the funds segment margin is checked against: "SEC" (the
+	// default) for equity and F&O, "COM" for commodity.
+	Segment string
+}
+
+// BasketMargin compares what a basket needs with what the account has.
+// Required is the API's combined requirement, so hedged legs get their
+// margin benefit.
+type BasketMargin struct {
+	Required  float64
+	Available float64
+	Margin    *MarginRequirement
+}
+
+// Shortfall is how much more margin the basket needs, or zero.
+func (b *BasketMargin) Shortfall() float64 {
+	return max(b.Required-b.Available, 0)
+}
+
+// BasketResult is the outcome of BasketBuilder.Submit. Report.Legs lines up
+// with the order the legs were added in.
+type BasketResult struct {
+	Margin *BasketMargin
+	Report *ExecutionReport
+}
+
+// BasketBuilder collects the legs of a multi-leg trade, checks their
+// combined margin against available funds and submits them together.
+type BasketBuilder struct {
+	m    *Manager
+	legs []OrderRequest
+}
+
+func (m *Manager) NewBasket() *BasketBuilder {
+	return &BasketBuilder{m: m}
+}
+
+// Add appends legs to the basket.
+func (b *BasketBuilder) Add(legs ...OrderRequest) *BasketBuilder {
+	b.legs = append(b.legs, legs...)
+	return b
+}
+
+func (b *BasketBuilder) Legs() []OrderRequest {
+	return append([]OrderRequest(nil), b.legs...)
+}
+
+// CheckMargin prices the whole basket in one margin request and compares it
+// with the available margin in segment ("SEC" if empty). The returned error
+// wraps ErrInsufficientFunds when the basket does not fit; the margin is
+// returned either way.
+func (b *BasketBuilder) CheckMargin(ctx context.Context, segment string) (*BasketMargin, error) {
+	if len(b.legs) == 0 {
+		return nil, fmt.Errorf("basket is empty")
+	}
+	if segment == "" {
+		segment = "SEC"
+	}
+
+	margin, err := b.m.GetMargin(ctx, b.legs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket margin: %w", err)
+	}
+	funds, err := b.m.GetFundsAndMargin(ctx, segment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funds: %w", err)
+	}
+
+	result := &BasketMargin{Required: margin.FinalMargin, Margin: margin}
+	if result.Required == 0 {
+		result.Required = margin.RequiredMargin
+	}
+	if segment == "COM" {
+		result.Available = funds.Data.Commodity.AvailableMargin
+	} else {
+		result.Available = funds.Data.Equity.AvailableMargin
+	}
+
+	if short := result.Shortfall(); short > 0 {
+		return result, fmt.Errorf("%w: basket needs %.2f, %.2f available", ErrInsufficientFunds, result.Required, result.Available)
+	}
+	return result, nil
+}
+
+// Submit checks margin unless opts.SkipMarginCheck, then sends the legs as
+// opts.Mode says. Nothing is sent if any leg is invalid or the margin check
+// fails. A failed leg is reported through an error wrapping ErrLegFailed,
+// alongside the full result.
+func (b *BasketBuilder) Submit(ctx context.Context, opts BasketOptions) (*BasketResult, error) {
+	if len(b.legs) == 0 {
+		return nil, fmt.Errorf("basket is empty")
+	}
+	for i, leg := range b.legs {
+		if err := validateOrderRequest(leg); err != nil {
+			return nil, fmt.Errorf("invalid leg %d: %w", i, err)
+		}
+	}
+
+	result := &BasketResult{}
+	if !opts.SkipMarginCheck {
+		margin, err := b.CheckMargin(ctx, opts.Segment)
+		result.Margin = margin
+		if err != nil {
+			return result, err
+		}
+	}
+
+	var err error
+	// Simulated placement has no multi-order endpoint to call.
+	if opts.Mode == BasketMulti && !b.m.dryRun && b.m.paper == nil {
+		result.Report, err = b.m.placeMulti(ctx, b.legs)
+	} else {
+		result.Report, err = b.m.ExecuteLegs(ctx, b.legs, MultiLegOptions{OnFailure: opts.OnFailure})
+	}
+	return result, err
+}
+
+type multiPlaceLeg struct {
+	OrderRequest
+	CorrelationID string `json:"correlation_id"`
+}
+
+type multiPlaceError struct {
+	OrderError
+	CorrelationID string `json:"correlation_id"`
+}
+
+type multiPlaceResponse struct {
+	Status string `json:"status"`
+	Data   []struct {
+		CorrelationID string `json:"correlation_id"`
+		OrderID       string `json:"order_id"`
+	} `json:"data"`
+	Errors  []multiPlaceError `json:"errors,omitempty"`
+	Summary MultiOrderSummary `json:"summary"`
+}
+
+func (r *multiPlaceResponse) envelope() (string, []OrderError) {
+	if r.Status == "partial_success" {
+		return "success", nil
+	}
+	errs := make([]OrderError, len(r.Errors))
+	for i, e := range r.Errors {
+		errs[i] = e.OrderError
+	}
+	return r.Status, errs
+}
+
+// placeMulti sends legs through the multi-order API. Each leg's index is its
+// correlation ID.
+func (m *Manager) placeMulti(ctx context.Context, legs []OrderRequest) (*ExecutionReport, error) {
+	if len(legs) > maxMultiOrderLegs {
+		return nil, fmt.Errorf("multi-order request takes at most %d legs, got %d", maxMultiOrderLegs, len(legs))
+	}
+	if err := m.checkHalt(); err != nil {
+		return nil, err
+	}
+
+	body := make([]multiPlaceLeg, len(legs))
+	for i, leg := range legs {
+		if err := m.preflight(ctx, &leg); err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		body[i] = multiPlaceLeg{OrderRequest: leg, CorrelationID: strconv.Itoa(i)}
+	}
+
+	req, err := m.newRequest(ctx, "POST", multiPlaceURL, body)
+	if err != nil {
+		return nil, err
+	}
+	var resp multiPlaceResponse
+	if err := m.doWith(m.orderClient, req, &resp); err != nil {
+		return nil, err
+	}
+
+	report := &ExecutionReport{FailedLeg: -1, Action: LegFailureLeave}
+	report.Legs = make([]LegResult, len(legs))
+	for i, leg := range body {
+		report.Legs[i] = LegResult{Request: leg.OrderRequest}
+	}
+	for _, d := range resp.Data {
+		if i, err := strconv.Atoi(d.CorrelationID); err == nil && i >= 0 && i < len(legs) {
+			report.Legs[i].OrderID = d.OrderID
+			m.firePlaced(report.Legs[i].Request, &OrderResponse{
+				Status: "success",
+				Data:   &OrderResponseData{OrderIDs: []string{d.OrderID}},
+			})
+		}
+	}
+	for _, e := range resp.Errors {
+		i, err := strconv.Atoi(e.CorrelationID)
+		if err != nil || i < 0 || i >= len(legs) {
+			continue
+		}
+		report.Legs[i].Err = fmt.Errorf("%s: %s", e.ErrorCode, e.Message)
+		m.fireRejected(report.Legs[i].Request, e.Message)
+		if report.FailedLeg < 0 || i < report.FailedLeg {
+			report.FailedLeg = i
+		}
+	}
+
+	if report.FailedLeg >= 0 {
+		failed := report.Legs[report.FailedLeg]
+		return report, fmt.Errorf("%w: leg %d (%s %s): %v", ErrLegFailed, report.FailedLeg,
+			failed.Request.TransactionType, failed.Request.InstrumentToken, failed.Err)
+	}
+	return report, nil
+}
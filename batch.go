@@ -0,0 +1,71 @@
+package upstox
+
+import (
+	"sync"
+	"time"
+)
+
+type TickBatchCallback func(ticks []Tick)
+
+type tickBatcher struct {
+	mu       sync.Mutex
+	pending  []Tick
+	callback TickBatchCallback
+}
+
+// OnTickBatch delivers ticks in batches: everything received during each
+// interval is handed to fn in a single call from a dedicated goroutine, so the
+// read loop only pays for an append. Each batch is drawn from a pool and owned
+// by fn once delivered; return it with ReleaseTicks when done to keep steady
+// state batching allocation-free.
+func (wsm *WebSocketManager) OnTickBatch(interval time.Duration, fn TickBatchCallback) {
+	b := &tickBatcher{callback: fn, pending: getTickSlice()}
+
+	wsm.mu.Lock()
+	wsm.batcher = b
+	wsm.mu.Unlock()
+
+	go b.run(wsm, interval)
+}
+
+func (b *tickBatcher) add(tick Tick) {
+	b.mu.Lock()
+	b.pending = append(b.pending, tick)
+	b.mu.Unlock()
+}
+
+func (b *tickBatcher) run(wsm *WebSocketManager, interval time.Duration) {
+	labelGoroutine("tick_batch")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wsm.ctx.Done():
+			b.flush()
+			return
+		case <-ticker.C:
+			wsm.mu.RLock()
+			current := wsm.batcher == b
+			wsm.mu.RUnlock()
+			if !current {
+				return
+			}
+			b.flush()
+		}
+	}
+}
+
+func (b *tickBatcher) flush() {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.pending
+	b.pending = getTickSlice()
+	b.mu.Unlock()
+
+	b.callback(batch)
+}
@@ -0,0 +1,332 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type BracketState int
+
+const (
+	// BracketPending: the entry order is working.
+	BracketPending BracketState = iota
+	// BracketOpen: the entry filled and the stop and target are working.
+	BracketOpen
+	BracketStopped
+	BracketTargetHit
+	// BracketCancelled: the entry was cancelled unfilled, or both exits
+	// were cancelled without filling.
+	BracketCancelled
+	// BracketFailed: the entry was rejected or an exit could not be placed.
+	BracketFailed
+)
+
+func (s BracketState) String() string {
+	switch s {
+	case BracketPending:
+		return "pending"
+	case BracketOpen:
+		return "open"
+	case BracketStopped:
+		return "stopped"
+	case BracketTargetHit:
+		return "target_hit"
+	case BracketCancelled:
+		return "cancelled"
+	case BracketFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("BracketState(%d)", int(s))
+}
+
+// BracketRequest is an entry order with a protective stop and a profit
+// target, both absolute prices. The stop is an SL-M order, or an SL order
+// limited at StopLimit when that is set.
+type BracketRequest struct {
+	Entry     OrderRequest
+	StopLoss  float64
+	Target    float64
+	StopLimit float64
+}
+
+// Bracket is the state of one bracket. Quantity is what the entry filled,
+// and so what the exits are placed for.
+type Bracket struct {
+	ID            string
+	Request       BracketRequest
+	State         BracketState
+	EntryOrderID  string
+	StopOrderID   string
+	TargetOrderID string
+	Quantity      int
+	EntryPrice    float64
+	ExitPrice     float64
+	Err           error
+}
+
+// BracketManager emulates bracket orders. It places the entry, and once the
+// entry fills places the stop and target for the filled quantity, linked in
+// an OCOGroup so that one filling cancels the other. Order changes come from
+// an OrderTracker, which must be started (or fed updates) for brackets to
+// progress.
+//
+// Exits are placed like ClosePosition's, so they go through even while
+// trading is halted.
+type BracketManager struct {
+	m       *Manager
+	ctx     context.Context
+	tracker *OrderTracker
+
+	mu       sync.Mutex
+	seq      int
+	brackets map[string]*Bracket
+	byEntry  map[string]*Bracket
+	onUpdate func(Bracket)
+}
+
+// NewBracketManager drives brackets from tracker's events. ctx bounds the
+// exit orders, cancels and modifications made in response to fills.
+func (m *Manager) NewBracketManager(ctx context.Context, tracker *OrderTracker) *BracketManager {
+	bm := &BracketManager{
+		m:        m,
+		ctx:      ctx,
+		tracker:  tracker,
+		brackets: make(map[string]*Bracket),
+		byEntry:  make(map[string]*Bracket),
+	}
+	tracker.OnEvent(bm.onEvent)
+	return bm
+}
+
+// OnUpdate registers fn to run after every bracket state change.
+func (bm *BracketManager) OnUpdate(fn func(Bracket)) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.onUpdate = fn
+}
+
+// Place validates req and places its entry order.
+func (bm *BracketManager) Place(ctx context.Context, req BracketRequest) (Bracket, error) {
+	if err := validateBracket(req); err != nil {
+		return Bracket{}, fmt.Errorf("invalid bracket: %w", err)
+	}
+
+	resp, err := bm.m.PlaceOrder(ctx, req.Entry)
+	if err != nil {
+		return Bracket{}, fmt.Errorf("failed to place entry: %w", err)
+	}
+
+	bm.mu.Lock()
+	bm.seq++
+	b := &Bracket{
+		ID:           "BRACKET-" + strconv.Itoa(bm.seq),
+		Request:      req,
+		EntryOrderID: resp.Data.OrderIDs[0],
+	}
+	bm.brackets[b.ID] = b
+	bm.byEntry[b.EntryOrderID] = b
+	snapshot := *b
+	bm.mu.Unlock()
+
+	bm.tracker.Watch(b.EntryOrderID)
+	return snapshot, nil
+}
+
+func validateBracket(req BracketRequest) error {
+	if err := validateOrderRequest(req.Entry); err != nil {
+		return err
+	}
+	if req.StopLoss <= 0 || req.Target <= 0 {
+		return fmt.Errorf("stop loss and target are required")
+	}
+	if isBuy(req.Entry.TransactionType) {
+		if req.StopLoss >= req.Target {
+			return fmt.Errorf("stop loss %.2f must be below target %.2f for a buy", req.StopLoss, req.Target)
+		}
+	} else if req.StopLoss <= req.Target {
+		return fmt.Errorf("stop loss %.2f must be above target %.2f for a sell", req.StopLoss, req.Target)
+	}
+	return nil
+}
+
+func isBuy(side string) bool {
+	return strings.EqualFold(side, string(OrderSideBuy))
+}
+
+func (bm *BracketManager) Get(id string) (Bracket, bool) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	b, ok := bm.brackets[id]
+	if !ok {
+		return Bracket{}, false
+	}
+	return *b, true
+}
+
+// Brackets returns every bracket, finished ones included.
+func (bm *BracketManager) Brackets() []Bracket {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	out := make([]Bracket, 0, len(bm.brackets))
+	for _, b := range bm.brackets {
+		out = append(out, *b)
+	}
+	slices.SortFunc(out, func(a, b Bracket) int { return strings.Compare(a.ID, b.ID) })
+	return out
+}
+
+// Cancel cancels whichever of the bracket's orders are working. An open
+// position is left in place; close it separately if needed.
+func (bm *BracketManager) Cancel(ctx context.Context, id string) error {
+	bm.mu.Lock()
+	b, ok := bm.brackets[id]
+	if !ok {
+		bm.mu.Unlock()
+		return fmt.Errorf("unknown bracket %s", id)
+	}
+	var orders []string
+	switch b.State {
+	case BracketPending:
+		orders = []string{b.EntryOrderID}
+	case BracketOpen:
+		orders = []string{b.StopOrderID, b.TargetOrderID}
+	}
+	bm.mu.Unlock()
+
+	for _, orderID := range orders {
+		if _, err := bm.m.CancelOrder(ctx, orderID); err != nil {
+			return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+		}
+	}
+	return nil
+}
+
+func (bm *BracketManager) onEvent(ev OrderEvent) {
+	bm.mu.Lock()
+	b, ok := bm.byEntry[ev.OrderID]
+	bm.mu.Unlock()
+	if ok {
+		bm.onEntry(b, ev)
+	}
+}
+
+func (bm *BracketManager) onEntry(b *Bracket, ev OrderEvent) {
+	switch ev.Type {
+	case OrderFilled:
+		bm.openExits(b, ev.Order)
+	case OrderCancelled:
+		if ev.Order.FilledQuantity > 0 {
+			bm.openExits(b, ev.Order)
+			return
+		}
+		bm.finish(b, BracketCancelled, 0, nil)
+	case OrderRejected:
+		bm.finish(b, BracketFailed, 0, fmt.Errorf("entry rejected: %s", ev.Order.StatusMessage))
+	}
+}
+
+// openExits places the stop and target for what the entry filled.
+func (bm *BracketManager) openExits(b *Bracket, entry *Order) {
+	req := b.Request
+	exitSide := string(OrderSideSell)
+	if !isBuy(req.Entry.TransactionType) {
+		exitSide = string(OrderSideBuy)
+	}
+	qty := entry.FilledQuantity
+
+	stop := OrderRequest{
+		Quantity:        qty,
+		Product:         req.Entry.Product,
+		Validity:        string(ValidityDay),
+		InstrumentToken: req.Entry.InstrumentToken,
+		OrderType:       string(OrderTypeSLM),
+		TransactionType: exitSide,
+		TriggerPrice:    req.StopLoss,
+		Tag:             req.Entry.Tag,
+		Force:           true,
+	}
+	if req.StopLimit > 0 {
+		stop.OrderType = string(OrderTypeSL)
+		stop.Price = req.StopLimit
+	}
+	target := OrderRequest{
+		Quantity:        qty,
+		Product:         req.Entry.Product,
+		Validity:        string(ValidityDay),
+		InstrumentToken: req.Entry.InstrumentToken,
+		OrderType:       string(OrderTypeLimit),
+		TransactionType: exitSide,
+		Price:           req.Target,
+		Tag:             req.Entry.Tag,
+		Force:           true,
+	}
+
+	stopResp, err := bm.m.submitOrder(bm.ctx, stop)
+	if err != nil {
+		bm.finish(b, BracketFailed, 0, fmt.Errorf("failed to place stop loss: %w", err))
+		return
+	}
+	targetResp, err := bm.m.submitOrder(bm.ctx, target)
+	if err != nil {
+		// Keep the stop; a position without a target is still protected.
+		bm.mu.Lock()
+		b.StopOrderID = stopResp.Data.OrderIDs[0]
+		bm.mu.Unlock()
+		bm.finish(b, BracketFailed, 0, fmt.Errorf("failed to place target: %w", err))
+		return
+	}
+
+	bm.mu.Lock()
+	b.State = BracketOpen
+	b.Quantity = qty
+	b.EntryPrice = entry.AveragePrice
+	b.StopOrderID = stopResp.Data.OrderIDs[0]
+	b.TargetOrderID = targetResp.Data.OrderIDs[0]
+	bm.mu.Unlock()
+
+	group, err := bm.m.NewOCOGroup(bm.ctx, bm.tracker, b.StopOrderID, b.TargetOrderID)
+	if err != nil {
+		bm.finish(b, BracketFailed, 0, err)
+		return
+	}
+	bm.notify(b)
+	group.OnDone(func(result OCOResult) { bm.onExit(b, result) })
+}
+
+func (bm *BracketManager) onExit(b *Bracket, result OCOResult) {
+	if result.Filled == nil {
+		bm.finish(b, BracketCancelled, 0, nil)
+		return
+	}
+	state := BracketTargetHit
+	if result.Filled.OrderID == b.StopOrderID {
+		state = BracketStopped
+	}
+	bm.finish(b, state, result.Filled.AveragePrice, nil)
+}
+
+func (bm *BracketManager) finish(b *Bracket, state BracketState, exitPrice float64, err error) {
+	bm.mu.Lock()
+	b.State = state
+	b.ExitPrice = exitPrice
+	b.Err = err
+	bm.mu.Unlock()
+	if err != nil {
+		bm.m.logger.Error("bracket failed", "bracket", b.ID, "error", err)
+	}
+	bm.notify(b)
+}
+
+func (bm *BracketManager) notify(b *Bracket) {
+	bm.mu.Lock()
+	fn := bm.onUpdate
+	snapshot := *b
+	bm.mu.Unlock()
+	if fn != nil {
+		fn(snapshot)
+	}
+}
@@ -0,0 +1,174 @@
+package upstox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// orderAPI answers placements with sequential order IDs, starting at 1, and
+// accepts every cancel. Placements for which reject returns true are refused.
+func orderAPI(reject func(n int) bool) *apiStub {
+	var placed atomic.Int32
+	return &apiStub{respond: func(req *http.Request, _ int) (*http.Response, error) {
+		switch {
+		case req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/order/place"):
+			n := int(placed.Add(1))
+			if reject != nil && reject(n) {
+				return jsonResponse(req, http.StatusBadRequest,
+					`{"status":"error","errors":[{"error_code":"UDAPI100500","message":"Order rejected"}]}`), nil
+			}
+			return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_ids":["`+strconv.Itoa(n)+`"]}}`), nil
+		case req.Method == "DELETE":
+			return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_id":"`+req.URL.Query().Get("order_id")+`"}}`), nil
+		}
+		return jsonResponse(req, http.StatusNotFound, `{"status":"error"}`), nil
+	}}
+}
+
+// placedOrders decodes the order requests stub accepted or refused, in order.
+func (s *apiStub) placedOrders(t *testing.T) []OrderRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []OrderRequest
+	for i, req := range s.requests {
+		if req.Method != "POST" {
+			continue
+		}
+		var o OrderRequest
+		if err := json.Unmarshal([]byte(s.bodies[i]), &o); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// cancelled returns the order IDs stub was asked to cancel, in order.
+func (s *apiStub) cancelled() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, req := range s.requests {
+		if req.Method == "DELETE" {
+			out = append(out, req.URL.Query().Get("order_id"))
+		}
+	}
+	return out
+}
+
+func newTestBracket(t *testing.T, stub *apiStub) (*BracketManager, *OrderTracker, Bracket) {
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+	tr := m.NewOrderTracker(OrderTrackerConfig{})
+	bm := m.NewBracketManager(context.Background(), tr)
+
+	entry := marketOrderRequest("NSE_EQ|X", 10, "BUY")
+	entry.OrderType = string(OrderTypeLimit)
+	entry.Price = 100
+	b, err := bm.Place(context.Background(), BracketRequest{Entry: entry, StopLoss: 95, Target: 110})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bm, tr, b
+}
+
+func TestBracketFillExitsAndOCO(t *testing.T) {
+	stub := orderAPI(nil)
+	bm, tr, b := newTestBracket(t, stub)
+	if b.State != BracketPending || b.EntryOrderID != "1" {
+		t.Fatalf("placed bracket = %+v", b)
+	}
+
+	var states []BracketState
+	bm.OnUpdate(func(b Bracket) { states = append(states, b.State) })
+
+	tr.Update(&Order{OrderID: "1", Status: "complete", Quantity: 10, FilledQuantity: 10, AveragePrice: 100})
+	b, _ = bm.Get(b.ID)
+	if b.State != BracketOpen || b.StopOrderID != "2" || b.TargetOrderID != "3" || b.Quantity != 10 || b.EntryPrice != 100 {
+		t.Fatalf("bracket after entry fill = %+v", b)
+	}
+	orders := stub.placedOrders(t)
+	if stop := orders[1]; stop.OrderType != "SL-M" || stop.TransactionType != "SELL" || stop.TriggerPrice != 95 || stop.Quantity != 10 {
+		t.Errorf("stop = %+v", stop)
+	}
+	if target := orders[2]; target.OrderType != "LIMIT" || target.TransactionType != "SELL" || target.Price != 110 || target.Quantity != 10 {
+		t.Errorf("target = %+v", target)
+	}
+
+	tr.Update(&Order{OrderID: "3", Status: "complete", Quantity: 10, FilledQuantity: 10, AveragePrice: 110})
+	if got := stub.cancelled(); !slices.Equal(got, []string{"2"}) {
+		t.Errorf("cancelled %v, want the stop", got)
+	}
+	b, _ = bm.Get(b.ID)
+	if b.State != BracketTargetHit || b.ExitPrice != 110 || b.Err != nil {
+		t.Errorf("bracket after target fill = %+v", b)
+	}
+	if want := []BracketState{BracketOpen, BracketTargetHit}; !slices.Equal(states, want) {
+		t.Errorf("updates = %v, want %v", states, want)
+	}
+}
+
+func TestBracketPartialEntryThenCancel(t *testing.T) {
+	stub := orderAPI(nil)
+	bm, tr, b := newTestBracket(t, stub)
+
+	tr.Update(&Order{OrderID: "1", Status: "open", Quantity: 10, FilledQuantity: 4, AveragePrice: 100})
+	if b, _ := bm.Get(b.ID); b.State != BracketPending {
+		t.Fatalf("exits placed on a partial fill: %+v", b)
+	}
+
+	tr.Update(&Order{OrderID: "1", Status: "cancelled", Quantity: 10, FilledQuantity: 4, AveragePrice: 100})
+	b, _ = bm.Get(b.ID)
+	if b.State != BracketOpen || b.Quantity != 4 {
+		t.Fatalf("bracket after cancel = %+v", b)
+	}
+	for _, o := range stub.placedOrders(t)[1:] {
+		if o.Quantity != 4 {
+			t.Errorf("exit for %d, want the 4 filled", o.Quantity)
+		}
+	}
+
+	tr.Update(&Order{OrderID: "2", Status: "complete", Quantity: 4, FilledQuantity: 4, AveragePrice: 95})
+	if got := stub.cancelled(); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("cancelled %v, want the target", got)
+	}
+	if b, _ := bm.Get(b.ID); b.State != BracketStopped || b.ExitPrice != 95 {
+		t.Errorf("bracket after stop fill = %+v", b)
+	}
+}
+
+func TestBracketUnfilledEntryCancelled(t *testing.T) {
+	stub := orderAPI(nil)
+	bm, tr, b := newTestBracket(t, stub)
+
+	tr.Update(&Order{OrderID: "1", Status: "cancelled", Quantity: 10})
+	if b, _ := bm.Get(b.ID); b.State != BracketCancelled {
+		t.Errorf("bracket = %+v", b)
+	}
+	if n := len(stub.placedOrders(t)); n != 1 {
+		t.Errorf("%d orders placed, want only the entry", n)
+	}
+}
+
+func TestBracketTargetPlacementFails(t *testing.T) {
+	stub := orderAPI(func(n int) bool { return n == 3 })
+	bm, tr, b := newTestBracket(t, stub)
+
+	tr.Update(&Order{OrderID: "1", Status: "complete", Quantity: 10, FilledQuantity: 10, AveragePrice: 100})
+	b, _ = bm.Get(b.ID)
+	if b.State != BracketFailed || b.Err == nil || !strings.Contains(b.Err.Error(), "target") {
+		t.Fatalf("bracket = %+v", b)
+	}
+	// The stop stays working to protect the position.
+	if b.StopOrderID != "2" || b.TargetOrderID != "" {
+		t.Errorf("stop %q, target %q", b.StopOrderID, b.TargetOrderID)
+	}
+	if got := stub.cancelled(); len(got) != 0 {
+		t.Errorf("cancelled %v", got)
+	}
+}
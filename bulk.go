@@ -0,0 +1,148 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type HistoricalJob struct {
+	InstrumentKey string
+	Interval      Interval
+	From          time.Time
+	To            time.Time
+}
+
+// CandleSink receives the candles for each completed job. Write is called from
+// worker goroutines and must be safe for concurrent use.
+type CandleSink interface {
+	Write(job HistoricalJob, candles []Candle) error
+}
+
+type CandleSinkFunc func(job HistoricalJob, candles []Candle) error
+
+func (f CandleSinkFunc) Write(job HistoricalJob, candles []Candle) error {
+	return f(job, candles)
+}
+
+type BulkFetchOptions struct {
+	Workers    int
+	MaxRetries int
+	RetryDelay time.Duration
+
+	// OnProgress is called after every job, successful or not, with the
+	// number of jobs finished so far.
+	OnProgress func(done, total int, job HistoricalJob, err error)
+}
+
+// FetchHistoricalBulk downloads candles for every job using a pool of workers,
+// retrying failed chunks with exponential backoff. Jobs spanning more than the
+// API allows per call are split into chunks, each written to sink separately.
+// All chunks are attempted; failures are returned together.
+func (m *Manager) FetchHistoricalBulk(ctx context.Context, jobs []HistoricalJob, sink CandleSink, opts BulkFetchOptions) error {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = time.Second
+	}
+
+	var chunks []HistoricalJob
+	for _, job := range jobs {
+		chunks = append(chunks, splitHistoricalJob(job)...)
+	}
+
+	work := make(chan HistoricalJob)
+	var (
+		mu   sync.Mutex
+		errs []error
+		done int
+		wg   sync.WaitGroup
+	)
+
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range work {
+				err := m.fetchHistoricalJob(ctx, job, sink, opts)
+
+				mu.Lock()
+				done++
+				finished := done
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+
+				if opts.OnProgress != nil {
+					opts.OnProgress(finished, len(chunks), job, err)
+				}
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		work <- chunk
+	}
+	close(work)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) fetchHistoricalJob(ctx context.Context, job HistoricalJob, sink CandleSink, opts BulkFetchOptions) error {
+	var candles []Candle
+	var err error
+
+	delay := opts.RetryDelay
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				break
+			}
+			delay *= 2
+		}
+		candles, err = m.GetHistoricalCandles(ctx, job.InstrumentKey, job.Interval, job.From, job.To)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s %s..%s: %w", job.InstrumentKey, job.Interval,
+			job.From.Format(historicalDateLayout), job.To.Format(historicalDateLayout), err)
+	}
+
+	if err := sink.Write(job, candles); err != nil {
+		return fmt.Errorf("sink failed for %s: %w", job.InstrumentKey, err)
+	}
+	return nil
+}
+
+func splitHistoricalJob(job HistoricalJob) []HistoricalJob {
+	span, ok := job.Interval.maxSpan()
+	if !ok || job.To.Sub(job.From) <= span {
+		return []HistoricalJob{job}
+	}
+
+	var chunks []HistoricalJob
+	for from := job.From; !from.After(job.To); {
+		to := from.Add(span)
+		if to.After(job.To) {
+			to = job.To
+		}
+		chunk := job
+		chunk.From, chunk.To = from, to
+		chunks = append(chunks, chunk)
+		// Date granularity is one day, so the next chunk starts the day after.
+		from = to.AddDate(0, 0, 1)
+	}
+	return chunks
+}
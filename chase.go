@@ -0,0 +1,145 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrChaseExhausted = errors.New("limit order chase exhausted")
+
+// ChaseOptions controls ChaseLimitOrder.
+type ChaseOptions struct {
+	// Source supplies the price to chase, typically the WebSocketManager
+	// subscribed to the order's instrument.
+	Source PriceSource
+
+	// MaxDistance caps how far the limit price may move from where the order
+	// was resting when the chase started.
+	MaxDistance float64
+
+	// MaxModifications caps the number of modify calls; zero means no cap.
+	MaxModifications int
+
+	// TickSize rounds the chased price toward the touch; zero leaves it as is.
+	TickSize float64
+
+	// Interval is how often the order is polled and re-priced; it defaults
+	// to 250ms.
+	Interval time.Duration
+
+	// CancelOnExhaust cancels the order when the chase gives up instead of
+	// leaving it resting at its last price.
+	CancelOnExhaust bool
+}
+
+// ChaseLimitOrder keeps modifying a resting limit order toward the latest feed
+// price until it fills, is cancelled or rejected, or the chase runs out of
+// distance or modifications (ErrChaseExhausted). The price only ever moves in
+// the aggressive direction. The latest known state of the order is returned
+// alongside any error, including ctx.Err() when ctx is done.
+func (m *Manager) ChaseLimitOrder(ctx context.Context, orderID string, opts ChaseOptions) (*Order, error) {
+	if opts.Source == nil {
+		return nil, fmt.Errorf("chase requires a price source")
+	}
+	if opts.MaxDistance <= 0 {
+		return nil, fmt.Errorf("chase requires a positive max distance")
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+
+	order, err := m.GetOrderDetails(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order %s: %w", orderID, err)
+	}
+	if order.OrderType != string(OrderTypeLimit) {
+		return order, fmt.Errorf("order %s is %s, only limit orders can be chased", orderID, order.OrderType)
+	}
+
+	buy := order.TransactionType == string(OrderSideBuy)
+	limit := order.Price - opts.MaxDistance
+	if buy {
+		limit = order.Price + opts.MaxDistance
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	modifications := 0
+	for {
+		switch order.Status {
+		case "complete":
+			return order, nil
+		case "cancelled", "rejected":
+			return order, fmt.Errorf("order %s %s: %s", orderID, order.Status, order.StatusMessage)
+		}
+
+		if price, ok := opts.Source.LastPrice(order.InstrumentToken); ok {
+			target := chaseTarget(price, limit, opts.TickSize, buy)
+			improves := (buy && target > order.Price) || (!buy && target < order.Price)
+			beyond := (buy && price > limit) || (!buy && price < limit)
+			atLimit := math.Abs(order.Price-limit) < 1e-9
+
+			switch {
+			case improves && opts.MaxModifications > 0 && modifications >= opts.MaxModifications,
+				beyond && atLimit:
+				return m.endChase(ctx, order, opts)
+			case improves:
+				modReq := modifyFromOrder(order)
+				modReq.Price = target
+				if _, err := m.modifyOrder(ctx, order, modReq); err != nil {
+					// The order may have filled or been cancelled between
+					// the poll and the modify; let the next poll decide.
+					// Anything else, auth and validation errors included,
+					// ends the chase.
+					var apiErr *APIError
+					if !errors.As(err, &apiErr) || !errors.Is(apiErr, ErrNotModifiable) {
+						return order, fmt.Errorf("failed to modify order %s: %w", orderID, err)
+					}
+				} else {
+					modifications++
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return order, ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, err := m.GetOrderDetails(ctx, orderID)
+		if err != nil {
+			return order, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		order = next
+	}
+}
+
+func (m *Manager) endChase(ctx context.Context, order *Order, opts ChaseOptions) (*Order, error) {
+	if opts.CancelOnExhaust {
+		if _, err := m.CancelOrder(ctx, order.OrderID); err != nil {
+			return order, fmt.Errorf("%w; failed to cancel order %s: %v", ErrChaseExhausted, order.OrderID, err)
+		}
+	}
+	return order, ErrChaseExhausted
+}
+
+// chaseTarget rounds price toward the touch and clamps it to limit.
+func chaseTarget(price, limit, tickSize float64, buy bool) float64 {
+	if tickSize > 0 {
+		if buy {
+			price = math.Ceil(price/tickSize-1e-9) * tickSize
+		} else {
+			price = math.Floor(price/tickSize+1e-9) * tickSize
+		}
+	}
+	if buy {
+		return math.Min(price, limit)
+	}
+	return math.Max(price, limit)
+}
@@ -0,0 +1,90 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// restingBuy is an untouched buy of 10 resting at 100.
+func restingBuy() Order {
+	order := partiallyFilled()
+	order.FilledQuantity, order.PendingQuantity, order.AveragePrice = 0, 10, 0
+	return order
+}
+
+func testChase(s *orderServer, opts ChaseOptions) (*Order, error) {
+	m := NewManager("id", "secret", "token", WithTransport(s.stub()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	opts.Interval = 5 * time.Millisecond
+	return m.ChaseLimitOrder(ctx, "1", opts)
+}
+
+func TestChaseFollowsPriceUntilFilled(t *testing.T) {
+	s := &orderServer{order: restingBuy()}
+	s.onModify = func(o *Order, req ModifyOrderRequest) string {
+		o.Price = req.Price
+		o.Status = "complete"
+		o.FilledQuantity, o.PendingQuantity = 10, 0
+		return ""
+	}
+
+	order, err := testChase(s, ChaseOptions{Source: fixedPrice(101.02), MaxDistance: 2, TickSize: 0.05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "complete" {
+		t.Errorf("order = %+v", order)
+	}
+	if mods := s.modified(); len(mods) != 1 || math.Abs(mods[0].Price-101.05) > 1e-9 {
+		t.Errorf("modifies = %+v", mods)
+	}
+}
+
+func TestChaseExhaustsAtMaxDistance(t *testing.T) {
+	s := &orderServer{order: restingBuy()}
+	s.onModify = func(o *Order, req ModifyOrderRequest) string {
+		o.Price = req.Price
+		return ""
+	}
+
+	order, err := testChase(s, ChaseOptions{Source: fixedPrice(105), MaxDistance: 2, CancelOnExhaust: true})
+	if !errors.Is(err, ErrChaseExhausted) {
+		t.Fatalf("err = %v, want ErrChaseExhausted", err)
+	}
+	if order.Price != 102 || s.order.Status != "cancelled" {
+		t.Errorf("order at %v, %s", order.Price, s.order.Status)
+	}
+}
+
+func TestChaseToleratesFillDuringModify(t *testing.T) {
+	s := &orderServer{order: restingBuy()}
+	s.onModify = func(o *Order, _ ModifyOrderRequest) string {
+		o.Status = "complete"
+		o.FilledQuantity, o.PendingQuantity = 10, 0
+		return `{"status":"error","errors":[{"error_code":"UDAPI100040","message":"Order is already completed"}]}`
+	}
+
+	order, err := testChase(s, ChaseOptions{Source: fixedPrice(101), MaxDistance: 2})
+	if err != nil || order.Status != "complete" {
+		t.Errorf("chase = %+v, %v", order, err)
+	}
+}
+
+func TestChaseStopsOnOtherModifyErrors(t *testing.T) {
+	s := &orderServer{order: restingBuy()}
+	s.onModify = func(*Order, ModifyOrderRequest) string {
+		return `{"status":"error","errors":[{"error_code":"UDAPI1021","message":"Invalid price","property_path":"price"}]}`
+	}
+
+	_, err := testChase(s, ChaseOptions{Source: fixedPrice(101), MaxDistance: 2})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("err = %v, want the validation error", err)
+	}
+	if n := len(s.modified()); n != 1 {
+		t.Errorf("%d modifies sent, want 1", n)
+	}
+}
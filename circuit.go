@@ -0,0 +1,134 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrOutsideCircuit = errors.New("price outside circuit limits")
+
+type CircuitMode int
+
+const (
+	// CircuitReject fails orders priced outside the band with ErrOutsideCircuit.
+	CircuitReject CircuitMode = iota + 1
+	// CircuitClamp moves out-of-band prices and triggers onto the band.
+	CircuitClamp
+)
+
+type CircuitBand struct {
+	Lower float64
+	Upper float64
+}
+
+type circuitCacheEntry struct {
+	band CircuitBand
+	day  int
+}
+
+// WithCircuitCheck checks the price and trigger of every priced order against
+// the instrument's circuit limits. Bands are fetched from the full quote API
+// on first use and cached for the trading day.
+func WithCircuitCheck(mode CircuitMode) ManagerOption {
+	return func(m *Manager) {
+		m.circuitMode = mode
+	}
+}
+
+// GetCircuitBand returns the day's circuit limits for an instrument.
+func (m *Manager) GetCircuitBand(ctx context.Context, instrumentKey string) (CircuitBand, error) {
+	day := time.Now().In(IST).YearDay()
+
+	m.mu.RLock()
+	entry, ok := m.circuitBands[instrumentKey]
+	m.mu.RUnlock()
+	if ok && entry.day == day {
+		return entry.band, nil
+	}
+
+	quotes, err := m.GetFullQuotes(ctx, instrumentKey)
+	if err != nil {
+		return CircuitBand{}, fmt.Errorf("failed to get circuit limits: %w", err)
+	}
+	quote, ok := quotes[instrumentKey]
+	if !ok {
+		return CircuitBand{}, fmt.Errorf("no quote returned for %s", instrumentKey)
+	}
+	band := CircuitBand{Lower: quote.LowerCircuitLimit, Upper: quote.UpperCircuitLimit}
+
+	m.mu.Lock()
+	if m.circuitBands == nil {
+		m.circuitBands = make(map[string]circuitCacheEntry)
+	}
+	m.circuitBands[instrumentKey] = circuitCacheEntry{band: band, day: day}
+	m.mu.Unlock()
+	return band, nil
+}
+
+func (m *Manager) checkCircuit(ctx context.Context, orderReq *OrderRequest) error {
+	if m.circuitMode == 0 || (orderReq.Price == 0 && orderReq.TriggerPrice == 0) {
+		return nil
+	}
+
+	band, err := m.GetCircuitBand(ctx, orderReq.InstrumentToken)
+	if err != nil {
+		return err
+	}
+	// Instruments without circuit limits, such as most derivatives, report zeros.
+	if band.Upper <= 0 {
+		return nil
+	}
+
+	for _, p := range []*float64{&orderReq.Price, &orderReq.TriggerPrice} {
+		if *p == 0 || (*p >= band.Lower && *p <= band.Upper) {
+			continue
+		}
+		if m.circuitMode == CircuitReject {
+			return fmt.Errorf("%w: %.2f is outside %.2f-%.2f for %s", ErrOutsideCircuit, *p, band.Lower, band.Upper, orderReq.InstrumentToken)
+		}
+		*p = math.Min(math.Max(*p, band.Lower), band.Upper)
+	}
+	return nil
+}
+
+type CircuitHit struct {
+	InstrumentKey string
+	LTP           float64
+	Band          CircuitBand
+	Upper         bool
+}
+
+// WatchCircuits fetches circuit limits for keys and calls fn from wsm's read
+// goroutine whenever an instrument's LTP reaches its upper or lower limit. It
+// fires once per touch and re-arms when the price moves back inside.
+func (m *Manager) WatchCircuits(ctx context.Context, wsm *WebSocketManager, fn func(CircuitHit), instrumentKeys ...string) (stop func(), err error) {
+	quotes, err := m.GetFullQuotes(ctx, instrumentKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circuit limits: %w", err)
+	}
+
+	bands := make(map[string]CircuitBand, len(quotes))
+	for key, quote := range quotes {
+		if quote.UpperCircuitLimit > 0 {
+			bands[key] = CircuitBand{Lower: quote.LowerCircuitLimit, Upper: quote.UpperCircuitLimit}
+		}
+	}
+
+	// Only the read goroutine touches hit, so it needs no lock.
+	hit := make(map[string]bool, len(bands))
+	return wsm.AddTickListener(func(tick Tick) {
+		band, ok := bands[tick.InstrumentKey]
+		if !ok {
+			return
+		}
+		upper := tick.LTP >= band.Upper
+		at := upper || tick.LTP <= band.Lower
+		if at && !hit[tick.InstrumentKey] {
+			fn(CircuitHit{InstrumentKey: tick.InstrumentKey, LTP: tick.LTP, Band: band, Upper: upper})
+		}
+		hit[tick.InstrumentKey] = at
+	}), nil
+}
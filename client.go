@@ -0,0 +1,35 @@
+package upstox
+
+import (
+	"context"
+	"time"
+)
+
+// UpstoxClient is the subset of Manager that trading code usually depends
+// on: orders, positions, funds, prices and the feed factory. Accept it
+// instead of *Manager to unit test strategies against upstoxtest.Fake.
+type UpstoxClient interface {
+	PlaceOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error)
+	PlaceLimitOrder(ctx context.Context, instrumentToken string, quantity int, side string, price float64) (*OrderResponse, error)
+	PlaceMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string) (*OrderResponse, error)
+	ModifyOrder(ctx context.Context, modReq ModifyOrderRequest) (*OrderIDResponse, error)
+	CancelOrder(ctx context.Context, orderID string) (*OrderIDResponse, error)
+	CancelAllOrders(ctx context.Context, filters ...CancelAllFilter) (*MultiOrderResponse, error)
+
+	GetOrderBook(ctx context.Context) ([]Order, error)
+	GetOrderDetails(ctx context.Context, orderID string) (*Order, error)
+	GetTradesForOrder(ctx context.Context, orderID string) ([]Trade, error)
+
+	GetPositions(ctx context.Context) ([]Position, error)
+	ClosePosition(ctx context.Context, instrumentToken string) (*OrderResponse, error)
+	CloseAllPositions(ctx context.Context) ([]OrderResponse, error)
+	GetHoldings(ctx context.Context) ([]Holding, error)
+	GetFundsAndMargin(ctx context.Context, segment ...string) (*FundsResponse, error)
+
+	GetLTP(ctx context.Context, instrumentKeys ...string) (map[string]float64, error)
+	GetHistoricalCandles(ctx context.Context, instrumentKey string, interval Interval, from, to time.Time) ([]Candle, error)
+
+	NewWebSocketManager(ctx context.Context, instrumentKeys []string, onPriceUpdate func(string, float64, *int32)) (*WebSocketManager, error)
+}
+
+var _ UpstoxClient = (*Manager)(nil)
@@ -0,0 +1,31 @@
+package upstox
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Codec is used for every REST request and response body. Drop-in
+// replacements for encoding/json (jsoniter, goccy/go-json, sonic) satisfy it
+// with a thin adapter; the standard library is the default.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewDecoder(r io.Reader) Decoder
+}
+
+type Decoder interface {
+	Decode(v any) error
+}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (stdCodec) NewDecoder(r io.Reader) Decoder     { return json.NewDecoder(r) }
+
+func WithCodec(codec Codec) ManagerOption {
+	return func(m *Manager) {
+		m.codec = codec
+	}
+}
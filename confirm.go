@@ -0,0 +1,130 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithConfirmation makes every placement wait delay and then look the order
+// up, so the returned response reflects an immediate rejection and the fill
+// and rejection hooks fire. Without it placement returns as soon as the
+// broker acknowledges the order; call ConfirmOrder where the status matters.
+//
+// On its own the wait is a plain sleep followed by one lookup per order; see
+// SetConfirmationTracker to end it as soon as the order's fate is known.
+func WithConfirmation(delay time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.confirm = true
+		m.confirmDelay = delay
+	}
+}
+
+// SetConfirmationTracker makes WithConfirmation wait on t, which must be
+// started, instead of sleeping: placement returns as soon as t sees the
+// order rejected, filled or cancelled, and only orders still working after
+// the delay are looked up. Pass nil to go back to sleeping.
+func (m *Manager) SetConfirmationTracker(t *OrderTracker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.confirmTracker = t
+}
+
+// ConfirmOrder looks up the first order in resp and returns a response
+// carrying its current status: an error envelope if it was rejected. Fill and
+// rejection hooks fire as they would under WithConfirmation.
+func (m *Manager) ConfirmOrder(ctx context.Context, resp *OrderResponse) (*OrderResponse, error) {
+	if resp == nil || resp.Data == nil || len(resp.Data.OrderIDs) == 0 {
+		return nil, fmt.Errorf("order response carries no order ID")
+	}
+	if resp.DryRun {
+		return resp, nil
+	}
+
+	order, err := m.GetOrderDetails(ctx, resp.Data.OrderIDs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm order %s: %w", resp.Data.OrderIDs[0], err)
+	}
+	return m.confirmedResponse(orderRequestFromOrder(order), resp, order), nil
+}
+
+// confirmPlaced is the WithConfirmation step of submitOrder. Lookup failures
+// are logged and the original response returned, since the order itself was
+// accepted.
+func (m *Manager) confirmPlaced(ctx context.Context, orderReq OrderRequest, orderResp *OrderResponse) *OrderResponse {
+	orderID := orderResp.Data.OrderIDs[0]
+
+	m.mu.RLock()
+	tracker := m.confirmTracker
+	m.mu.RUnlock()
+	if tracker != nil {
+		if order, ok := m.awaitConfirmation(ctx, tracker, orderID); ok {
+			return m.confirmedResponse(orderReq, orderResp, order)
+		}
+	} else if err := sleepCtx(ctx, m.confirmDelay); err != nil {
+		return orderResp
+	}
+	if ctx.Err() != nil {
+		return orderResp
+	}
+
+	order, err := m.GetOrderDetails(ctx, orderID)
+	if err != nil {
+		m.logger.Warn("could not get order details", "order_id", orderID, "error", err)
+		return orderResp
+	}
+	return m.confirmedResponse(orderReq, orderResp, order)
+}
+
+// awaitConfirmation waits up to the confirmation delay for tracker to see
+// orderID reach a final state. If the order is still working by then it is
+// unwatched again, unless the caller was already watching it.
+func (m *Manager) awaitConfirmation(ctx context.Context, tracker *OrderTracker, orderID string) (*Order, bool) {
+	waitCtx, cancel := context.WithTimeout(ctx, m.confirmDelay)
+	defer cancel()
+
+	order, err := tracker.Wait(waitCtx, orderID)
+	if err != nil {
+		return nil, false
+	}
+	return order, true
+}
+
+func (m *Manager) confirmedResponse(orderReq OrderRequest, orderResp *OrderResponse, order *Order) *OrderResponse {
+	detailed := &OrderResponse{
+		Status: "success",
+		Data: &OrderResponseData{
+			OrderIDs: orderResp.Data.OrderIDs,
+		},
+		Metadata: orderResp.Metadata,
+	}
+
+	switch order.Status {
+	case "rejected":
+		detailed.Status = "error"
+		detailed.Errors = []OrderError{{
+			ErrorCode: "ORDER_REJECTED",
+			Message:   order.StatusMessage,
+		}}
+		m.fireRejected(orderReq, order.StatusMessage)
+	case "complete":
+		m.fireFilled(order)
+	}
+	return detailed
+}
+
+func orderRequestFromOrder(order *Order) OrderRequest {
+	return OrderRequest{
+		Quantity:          order.Quantity,
+		Product:           order.Product,
+		Validity:          order.Validity,
+		Price:             order.Price,
+		Tag:               order.Tag,
+		InstrumentToken:   order.InstrumentToken,
+		OrderType:         order.OrderType,
+		TransactionType:   order.TransactionType,
+		DisclosedQuantity: order.DisclosedQuantity,
+		TriggerPrice:      order.TriggerPrice,
+		IsAMO:             order.IsAMO,
+	}
+}
@@ -0,0 +1,148 @@
+package upstox
+
+import "sync"
+
+type TickCallback func(tick Tick)
+
+// tickConflator sits between the read loop and a slow consumer. For
+// conflated instruments at most one tick is queued at a time: a newer tick
+// overwrites the queued one in place, keeping its position in the queue.
+// Instruments with conflation disabled have every tick queued in order.
+type tickConflator struct {
+	mu       sync.Mutex
+	queue    []conflatedEntry
+	head     int
+	latest   map[string]Tick
+	exempt   map[string]bool
+	wake     chan struct{}
+	callback TickCallback
+}
+
+type conflatedEntry struct {
+	key       string
+	tick      Tick
+	conflated bool
+}
+
+// OnConflatedTick delivers ticks to fn on a dedicated goroutine. While fn keeps
+// up every tick is delivered; when it falls behind, intermediate ticks for an
+// instrument are dropped and only its latest tick is delivered. Use
+// SetConflation to opt individual instruments out.
+func (wsm *WebSocketManager) OnConflatedTick(fn TickCallback) {
+	c := &tickConflator{
+		latest:   make(map[string]Tick),
+		exempt:   make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+		callback: fn,
+	}
+
+	wsm.mu.Lock()
+	if wsm.conflator != nil {
+		c.exempt = wsm.conflator.exemptions()
+	}
+	wsm.conflator = c
+	wsm.mu.Unlock()
+
+	go c.run(wsm)
+}
+
+// SetConflation enables or disables conflation for the given instruments.
+// Conflation is enabled for every instrument by default.
+func (wsm *WebSocketManager) SetConflation(enabled bool, instrumentKeys ...string) {
+	wsm.mu.RLock()
+	c := wsm.conflator
+	wsm.mu.RUnlock()
+
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range instrumentKeys {
+		if enabled {
+			delete(c.exempt, key)
+		} else {
+			c.exempt[key] = true
+		}
+	}
+}
+
+func (c *tickConflator) exemptions() map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]bool, len(c.exempt))
+	for k, v := range c.exempt {
+		out[k] = v
+	}
+	return out
+}
+
+func (c *tickConflator) add(tick Tick) {
+	c.mu.Lock()
+	if c.exempt[tick.InstrumentKey] {
+		c.queue = append(c.queue, conflatedEntry{key: tick.InstrumentKey, tick: tick})
+	} else {
+		if _, queued := c.latest[tick.InstrumentKey]; !queued {
+			c.queue = append(c.queue, conflatedEntry{key: tick.InstrumentKey, conflated: true})
+		}
+		c.latest[tick.InstrumentKey] = tick
+	}
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *tickConflator) next() (Tick, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.head == len(c.queue) {
+		return Tick{}, false
+	}
+
+	entry := c.queue[c.head]
+	c.queue[c.head] = conflatedEntry{}
+	c.head++
+	if c.head == len(c.queue) {
+		c.queue = c.queue[:0]
+		c.head = 0
+	}
+
+	if !entry.conflated {
+		return entry.tick, true
+	}
+	tick := c.latest[entry.key]
+	delete(c.latest, entry.key)
+	return tick, true
+}
+
+func (c *tickConflator) run(wsm *WebSocketManager) {
+	labelGoroutine("tick_conflate")
+
+	for {
+		select {
+		case <-wsm.ctx.Done():
+			return
+		case <-c.wake:
+		}
+
+		for {
+			wsm.mu.RLock()
+			current := wsm.conflator == c
+			wsm.mu.RUnlock()
+			if !current {
+				return
+			}
+
+			tick, ok := c.next()
+			if !ok {
+				break
+			}
+			c.callback(tick)
+		}
+	}
+}
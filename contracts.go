@@ -0,0 +1,89 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const optionContractURL = "https://api.upstox.com/v2/option/contract"
+
+// OptionContract is a listed option on an underlying. Expiry is the expiry
+// date as YYYY-MM-DD; InstrumentType is CE or PE.
+type OptionContract struct {
+	Name             string  `json:"name"`
+	Segment          string  `json:"segment"`
+	Exchange         string  `json:"exchange"`
+	Expiry           string  `json:"expiry"`
+	Weekly           bool    `json:"weekly"`
+	InstrumentKey    string  `json:"instrument_key"`
+	ExchangeToken    string  `json:"exchange_token"`
+	TradingSymbol    string  `json:"trading_symbol"`
+	TickSize         float64 `json:"tick_size"`
+	LotSize          int     `json:"lot_size"`
+	InstrumentType   string  `json:"instrument_type"`
+	FreezeQuantity   float64 `json:"freeze_quantity"`
+	UnderlyingKey    string  `json:"underlying_key"`
+	UnderlyingType   string  `json:"underlying_type"`
+	UnderlyingSymbol string  `json:"underlying_symbol"`
+	StrikePrice      float64 `json:"strike_price"`
+	MinimumLot       int     `json:"minimum_lot"`
+}
+
+type OptionContractResponse struct {
+	Status string           `json:"status"`
+	Data   []OptionContract `json:"data"`
+	Errors []OrderError     `json:"errors,omitempty"`
+}
+
+func (r *OptionContractResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetOptionContracts lists every option contract, across all expiries, on
+// the underlying, e.g. "NSE_INDEX|Nifty 50".
+func (m *Manager) GetOptionContracts(ctx context.Context, underlyingKey string) ([]OptionContract, error) {
+	return m.getOptionContracts(ctx, underlyingKey, "")
+}
+
+// GetOptionContractsForExpiry lists the strikes of a single expiry.
+func (m *Manager) GetOptionContractsForExpiry(ctx context.Context, underlyingKey string, expiry time.Time) ([]OptionContract, error) {
+	return m.getOptionContracts(ctx, underlyingKey, expiry.Format(time.DateOnly))
+}
+
+func (m *Manager) getOptionContracts(ctx context.Context, underlyingKey, expiry string) ([]OptionContract, error) {
+	if underlyingKey == "" {
+		return nil, fmt.Errorf("underlying key is required")
+	}
+
+	q := url.Values{}
+	q.Set("instrument_key", underlyingKey)
+	if expiry != "" {
+		q.Set("expiry_date", expiry)
+	}
+
+	req, err := m.newRequest(ctx, "GET", optionContractURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var contractResp OptionContractResponse
+	if err := m.do(req, &contractResp); err != nil {
+		return nil, err
+	}
+
+	return contractResp.Data, nil
+}
+
+// OptionExpiries returns the distinct expiry dates in contracts, earliest
+// first.
+func OptionExpiries(contracts []OptionContract) []string {
+	expiries := make([]string, 0)
+	for _, c := range contracts {
+		if !slices.Contains(expiries, c.Expiry) {
+			expiries = append(expiries, c.Expiry)
+		}
+	}
+	slices.Sort(expiries)
+	return expiries
+}
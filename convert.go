@@ -0,0 +1,78 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+)
+
+const convertPositionURL = "https://api.upstox.com/v2/portfolio/convert-position"
+
+// ConvertPositionRequest moves Quantity of an open position from OldProduct
+// to NewProduct, e.g. carrying an intraday position overnight as delivery.
+// TransactionType is the side of the open position.
+type ConvertPositionRequest struct {
+	InstrumentToken string      `json:"instrument_token"`
+	NewProduct      ProductType `json:"new_product"`
+	OldProduct      ProductType `json:"old_product"`
+	TransactionType string      `json:"transaction_type"`
+	Quantity        int         `json:"quantity"`
+}
+
+type ConvertPositionResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Status string `json:"status"`
+	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *ConvertPositionResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+func (m *Manager) ConvertPosition(ctx context.Context, convReq ConvertPositionRequest) (*ConvertPositionResponse, error) {
+	if err := validateConvertRequest(convReq); err != nil {
+		return nil, fmt.Errorf("invalid conversion: %w", err)
+	}
+
+	if m.dryRun {
+		m.logger.Info("dry run: would convert position", "instrument_token", convReq.InstrumentToken,
+			"quantity", convReq.Quantity, "side", convReq.TransactionType, "from", convReq.OldProduct, "to", convReq.NewProduct)
+		resp := &ConvertPositionResponse{Status: "success"}
+		resp.Data.Status = "complete"
+		return resp, nil
+	}
+
+	req, err := m.newRequest(ctx, "PUT", convertPositionURL, convReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var convResp ConvertPositionResponse
+	if err := m.do(req, &convResp); err != nil {
+		return nil, fmt.Errorf("failed to convert position: %w", err)
+	}
+
+	return &convResp, nil
+}
+
+func validateConvertRequest(convReq ConvertPositionRequest) error {
+	if convReq.InstrumentToken == "" {
+		return fmt.Errorf("instrument token is required")
+	}
+	if convReq.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive, got %d", convReq.Quantity)
+	}
+	if convReq.TransactionType != string(OrderSideBuy) && convReq.TransactionType != string(OrderSideSell) {
+		return fmt.Errorf("invalid transaction type: %q", convReq.TransactionType)
+	}
+	for _, p := range []ProductType{convReq.OldProduct, convReq.NewProduct} {
+		switch p {
+		case ProductIntraday, ProductDelivery, ProductMTF:
+		default:
+			return fmt.Errorf("unknown product %q", p)
+		}
+	}
+	if convReq.OldProduct == convReq.NewProduct {
+		return fmt.Errorf("position is already %s", convReq.NewProduct)
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package upstox
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CSVColumn string
+
+const (
+	ColTime       CSVColumn = "time" // exchange time for ticks (receive time if absent), bucket start for candles
+	ColReceivedAt CSVColumn = "received_at"
+	ColInstrument CSVColumn = "instrument_key"
+	ColLTP        CSVColumn = "ltp"
+	ColLTQ        CSVColumn = "ltq"
+	ColClosePrice CSVColumn = "close_price" // previous day's close carried on ticks
+	ColInterval   CSVColumn = "interval"
+	ColOpen       CSVColumn = "open"
+	ColHigh       CSVColumn = "high"
+	ColLow        CSVColumn = "low"
+	ColClose      CSVColumn = "close"
+	ColVolume     CSVColumn = "volume"
+	ColOI         CSVColumn = "oi"
+)
+
+var (
+	TickColumns   = []CSVColumn{ColTime, ColInstrument, ColLTP, ColLTQ}
+	CandleColumns = []CSVColumn{ColTime, ColInstrument, ColInterval, ColOpen, ColHigh, ColLow, ColClose, ColVolume, ColOI}
+)
+
+const csvTimeLayout = "2006-01-02 15:04:05.000"
+
+type CSVSinkConfig struct {
+	Dir    string
+	Prefix string // file name prefix, "feed" if empty
+	// Columns to write, in order. Columns that do not apply to a record, such
+	// as ltp on a candle, are left empty.
+	Columns []CSVColumn
+	// PerInstrument writes one file per instrument per day instead of one
+	// combined file per day.
+	PerInstrument bool
+	// FlushInterval bounds how long rows sit in memory; 0 means one second.
+	FlushInterval time.Duration
+	// Logger receives write failures from OnTick, OnCandle and the
+	// background flush; nil discards them.
+	Logger Logger
+}
+
+// CSVSink writes ticks (LTPC fields only) and candles to CSV files that
+// rotate at IST midnight, named <prefix>-<date>.csv or
+// <prefix>-<instrument>-<date>.csv. Existing
+// files are appended to, so a restart mid-day continues the same file. Rows
+// are buffered and flushed periodically; call Close on shutdown so nothing
+// buffered is lost.
+type CSVSink struct {
+	cfg CSVSinkConfig
+
+	mu     sync.Mutex
+	files  map[string]*csvFile
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+type csvFile struct {
+	day string
+	f   *os.File
+	buf *bufio.Writer
+	w   *csv.Writer
+}
+
+func NewCSVSink(cfg CSVSinkConfig) (*CSVSink, error) {
+	if len(cfg.Columns) == 0 {
+		return nil, errors.New("CSV sink needs at least one column")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "feed"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create CSV directory: %w", err)
+	}
+
+	s := &CSVSink{
+		cfg:   cfg,
+		files: make(map[string]*csvFile),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+func (s *CSVSink) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(s.OnTick)
+}
+
+// OnTick writes a tick, logging failures so it can be used as a listener.
+func (s *CSVSink) OnTick(tick Tick) {
+	if err := s.WriteTick(tick); err != nil {
+		s.cfg.Logger.Error("csv sink: write failed", "error", err)
+	}
+}
+
+// OnCandle has the CandleCloseCallback shape, for use with
+// CandleAggregator.OnCandleClose.
+func (s *CSVSink) OnCandle(instrumentKey string, interval Interval, c Candle) {
+	if err := s.WriteCandle(instrumentKey, interval, c); err != nil {
+		s.cfg.Logger.Error("csv sink: write failed", "error", err)
+	}
+}
+
+func (s *CSVSink) WriteTick(tick Tick) error {
+	t := tick.ReceivedAt
+	if tick.LTT > 0 {
+		t = time.UnixMilli(tick.LTT)
+	}
+
+	row := make([]string, len(s.cfg.Columns))
+	for i, col := range s.cfg.Columns {
+		switch col {
+		case ColTime:
+			row[i] = t.In(IST).Format(csvTimeLayout)
+		case ColReceivedAt:
+			row[i] = tick.ReceivedAt.In(IST).Format(csvTimeLayout)
+		case ColInstrument:
+			row[i] = tick.InstrumentKey
+		case ColLTP:
+			row[i] = formatCSVFloat(tick.LTP)
+		case ColLTQ:
+			row[i] = strconv.FormatInt(tick.LTQ, 10)
+		case ColClosePrice:
+			row[i] = formatCSVFloat(tick.CP)
+		}
+	}
+	return s.write(tick.InstrumentKey, t, row)
+}
+
+func (s *CSVSink) WriteCandle(instrumentKey string, interval Interval, c Candle) error {
+	row := make([]string, len(s.cfg.Columns))
+	for i, col := range s.cfg.Columns {
+		switch col {
+		case ColTime:
+			row[i] = c.Timestamp.In(IST).Format(csvTimeLayout)
+		case ColInstrument:
+			row[i] = instrumentKey
+		case ColInterval:
+			row[i] = string(interval)
+		case ColOpen:
+			row[i] = formatCSVFloat(c.Open)
+		case ColHigh:
+			row[i] = formatCSVFloat(c.High)
+		case ColLow:
+			row[i] = formatCSVFloat(c.Low)
+		case ColClose:
+			row[i] = formatCSVFloat(c.Close)
+		case ColVolume:
+			row[i] = strconv.FormatInt(c.Volume, 10)
+		case ColOI:
+			row[i] = strconv.FormatInt(c.OpenInterest, 10)
+		}
+	}
+	return s.write(instrumentKey, c.Timestamp, row)
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (s *CSVSink) write(instrumentKey string, t time.Time, row []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("CSV sink is closed")
+	}
+
+	name := ""
+	if s.cfg.PerInstrument {
+		name = instrumentKey
+	}
+	day := t.In(IST).Format("2006-01-02")
+
+	cf := s.files[name]
+	if cf != nil && cf.day != day {
+		// Rows are written in arrival order, so a row for another day means
+		// the old file is finished.
+		if err := cf.close(); err != nil {
+			s.cfg.Logger.Error("csv sink: failed to close file", "file", cf.f.Name(), "error", err)
+		}
+		cf = nil
+	}
+	if cf == nil {
+		var err error
+		if cf, err = s.open(name, day); err != nil {
+			delete(s.files, name)
+			return err
+		}
+		s.files[name] = cf
+	}
+
+	if err := cf.w.Write(row); err != nil {
+		return fmt.Errorf("failed to write %s: %w", cf.f.Name(), err)
+	}
+	return nil
+}
+
+// sinkPath names a day's file for the file sinks: <prefix>-<date><ext>, or
+// <prefix>-<instrument>-<date><ext> when name is set.
+func sinkPath(dir, prefix, name, day, ext string) string {
+	parts := []string{prefix}
+	if name != "" {
+		parts = append(parts, strings.NewReplacer("|", "_", " ", "_", "/", "_").Replace(name))
+	}
+	parts = append(parts, day)
+	return filepath.Join(dir, strings.Join(parts, "-")+ext)
+}
+
+func (s *CSVSink) open(name, day string) (*csvFile, error) {
+	path := sinkPath(s.cfg.Dir, s.cfg.Prefix, name, day, ".csv")
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+
+	buf := bufio.NewWriter(f)
+	cf := &csvFile{day: day, f: f, buf: buf, w: csv.NewWriter(buf)}
+	if info.Size() == 0 {
+		header := make([]string, len(s.cfg.Columns))
+		for i, col := range s.cfg.Columns {
+			header[i] = string(col)
+		}
+		cf.w.Write(header)
+	}
+	return cf, nil
+}
+
+func (cf *csvFile) flush() error {
+	cf.w.Flush()
+	if err := cf.w.Error(); err != nil {
+		return err
+	}
+	return cf.buf.Flush()
+}
+
+func (cf *csvFile) close() error {
+	err := cf.flush()
+	if cerr := cf.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Flush pushes buffered rows to disk.
+func (s *CSVSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, cf := range s.files {
+		if err := cf.flush(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush %s: %w", cf.f.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *CSVSink) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				s.cfg.Logger.Error("csv sink: flush failed", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close flushes and closes every file. Writes after Close fail.
+func (s *CSVSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for name, cf := range s.files {
+		if err := cf.close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", cf.f.Name(), err))
+		}
+		delete(s.files, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/adeludedperson/go-upstox/pb"
+)
+
+// DepthSnapshot is a one-shot view of an instrument's order book.
+type DepthSnapshot struct {
+	InstrumentKey     string
+	LastPrice         float64
+	Bids              []DepthLevel
+	Asks              []DepthLevel
+	TotalBuyQuantity  float64
+	TotalSellQuantity float64
+	FetchedAt         time.Time
+}
+
+// GetMarketDepth fetches up to levels price levels per side over REST, for
+// code that needs the book once without subscribing to the feed. The full
+// quote endpoint serves five levels; levels <= 0 returns all of them.
+func (m *Manager) GetMarketDepth(ctx context.Context, instrumentKey string, levels int) (*DepthSnapshot, error) {
+	quotes, err := m.GetFullQuotes(ctx, instrumentKey)
+	if err != nil {
+		return nil, err
+	}
+	quote, ok := quotes[instrumentKey]
+	if !ok {
+		return nil, fmt.Errorf("no quote returned for %s", instrumentKey)
+	}
+
+	bids, asks := quote.Depth.Buy, quote.Depth.Sell
+	if levels > 0 {
+		bids = bids[:min(levels, len(bids))]
+		asks = asks[:min(levels, len(asks))]
+	}
+
+	return &DepthSnapshot{
+		InstrumentKey:     instrumentKey,
+		LastPrice:         quote.LastPrice,
+		Bids:              bids,
+		Asks:              asks,
+		TotalBuyQuantity:  quote.TotalBuyQuantity,
+		TotalSellQuantity: quote.TotalSellQuantity,
+		FetchedAt:         time.Now(),
+	}, nil
+}
+
+func (d *DepthSnapshot) BestBid() (DepthLevel, bool) {
+	for _, l := range d.Bids {
+		if l.Quantity > 0 {
+			return l, true
+		}
+	}
+	return DepthLevel{}, false
+}
+
+func (d *DepthSnapshot) BestAsk() (DepthLevel, bool) {
+	for _, l := range d.Asks {
+		if l.Quantity > 0 {
+			return l, true
+		}
+	}
+	return DepthLevel{}, false
+}
+
+// Spread is best ask minus best bid; ok is false if either side is empty.
+func (d *DepthSnapshot) Spread() (spread float64, ok bool) {
+	bid, okBid := d.BestBid()
+	ask, okAsk := d.BestAsk()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// EstimateFill walks the visible book for a market order of quantity on side
+// and returns the average price of what the snapshot could fill. filled is
+// less than quantity when the visible depth runs out.
+func (d *DepthSnapshot) EstimateFill(side OrderSide, quantity int64) (avgPrice float64, filled int64) {
+	levels := d.Asks
+	if side == OrderSideSell {
+		levels = d.Bids
+	}
+
+	var value float64
+	for _, l := range levels {
+		if filled >= quantity {
+			break
+		}
+		take := min(l.Quantity, quantity-filled)
+		value += float64(take) * l.Price
+		filled += take
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return value / float64(filled), filled
+}
+
+type DepthCallback func(depth *DepthSnapshot)
+
+// OnDepth delivers the order book carried by full and full_d30 feeds: five
+// levels per side in full mode and thirty in full_d30, e.g. after
+// SubscribeWithMode(ModeFullD30, keys...). Feed depth carries no order
+// counts, so Orders is zero. fn runs on the read goroutine and may retain the
+// snapshot.
+func (wsm *WebSocketManager) OnDepth(fn DepthCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.onDepth = fn
+}
+
+// feedDepth converts a full feed's bid/ask ladder into a snapshot, or returns
+// nil when the feed carries no depth.
+func feedDepth(instrumentKey string, feed *pb.Feed, receivedAt time.Time) *DepthSnapshot {
+	m := feed.GetFullFeed().GetMarketFF()
+	levels := m.GetMarketLevel().GetBidAskQuote()
+	if len(levels) == 0 {
+		return nil
+	}
+
+	d := &DepthSnapshot{
+		InstrumentKey:     instrumentKey,
+		LastPrice:         m.GetLtpc().GetLtp(),
+		Bids:              make([]DepthLevel, 0, len(levels)),
+		Asks:              make([]DepthLevel, 0, len(levels)),
+		TotalBuyQuantity:  m.GetTbq(),
+		TotalSellQuantity: m.GetTsq(),
+		FetchedAt:         receivedAt,
+	}
+	for _, q := range levels {
+		d.Bids = append(d.Bids, DepthLevel{Quantity: q.GetBidQ(), Price: q.GetBidP()})
+		d.Asks = append(d.Asks, DepthLevel{Quantity: q.GetAskQ(), Price: q.GetAskP()})
+	}
+	return d
+}
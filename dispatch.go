@@ -0,0 +1,116 @@
+package upstox
+
+import (
+	"time"
+
+	pb "github.com/adeludedperson/go-upstox/pb"
+)
+
+// feedLTPCMessage returns the LTPC block of a feed whichever FeedUnion variant
+// carries it, or nil when the feed has none.
+func feedLTPCMessage(feed *pb.Feed) *pb.LTPC {
+	if ltpc := feed.GetLtpc(); ltpc != nil {
+		return ltpc
+	}
+	if ff := feed.GetFullFeed(); ff != nil {
+		if m := ff.GetMarketFF(); m != nil {
+			return m.GetLtpc()
+		}
+		return ff.GetIndexFF().GetLtpc()
+	}
+	return feed.GetFirstLevelWithGreeks().GetLtpc()
+}
+
+func feedGreeksMessage(feed *pb.Feed) *pb.OptionGreeks {
+	if g := feed.GetFirstLevelWithGreeks().GetOptionGreeks(); g != nil {
+		return g
+	}
+	return feed.GetFullFeed().GetMarketFF().GetOptionGreeks()
+}
+
+// dispatch fans a decoded tick out to every registered consumer. It runs on
+// the read goroutine, so consumers that do real work should use the batched
+// or channel-based delivery paths instead of the synchronous callback.
+func (wsm *WebSocketManager) dispatch(tick Tick) {
+	wsm.subs.setLastTick(tick)
+
+	wsm.mu.RLock()
+	listeners := wsm.listeners
+	batcher := wsm.batcher
+	conflator := wsm.conflator
+	dispatcher := wsm.dispatcher
+	feed := wsm.feed
+	synthetics := wsm.syntheticsByLeg[tick.InstrumentKey]
+	wsm.mu.RUnlock()
+
+	for _, l := range listeners {
+		l.fn(tick)
+	}
+
+	if wsm.onPriceUpdate != nil {
+		var ltq *int32
+		if tick.LTQ != 0 {
+			ltqVal := int32(tick.LTQ)
+			ltq = &ltqVal
+		}
+		wsm.onPriceUpdate(tick.InstrumentKey, tick.LTP, ltq)
+	}
+
+	if batcher != nil {
+		batcher.add(tick)
+	}
+	if conflator != nil {
+		conflator.add(tick)
+	}
+	if dispatcher != nil {
+		dispatcher.Dispatch(tick)
+	}
+	if feed != nil {
+		feed.send(wsm.ctx, tick)
+	}
+
+	for _, syn := range synthetics {
+		if synTick, ok := wsm.syntheticTick(syn, tick); ok {
+			wsm.dispatch(synTick)
+		}
+	}
+}
+
+type tickListener struct {
+	fn TickCallback
+}
+
+// InjectTick delivers tick to every consumer as if it had arrived on the
+// feed. It is meant for tests, fakes and replays of feeds that are not
+// connected.
+func (wsm *WebSocketManager) InjectTick(tick Tick) {
+	if tick.ReceivedAt.IsZero() {
+		tick.ReceivedAt = time.Now()
+	}
+	wsm.dispatch(tick)
+}
+
+// AddTickListener registers fn to receive every tick synchronously on the
+// read goroutine, alongside any other listeners. Listeners must return quickly;
+// hand heavy work to a goroutine. Call the returned function to remove fn.
+func (wsm *WebSocketManager) AddTickListener(fn TickCallback) (remove func()) {
+	l := &tickListener{fn: fn}
+
+	wsm.mu.Lock()
+	// Copy on write so dispatch can iterate its snapshot without holding the lock.
+	wsm.listeners = append(wsm.listeners[:len(wsm.listeners):len(wsm.listeners)], l)
+	wsm.mu.Unlock()
+
+	return func() {
+		wsm.mu.Lock()
+		defer wsm.mu.Unlock()
+		for i, existing := range wsm.listeners {
+			if existing == l {
+				next := make([]*tickListener, 0, len(wsm.listeners)-1)
+				next = append(next, wsm.listeners[:i]...)
+				wsm.listeners = append(next, wsm.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
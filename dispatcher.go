@@ -0,0 +1,84 @@
+package upstox
+
+import "sync"
+
+// TickDispatcher fans ticks out to a fixed pool of workers while preserving
+// per-instrument order: every tick for a given instrument is hashed to the
+// same worker, so heavy per-tick work runs in parallel across instruments but
+// never reorders one instrument's updates.
+//
+// Dispatch blocks when the target worker's queue is full; dropping would break
+// the ordering guarantee. Combine with conflation if dropping is acceptable.
+type TickDispatcher struct {
+	queues  []chan Tick
+	handler TickCallback
+	wg      sync.WaitGroup
+
+	// mu is held for reading while a tick is queued, so Close cannot close
+	// a queue under a blocked Dispatch.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewTickDispatcher(workers, queueSize int, handler TickCallback) *TickDispatcher {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	d := &TickDispatcher{
+		queues:  make([]chan Tick, workers),
+		handler: handler,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan Tick, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+func (d *TickDispatcher) work(queue <-chan Tick) {
+	labelGoroutine("tick_dispatch")
+
+	defer d.wg.Done()
+	for tick := range queue {
+		d.handler(tick)
+	}
+}
+
+// Dispatch queues tick on its instrument's worker. Ticks dispatched after
+// Close are dropped, so a feed still attached when the dispatcher closes
+// does not panic.
+func (d *TickDispatcher) Dispatch(tick Tick) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	d.queues[hashKey(tick.InstrumentKey)%uint32(len(d.queues))] <- tick
+}
+
+// Close stops accepting ticks, lets the workers drain their queues and waits
+// for them to finish. It must not be called from the handler.
+func (d *TickDispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, q := range d.queues {
+			close(q)
+		}
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// DispatchTo routes every tick from this manager through d. Pass nil to
+// detach.
+func (wsm *WebSocketManager) DispatchTo(d *TickDispatcher) {
+	wsm.mu.Lock()
+	wsm.dispatcher = d
+	wsm.mu.Unlock()
+}
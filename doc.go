@@ -0,0 +1,19 @@
+// Package upstox is a Go client for the Upstox trading and market data APIs.
+//
+// # High-throughput feeds
+//
+// Decoding dominates the cost of consuming 30-level depth. The figures below
+// come from go test -bench HandleFrame on an Intel Xeon (Go 1.23), feeding
+// synthetic full_d30 frames through WebSocketManager.HandleFrame:
+//
+//	instruments/frame  full decode + callback          OnFastPrice
+//	1                  5.9 µs,   54 allocs             0.2 µs, 0 allocs
+//	50                 301 µs,   2700 allocs           7.5 µs, 0 allocs
+//	200                1.34 ms,  10800 allocs          34 µs,  0 allocs
+//
+// Full decoding costs roughly 6 µs and 54 allocations per instrument per
+// frame, so a few hundred depth instruments can saturate a core during
+// volatile sessions. HighThroughputConfig subscribes in full_d30 with a 1 MiB
+// read buffer; combine it with OnTickBatch so downstream work happens off the
+// read goroutine, and switch to OnFastPrice when only prices are needed.
+package upstox
@@ -0,0 +1,44 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+)
+
+const dryRunOrderPrefix = "DRYRUN-"
+
+func (m *Manager) IsDryRun() bool {
+	return m.dryRun
+}
+
+func (m *Manager) dryRunOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error) {
+	guid, err := generateGUID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate dry-run order ID: %w", err)
+	}
+
+	m.logger.Info("dry run: would place order", "side", orderReq.TransactionType, "order_type", orderReq.OrderType,
+		"quantity", orderReq.Quantity, "instrument_token", orderReq.InstrumentToken, "product", orderReq.Product,
+		"validity", orderReq.Validity, "price", orderReq.Price, "trigger_price", orderReq.TriggerPrice)
+
+	// Margin and charges are informational here; a failure to price the order
+	// should not stop a strategy that is being exercised in dry-run mode.
+	if margin, err := m.GetMargin(ctx, orderReq); err != nil {
+		m.logger.Warn("dry run: margin lookup failed", "error", err)
+	} else {
+		m.logger.Info("dry run: margin", "required", margin.RequiredMargin, "final", margin.FinalMargin)
+	}
+	if charges, err := m.GetBrokerage(ctx, orderReq); err != nil {
+		m.logger.Warn("dry run: brokerage lookup failed", "error", err)
+	} else {
+		m.logger.Info("dry run: estimated charges", "total", charges.Total, "brokerage", charges.Brokerage)
+	}
+
+	return &OrderResponse{
+		Status: "success",
+		Data: &OrderResponseData{
+			OrderIDs: []string{dryRunOrderPrefix + guid},
+		},
+		DryRun: true,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrDuplicateOrder = errors.New("duplicate order suppressed")
+
+// WithDuplicateWindow rejects an order identical to one submitted less than
+// window ago (same instrument, side, quantity and order type). Set
+// OrderRequest.Force, or use ForcePlaceMarketOrder, to bypass the check.
+func WithDuplicateWindow(window time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.duplicateWindow = window
+	}
+}
+
+func (m *Manager) ForcePlaceMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string) (*OrderResponse, error) {
+	orderReq := marketOrderRequest(instrumentToken, quantity, side)
+	orderReq.Force = true
+	return m.placeOrder(ctx, orderReq)
+}
+
+func duplicateKey(req OrderRequest) string {
+	return fmt.Sprintf("%s|%s|%d|%s", req.InstrumentToken, req.TransactionType, req.Quantity, req.OrderType)
+}
+
+// checkDuplicate records req and reports ErrDuplicateOrder if an identical
+// order was seen inside the window. The order is recorded before it is sent,
+// so two goroutines racing on the same signal cannot both get through.
+func (m *Manager) checkDuplicate(req OrderRequest) error {
+	if m.duplicateWindow <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	key := duplicateKey(req)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.recentOrders == nil {
+		m.recentOrders = make(map[string]time.Time)
+	}
+
+	for k, t := range m.recentOrders {
+		if now.Sub(t) >= m.duplicateWindow {
+			delete(m.recentOrders, k)
+		}
+	}
+
+	if last, ok := m.recentOrders[key]; ok && !req.Force {
+		return fmt.Errorf("%w: %s submitted %v ago", ErrDuplicateOrder, key, now.Sub(last).Round(time.Millisecond))
+	}
+
+	m.recentOrders[key] = now
+	return nil
+}
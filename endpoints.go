@@ -0,0 +1,87 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+type APIVersion string
+
+const (
+	V2 APIVersion = "v2"
+	V3 APIVersion = "v3"
+)
+
+// Endpoint names an API that Upstox serves in more than one version. The
+// client defaults to the newest version it supports; WithEndpointVersion and
+// ContextWithEndpointVersion select another.
+type Endpoint string
+
+const (
+	EndpointPlaceOrder       Endpoint = "order/place"
+	EndpointModifyOrder      Endpoint = "order/modify"
+	EndpointCancelOrder      Endpoint = "order/cancel"
+	EndpointHistoricalCandle Endpoint = "historical-candle"
+	EndpointIntradayCandle   Endpoint = "historical-candle/intraday"
+	EndpointLTP              Endpoint = "market-quote/ltp"
+)
+
+type endpointSpec struct {
+	host     string
+	versions []APIVersion // oldest first; the last is the default
+}
+
+var endpointRegistry = map[Endpoint]endpointSpec{
+	EndpointPlaceOrder:       {host: "https://" + orderHost, versions: []APIVersion{V2, V3}},
+	EndpointModifyOrder:      {host: "https://" + orderHost, versions: []APIVersion{V2, V3}},
+	EndpointCancelOrder:      {host: "https://" + orderHost, versions: []APIVersion{V2, V3}},
+	EndpointHistoricalCandle: {host: "https://api.upstox.com", versions: []APIVersion{V2, V3}},
+	EndpointIntradayCandle:   {host: "https://api.upstox.com", versions: []APIVersion{V2, V3}},
+	EndpointLTP:              {host: "https://api.upstox.com", versions: []APIVersion{V2, V3}},
+}
+
+// Versions lists the API versions the client can use for e, oldest first.
+func (e Endpoint) Versions() []APIVersion {
+	return slices.Clone(endpointRegistry[e].versions)
+}
+
+// WithEndpointVersion makes every call to e use version v unless the call's
+// context says otherwise. An unsupported version makes those calls fail.
+func WithEndpointVersion(e Endpoint, v APIVersion) ManagerOption {
+	return func(m *Manager) {
+		if m.endpointVersions == nil {
+			m.endpointVersions = make(map[Endpoint]APIVersion)
+		}
+		m.endpointVersions[e] = v
+	}
+}
+
+type endpointVersionKey struct{ e Endpoint }
+
+// ContextWithEndpointVersion overrides the version of e for calls made with
+// the returned context.
+func ContextWithEndpointVersion(ctx context.Context, e Endpoint, v APIVersion) context.Context {
+	return context.WithValue(ctx, endpointVersionKey{e}, v)
+}
+
+// endpointURL resolves e for one call: the context override, then the
+// Manager's configured version, then the newest supported version.
+func (m *Manager) endpointURL(ctx context.Context, e Endpoint) (string, APIVersion, error) {
+	spec, ok := endpointRegistry[e]
+	if !ok {
+		return "", "", fmt.Errorf("unknown endpoint %q", e)
+	}
+
+	v := spec.versions[len(spec.versions)-1]
+	if cv, ok := m.endpointVersions[e]; ok {
+		v = cv
+	}
+	if cv, ok := ctx.Value(endpointVersionKey{e}).(APIVersion); ok {
+		v = cv
+	}
+	if !slices.Contains(spec.versions, v) {
+		return "", "", fmt.Errorf("endpoint %s has no %s version", e, v)
+	}
+	return fmt.Sprintf("%s/%s/%s", spec.host, v, e), v, nil
+}
@@ -0,0 +1,143 @@
+package upstox
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinels for common API failures. A returned *APIError matches them with
+// errors.Is, e.g. errors.Is(err, ErrInvalidToken). ErrInsufficientFunds
+// (funds.go) and ErrNotModifiable (variety.go) are matched the same way.
+var (
+	ErrInvalidToken  = errors.New("invalid or expired access token")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrMarketClosed  = errors.New("market closed")
+	ErrOrderNotFound = errors.New("order not found")
+	ErrInvalidInput  = errors.New("invalid request parameters")
+)
+
+// APIError is returned whenever Upstox answers with a non-2xx HTTP status or
+// with a JSON envelope whose status is not "success".
+type APIError struct {
+	StatusCode int
+	Status     string
+	Errors     []OrderError
+	Body       string
+
+	// RetryAfter is how long the API asked the caller to wait, on 429 and
+	// some 503 responses.
+	RetryAfter time.Duration
+	// Quota holds the response's rate-limit headers, if it had any.
+	Quota *Quota
+}
+
+// UpstoxError is the same type as APIError, for use with errors.As.
+type UpstoxError = APIError
+
+func (e *APIError) Error() string {
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("API error: status %d, %s: %s", e.StatusCode, e.Errors[0].ErrorCode, e.Errors[0].Message)
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("API error: status %d, body: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("API error: status %d, response status '%s'", e.StatusCode, e.Status)
+}
+
+// Code returns the first error_code reported by the API, if any.
+func (e *APIError) Code() string {
+	if len(e.Errors) > 0 {
+		return e.Errors[0].ErrorCode
+	}
+	return ""
+}
+
+// Message returns the first API error message, falling back to Error().
+func (e *APIError) Message() string {
+	if len(e.Errors) > 0 && e.Errors[0].Message != "" {
+		return e.Errors[0].Message
+	}
+	return e.Error()
+}
+
+// Field returns the request field the first error refers to, if any.
+func (e *APIError) Field() string {
+	if len(e.Errors) > 0 {
+		return e.Errors[0].PropertyPath
+	}
+	return ""
+}
+
+// apiErrorCodes maps documented error codes to sentinels.
+var apiErrorCodes = map[string]error{
+	"UDAPI100050": ErrInvalidToken,
+	"UDAPI100016": ErrInvalidToken,
+	"UDAPI10005":  ErrRateLimited,
+}
+
+// rejectionSentinels maps the rejection reasons that have a sentinel to it, so
+// messages are classified by ClassifyRejection's phrase list alone.
+var rejectionSentinels = map[RejectionReason]error{
+	RejectionInsufficientMargin: ErrInsufficientFunds,
+	RejectionMarketClosed:       ErrMarketClosed,
+}
+
+// Is reports whether e is one of the sentinels above, judged by HTTP status,
+// then error code, then the wording of the API's messages as classified by
+// ClassifyRejection.
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return target == ErrInvalidToken
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+
+	for _, oe := range e.Errors {
+		if sentinel, ok := apiErrorCodes[oe.ErrorCode]; ok && sentinel == target {
+			return true
+		}
+		if sentinel, ok := rejectionSentinels[ClassifyRejection(oe.Message)]; ok && sentinel == target {
+			return true
+		}
+		switch target {
+		case ErrOrderNotFound:
+			msg := strings.ToLower(oe.Message)
+			if strings.Contains(msg, "order") && strings.Contains(msg, "not found") {
+				return true
+			}
+		case ErrInvalidInput:
+			if oe.PropertyPath != "" {
+				return true
+			}
+		case ErrNotModifiable:
+			if orderStateConflict(oe.Message) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// orderStateConflict reports whether msg says an order could not be changed
+// because of the state it is in, e.g. it has already filled or been
+// cancelled.
+func orderStateConflict(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, phrase := range []string{"not modifiable", "cannot be modified", "cannot modify", "modifiable state"} {
+		if strings.Contains(msg, phrase) {
+			return true
+		}
+	}
+	if strings.Contains(msg, "already") {
+		for _, state := range []string{"complete", "executed", "filled", "cancel", "reject"} {
+			if strings.Contains(msg, state) {
+				return true
+			}
+		}
+	}
+	return false
+}
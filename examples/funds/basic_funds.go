@@ -1,19 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/rehanshaik/go-upstox"
+	"github.com/adeludedperson/go-upstox"
 )
 
 func main() {
 	// Replace with your actual credentials and access token
 	manager := upstox.NewManager("your_client_id", "your_client_secret", "your_access_token")
+	ctx := context.Background()
 
 	// Get all funds (both equity and commodity)
 	fmt.Println("Getting all funds...")
-	funds, err := manager.GetFundsAndMargin()
+	funds, err := manager.GetFundsAndMargin(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get funds: %v", err)
 	}
@@ -39,7 +41,7 @@ func main() {
 
 	// Get only equity funds
 	fmt.Println("\n\nGetting equity funds only...")
-	equityFunds, err := manager.GetFundsAndMargin("SEC")
+	equityFunds, err := manager.GetFundsAndMargin(ctx, "SEC")
 	if err != nil {
 		log.Fatalf("Failed to get equity funds: %v", err)
 	}
@@ -48,7 +50,7 @@ func main() {
 
 	// Get only commodity funds
 	fmt.Println("\nGetting commodity funds only...")
-	commodityFunds, err := manager.GetFundsAndMargin("COM")
+	commodityFunds, err := manager.GetFundsAndMargin(ctx, "COM")
 	if err != nil {
 		log.Fatalf("Failed to get commodity funds: %v", err)
 	}
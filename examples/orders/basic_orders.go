@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -14,6 +15,7 @@ func main() {
 	accessToken := "your_access_token"
 
 	manager := upstox.NewManager(clientID, clientSecret, accessToken)
+	ctx := context.Background()
 
 	// Example instrument token for SBIN (State Bank of India)
 	instrumentToken := "NSE_EQ|INE062A01020"
@@ -22,7 +24,7 @@ func main() {
 
 	// Place a buy order for 1 share
 	fmt.Printf("Placing buy order for 1 share of %s...\n", instrumentToken)
-	buyResp, err := manager.PlaceBuyOrder(instrumentToken, 1)
+	buyResp, err := manager.PlaceBuyOrder(ctx, instrumentToken, 1)
 	if err != nil {
 		log.Fatalf("Failed to place buy order: %v", err)
 	}
@@ -38,7 +40,7 @@ func main() {
 
 	// Place a sell order for 1 share
 	fmt.Printf("\nPlacing sell order for 1 share of %s...\n", instrumentToken)
-	sellResp, err := manager.PlaceSellOrder(instrumentToken, 1)
+	sellResp, err := manager.PlaceSellOrder(ctx, instrumentToken, 1)
 	if err != nil {
 		log.Fatalf("Failed to place sell order: %v", err)
 	}
@@ -54,7 +56,7 @@ func main() {
 
 	// Alternative: Use PlaceMarketOrder directly
 	fmt.Printf("\nPlacing market order using PlaceMarketOrder method...\n")
-	marketResp, err := manager.PlaceMarketOrder(instrumentToken, 2, "BUY")
+	marketResp, err := manager.PlaceMarketOrder(ctx, instrumentToken, 2, "BUY")
 	if err != nil {
 		log.Fatalf("Failed to place market order: %v", err)
 	}
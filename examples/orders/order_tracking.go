@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -14,12 +15,13 @@ func main() {
 	accessToken := "your_access_token"
 
 	manager := upstox.NewManager(clientID, clientSecret, accessToken)
+	ctx := context.Background()
 
 	fmt.Println("=== Order Tracking Example ===")
 
 	// Get order book (all orders for the day)
 	fmt.Println("\n1. Fetching order book...")
-	orders, err := manager.GetOrderBook()
+	orders, err := manager.GetOrderBook(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get order book: %v", err)
 	}
@@ -48,7 +50,7 @@ func main() {
 			firstOrderID := orders[0].OrderID
 			fmt.Printf("2. Getting detailed information for order %s...\n", firstOrderID)
 			
-			orderDetail, err := manager.GetOrderDetails(firstOrderID)
+			orderDetail, err := manager.GetOrderDetails(ctx, firstOrderID)
 			if err != nil {
 				log.Fatalf("Failed to get order details: %v", err)
 			}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -14,12 +15,13 @@ func main() {
 	accessToken := "your_access_token"
 
 	manager := upstox.NewManager(clientID, clientSecret, accessToken)
+	ctx := context.Background()
 
 	fmt.Println("=== Position Management Example ===")
 
 	// Get current positions
 	fmt.Println("\n1. Fetching current positions...")
-	positions, err := manager.GetPositions()
+	positions, err := manager.GetPositions(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get positions: %v", err)
 	}
@@ -43,7 +45,7 @@ func main() {
 	instrumentToken := "NSE_EQ|INE062A01020" // SBIN
 	fmt.Printf("2. Attempting to close position for %s...\n", instrumentToken)
 	
-	closeResp, err := manager.ClosePosition(instrumentToken)
+	closeResp, err := manager.ClosePosition(ctx, instrumentToken)
 	if err != nil {
 		fmt.Printf("❌ Failed to close position: %v\n", err)
 	} else {
@@ -58,7 +60,7 @@ func main() {
 	
 	// Uncomment the following lines to actually close all positions
 	/*
-	responses, err := manager.CloseAllPositions()
+	responses, err := manager.CloseAllPositions(ctx)
 	if err != nil {
 		log.Fatalf("Failed to close all positions: %v", err)
 	}
@@ -0,0 +1,159 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrExpiryDayEntry = errors.New("new entries in contracts expiring today are blocked")
+
+// ExpiryGuard protects against carrying derivatives into expiry, where
+// in-the-money stock options settle physically and exercised options attract
+// STT on their full intrinsic value. Times are IST offsets from midnight.
+type ExpiryGuard struct {
+	Lookup InstrumentLookup
+
+	// BlockAfter rejects orders that open or add to a position in a
+	// contract expiring today once this time has passed; zero disables it.
+	BlockAfter time.Duration
+
+	// SquareOffAt is when RunExpiryGuard closes every position in a contract
+	// expiring today; zero disables it.
+	SquareOffAt time.Duration
+
+	// OnWarning, if set, is called by RunExpiryGuard for each expiring
+	// position it finds; otherwise the position is logged.
+	OnWarning func(Position, Instrument)
+}
+
+// WithExpiryGuard installs g. Its entry block applies to every order placed
+// through the Manager; warnings and square-off need RunExpiryGuard.
+func WithExpiryGuard(g ExpiryGuard) ManagerOption {
+	return func(m *Manager) {
+		m.expiryGuard = &g
+	}
+}
+
+func expiresOn(inst Instrument, day time.Time) bool {
+	if inst.Expiry == 0 {
+		return false
+	}
+	ey, em, ed := time.UnixMilli(inst.Expiry).In(IST).Date()
+	dy, dm, dd := day.In(IST).Date()
+	return ey == dy && em == dm && ed == dd
+}
+
+// checkExpiryEntry rejects entries into contracts expiring today after the
+// guard's cutoff. Positions are only fetched once both cheap checks match.
+func (m *Manager) checkExpiryEntry(ctx context.Context, orderReq OrderRequest) error {
+	g := m.expiryGuard
+	if g == nil || g.BlockAfter <= 0 || g.Lookup == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if sinceMidnight(now) < g.BlockAfter {
+		return nil
+	}
+	inst, ok := g.Lookup.ByKey(orderReq.InstrumentToken)
+	if !ok || !expiresOn(inst, now) {
+		return nil
+	}
+
+	positions, err := m.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions for expiry check: %w", err)
+	}
+	held := 0
+	for _, pos := range positions {
+		if pos.InstrumentToken == orderReq.InstrumentToken {
+			held += pos.Quantity
+		}
+	}
+
+	reduces := (orderReq.TransactionType == string(OrderSideSell) && held >= orderReq.Quantity) ||
+		(orderReq.TransactionType == string(OrderSideBuy) && -held >= orderReq.Quantity)
+	if reduces {
+		return nil
+	}
+	return fmt.Errorf("%w: %s expires today and the cutoff was %s", ErrExpiryDayEntry, inst.TradingSymbol, formatClock(g.BlockAfter))
+}
+
+// ExpiringPositions returns open positions in contracts expiring today,
+// paired with their instruments.
+func (m *Manager) ExpiringPositions(ctx context.Context, lookup InstrumentLookup) ([]Position, []Instrument, error) {
+	positions, err := m.GetPositions(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	now := time.Now()
+	var outPos []Position
+	var outInst []Instrument
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		if inst, ok := lookup.ByKey(pos.InstrumentToken); ok && expiresOn(inst, now) {
+			outPos = append(outPos, pos)
+			outInst = append(outInst, inst)
+		}
+	}
+	return outPos, outInst, nil
+}
+
+// RunExpiryGuard warns about expiring positions straight away and, if the
+// guard has a SquareOffAt time, waits for it and closes them at market. It
+// returns after the square-off, or when ctx is done.
+func (m *Manager) RunExpiryGuard(ctx context.Context) error {
+	g := m.expiryGuard
+	if g == nil || g.Lookup == nil {
+		return fmt.Errorf("no expiry guard configured")
+	}
+
+	positions, instruments, err := m.ExpiringPositions(ctx, g.Lookup)
+	if err != nil {
+		return err
+	}
+	for i, pos := range positions {
+		if g.OnWarning != nil {
+			g.OnWarning(pos, instruments[i])
+		} else {
+			m.logger.Warn("expiry guard: position expires today", "symbol", instruments[i].TradingSymbol, "quantity", pos.Quantity)
+		}
+	}
+
+	if g.SquareOffAt <= 0 {
+		return nil
+	}
+
+	if wait := g.SquareOffAt - sinceMidnight(time.Now()); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	// Re-read positions: some may have been closed or opened since the warning.
+	positions, instruments, err = m.ExpiringPositions(ctx, g.Lookup)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, pos := range positions {
+		m.logger.Info("expiry guard: squaring off", "symbol", instruments[i].TradingSymbol, "quantity", pos.Quantity)
+		if _, err := m.ClosePosition(ctx, pos.InstrumentToken); err != nil {
+			errs = append(errs, fmt.Errorf("failed to square off %s: %w", instruments[i].TradingSymbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
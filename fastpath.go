@@ -0,0 +1,185 @@
+package upstox
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// PriceHandler receives only the last traded price and quantity of a tick.
+type PriceHandler func(instrumentKey string, ltp float64, ltq int64)
+
+// Field numbers from upstox-market-data.proto used by the fast path.
+const (
+	fieldFeedResponseFeeds protowire.Number = 2
+	fieldMapKey            protowire.Number = 1
+	fieldMapValue          protowire.Number = 2
+	fieldFeedLTPC          protowire.Number = 1
+	fieldFeedFullFeed      protowire.Number = 2
+	fieldFeedFirstLevel    protowire.Number = 3
+	fieldFullFeedMarketFF  protowire.Number = 1
+	fieldFullFeedIndexFF   protowire.Number = 2
+	fieldNestedLTPC        protowire.Number = 1
+	fieldLTPCLtp           protowire.Number = 1
+	fieldLTPCLtq           protowire.Number = 3
+)
+
+var errMalformedFrame = errors.New("malformed feed frame")
+
+// OnFastPrice switches the manager into a price-only mode: frames are walked
+// directly on the wire format to pull out LTP/LTQ, without materialising the
+// protobuf structs, and instrument keys are interned in a symbol table so the
+// steady state allocates nothing per tick. While a fast handler is set the
+// regular decode path (onPriceUpdate and friends) is bypassed entirely.
+//
+// The handler runs on the read goroutine; the key string it receives is
+// interned and safe to retain.
+func (wsm *WebSocketManager) OnFastPrice(fn PriceHandler) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	wsm.fastPrice = fn
+	if wsm.symbols == nil {
+		wsm.symbols = make(map[string]string, len(wsm.config.InstrumentKeys))
+		for _, key := range wsm.config.InstrumentKeys {
+			wsm.symbols[key] = key
+		}
+	}
+}
+
+// intern returns a canonical string for key. The map lookup with string(key)
+// does not allocate, so only the first sighting of an instrument costs an
+// allocation. Called only from the read goroutine.
+func (wsm *WebSocketManager) intern(key []byte) string {
+	if s, ok := wsm.symbols[string(key)]; ok {
+		return s
+	}
+	s := string(key)
+	wsm.symbols[s] = s
+	return s
+}
+
+func (wsm *WebSocketManager) processFastPrice(data []byte, fn PriceHandler) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return errMalformedFrame
+		}
+		data = data[n:]
+
+		if num != fieldFeedResponseFeeds || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return errMalformedFrame
+			}
+			data = data[n:]
+			continue
+		}
+
+		entry, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return errMalformedFrame
+		}
+		data = data[n:]
+
+		key, _ := wireBytesField(entry, fieldMapKey)
+		feed, ok := wireBytesField(entry, fieldMapValue)
+		if !ok || len(key) == 0 {
+			continue
+		}
+
+		ltpc, ok := feedLTPC(feed)
+		if !ok {
+			continue
+		}
+
+		ltp, ltq := wireLTPC(ltpc)
+		if ltp > 0 {
+			fn(wsm.intern(key), ltp, ltq)
+		}
+	}
+	return nil
+}
+
+// feedLTPC locates the LTPC sub-message regardless of which FeedUnion variant
+// (ltpc, fullFeed.marketFF/indexFF, firstLevelWithGreeks) carries it.
+func feedLTPC(feed []byte) ([]byte, bool) {
+	if b, ok := wireBytesField(feed, fieldFeedLTPC); ok {
+		return b, true
+	}
+	if full, ok := wireBytesField(feed, fieldFeedFullFeed); ok {
+		if ff, ok := wireBytesField(full, fieldFullFeedMarketFF); ok {
+			return wireBytesField(ff, fieldNestedLTPC)
+		}
+		if ff, ok := wireBytesField(full, fieldFullFeedIndexFF); ok {
+			return wireBytesField(ff, fieldNestedLTPC)
+		}
+		return nil, false
+	}
+	if fl, ok := wireBytesField(feed, fieldFeedFirstLevel); ok {
+		return wireBytesField(fl, fieldNestedLTPC)
+	}
+	return nil, false
+}
+
+func wireLTPC(b []byte) (ltp float64, ltq int64) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ltp, ltq
+		}
+		b = b[n:]
+
+		switch {
+		case num == fieldLTPCLtp && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return ltp, ltq
+			}
+			ltp = math.Float64frombits(v)
+			b = b[n:]
+		case num == fieldLTPCLtq && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return ltp, ltq
+			}
+			ltq = int64(v)
+			b = b[n:]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return ltp, ltq
+			}
+			b = b[n:]
+		}
+	}
+	return ltp, ltq
+}
+
+// wireBytesField returns the payload of the first length-delimited field with
+// the given number, as a sub-slice of b.
+func wireBytesField(b []byte, want protowire.Number) ([]byte, bool) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, false
+		}
+		b = b[n:]
+
+		if num == want && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, false
+			}
+			return v, true
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil, false
+		}
+		b = b[n:]
+	}
+	return nil, false
+}
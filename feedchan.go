@@ -0,0 +1,107 @@
+package upstox
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// BackpressurePolicy decides what happens to a tick when the Feeds channel
+// is full.
+type BackpressurePolicy int
+
+const (
+	// DropNewest discards the incoming tick, keeping the read loop moving.
+	DropNewest BackpressurePolicy = iota
+	// DropOldest discards the oldest queued tick to make room.
+	DropOldest
+	// Block makes the read loop wait for the consumer. No tick is lost, but
+	// a slow consumer stalls every other delivery path and the socket.
+	Block
+)
+
+const defaultFeedBuffer = 4096
+
+type feedChannel struct {
+	mu      sync.RWMutex
+	ch      chan Tick
+	policy  BackpressurePolicy
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// Feeds returns a channel carrying every tick, buffered by
+// WebSocketConfig.FeedBuffer (4096 if unset) and handled per FeedPolicy when
+// the consumer falls behind. The channel is created on the first call and
+// closed by Stop, so it can be ranged over from the consumer's goroutine.
+func (wsm *WebSocketManager) Feeds() <-chan Tick {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	if wsm.feed == nil {
+		size := wsm.config.FeedBuffer
+		if size <= 0 {
+			size = defaultFeedBuffer
+		}
+		wsm.feed = &feedChannel{ch: make(chan Tick, size), policy: wsm.config.FeedPolicy}
+	}
+	return wsm.feed.ch
+}
+
+// DroppedTicks counts ticks the Feeds channel discarded under a drop policy.
+func (wsm *WebSocketManager) DroppedTicks() uint64 {
+	wsm.mu.RLock()
+	f := wsm.feed
+	wsm.mu.RUnlock()
+
+	if f == nil {
+		return 0
+	}
+	return f.dropped.Load()
+}
+
+func (f *feedChannel) send(ctx context.Context, tick Tick) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+
+	select {
+	case f.ch <- tick:
+		return
+	default:
+	}
+
+	switch f.policy {
+	case Block:
+		select {
+		case f.ch <- tick:
+		case <-ctx.Done():
+		}
+	case DropOldest:
+		select {
+		case <-f.ch:
+			f.dropped.Add(1)
+		default:
+		}
+		select {
+		case f.ch <- tick:
+		default:
+			f.dropped.Add(1)
+		}
+	default:
+		f.dropped.Add(1)
+	}
+}
+
+// close must only be called once ctx passed to send is done, so a blocked
+// sender has let go of the read lock.
+func (f *feedChannel) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.ch)
+	}
+}
@@ -0,0 +1,295 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// feedModeLimits are Upstox's per-connection instrument caps for each mode.
+var feedModeLimits = map[SubscriptionMode]int{
+	ModeLTPC:         5000,
+	ModeOptionGreeks: 3000,
+	ModeFull:         2000,
+	ModeFullD30:      50,
+}
+
+type FeedPoolConfig struct {
+	// Mode is the subscription mode of every instrument in the pool; empty
+	// means ltpc.
+	Mode SubscriptionMode
+	// MaxPerConnection caps instruments per connection; zero uses the
+	// exchange limit for Mode.
+	MaxPerConnection int
+	// MaxConnections caps the number of connections; zero means no cap.
+	// Upstox limits concurrent feed connections per user, so set this to
+	// the account's allowance to get an error instead of a refused socket.
+	MaxConnections int
+}
+
+// FeedPool spreads an instrument universe too large for one feed connection
+// over as many connections as needed. New instruments go to the connection
+// with the fewest instruments, and ticks from every connection are merged
+// into the pool's listeners.
+type FeedPool struct {
+	m   *Manager
+	cfg FeedPoolConfig
+
+	// opMu serialises Subscribe, Unsubscribe, Start and Stop. They dial and
+	// talk to connections outside mu, so ticks keep flowing meanwhile.
+	opMu sync.Mutex
+
+	mu        sync.RWMutex
+	shards    []*WebSocketManager
+	owner     map[string]*WebSocketManager
+	listeners []*tickListener
+	started   bool
+}
+
+func (m *Manager) NewFeedPool(ctx context.Context, instrumentKeys []string, cfg FeedPoolConfig) (*FeedPool, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeLTPC
+	}
+	if cfg.MaxPerConnection <= 0 {
+		limit, ok := feedModeLimits[cfg.Mode]
+		if !ok {
+			return nil, fmt.Errorf("unknown subscription mode %q", cfg.Mode)
+		}
+		cfg.MaxPerConnection = limit
+	}
+
+	p := &FeedPool{m: m, cfg: cfg, owner: make(map[string]*WebSocketManager)}
+	if err := p.Subscribe(ctx, instrumentKeys...); err != nil {
+		p.Stop()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Subscribe adds instruments to the least loaded connections, opening new
+// ones as existing connections fill up. Instruments already in the pool are
+// ignored. If a connection refuses its instruments they stay out of the
+// pool, and a later Subscribe can retry them.
+func (p *FeedPool) Subscribe(ctx context.Context, instrumentKeys ...string) error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.RLock()
+	shards := slices.Clone(p.shards)
+	started := p.started
+	var pending []string
+	for _, key := range instrumentKeys {
+		if _, ok := p.owner[key]; !ok && !slices.Contains(pending, key) {
+			pending = append(pending, key)
+		}
+	}
+	p.mu.RUnlock()
+
+	assigned := make(map[*WebSocketManager][]string)
+	var opened []*WebSocketManager
+	for _, key := range pending {
+		shard := p.leastLoaded(shards, assigned)
+		if shard == nil {
+			if p.cfg.MaxConnections > 0 && len(shards) >= p.cfg.MaxConnections {
+				stopAll(opened)
+				return fmt.Errorf("feed pool is full: %d connections of %d instruments", len(shards), p.cfg.MaxPerConnection)
+			}
+			var err error
+			if shard, err = p.openShard(ctx, started); err != nil {
+				stopAll(opened)
+				return err
+			}
+			shards = append(shards, shard)
+			opened = append(opened, shard)
+		}
+		assigned[shard] = append(assigned[shard], key)
+	}
+
+	var firstErr error
+	subscribed := make(map[*WebSocketManager][]string, len(assigned))
+	for shard, keys := range assigned {
+		if err := shard.Subscribe(keys...); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to subscribe on feed connection: %w", err)
+			}
+			continue
+		}
+		subscribed[shard] = keys
+	}
+
+	p.mu.Lock()
+	p.shards = append(p.shards, opened...)
+	for shard, keys := range subscribed {
+		for _, key := range keys {
+			p.owner[key] = shard
+		}
+	}
+	p.mu.Unlock()
+	return firstErr
+}
+
+// leastLoaded returns the connection with the most room, counting
+// instruments assigned in the current call, or nil if all are full.
+func (p *FeedPool) leastLoaded(shards []*WebSocketManager, assigned map[*WebSocketManager][]string) *WebSocketManager {
+	var best *WebSocketManager
+	bestLoad := p.cfg.MaxPerConnection
+	for _, shard := range shards {
+		if load := shardLoad(shard) + len(assigned[shard]); load < bestLoad {
+			best, bestLoad = shard, load
+		}
+	}
+	return best
+}
+
+func shardLoad(shard *WebSocketManager) int {
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return len(shard.config.InstrumentKeys)
+}
+
+// openShard opens a connection for the pool, starting it if the pool has
+// been started.
+func (p *FeedPool) openShard(ctx context.Context, start bool) (*WebSocketManager, error) {
+	shard, err := p.m.NewWebSocketManager(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	shard.config.Mode = p.cfg.Mode
+	shard.AddTickListener(p.dispatch)
+
+	if start {
+		if err := shard.Start(); err != nil {
+			shard.Stop()
+			return nil, fmt.Errorf("failed to start feed connection: %w", err)
+		}
+	}
+	return shard, nil
+}
+
+func stopAll(shards []*WebSocketManager) {
+	for _, shard := range shards {
+		shard.Stop()
+	}
+}
+
+// Unsubscribe removes instruments from whichever connections carry them.
+// Connections left empty stay open for later subscriptions.
+func (p *FeedPool) Unsubscribe(instrumentKeys ...string) error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.RLock()
+	removed := make(map[*WebSocketManager][]string)
+	for _, key := range instrumentKeys {
+		if shard, ok := p.owner[key]; ok && !slices.Contains(removed[shard], key) {
+			removed[shard] = append(removed[shard], key)
+		}
+	}
+	p.mu.RUnlock()
+
+	var firstErr error
+	for shard, keys := range removed {
+		if err := shard.Unsubscribe(keys...); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to unsubscribe on feed connection: %w", err)
+			}
+			delete(removed, shard)
+		}
+	}
+
+	p.mu.Lock()
+	for _, keys := range removed {
+		for _, key := range keys {
+			delete(p.owner, key)
+		}
+	}
+	p.mu.Unlock()
+	return firstErr
+}
+
+// AddTickListener registers fn for ticks from every connection in the pool.
+// Each connection has its own read goroutine, so fn may be called
+// concurrently and must be safe for that. Call the returned function to
+// remove fn.
+func (p *FeedPool) AddTickListener(fn TickCallback) (remove func()) {
+	l := &tickListener{fn: fn}
+
+	p.mu.Lock()
+	p.listeners = append(p.listeners[:len(p.listeners):len(p.listeners)], l)
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.listeners = slices.DeleteFunc(slices.Clone(p.listeners), func(existing *tickListener) bool { return existing == l })
+	}
+}
+
+func (p *FeedPool) dispatch(tick Tick) {
+	p.mu.RLock()
+	listeners := p.listeners
+	p.mu.RUnlock()
+
+	for _, l := range listeners {
+		l.fn(tick)
+	}
+}
+
+// Start connects every connection in the pool; connections opened later by
+// Subscribe start immediately.
+func (p *FeedPool) Start() error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	p.started = true
+	shards := slices.Clone(p.shards)
+	p.mu.Unlock()
+
+	for i, shard := range shards {
+		if err := shard.Start(); err != nil {
+			return fmt.Errorf("failed to start feed connection %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (p *FeedPool) Stop() {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	shards := p.shards
+	p.shards = nil
+	p.owner = make(map[string]*WebSocketManager)
+	p.started = false
+	p.mu.Unlock()
+
+	stopAll(shards)
+}
+
+// Shards returns the pool's connections, e.g. to register lifecycle
+// callbacks on each.
+func (p *FeedPool) Shards() []*WebSocketManager {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.shards)
+}
+
+// Shard returns the connection carrying instrumentKey.
+func (p *FeedPool) Shard(instrumentKey string) (*WebSocketManager, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	shard, ok := p.owner[instrumentKey]
+	return shard, ok
+}
+
+// LastPrice implements PriceSource across the whole pool.
+func (p *FeedPool) LastPrice(instrumentKey string) (float64, bool) {
+	shard, ok := p.Shard(instrumentKey)
+	if !ok {
+		return 0, false
+	}
+	return shard.LastPrice(instrumentKey)
+}
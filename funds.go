@@ -0,0 +1,188 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Reservation is margin set aside for an order that has been queued but may
+// not yet show up in the broker's available margin.
+type Reservation struct {
+	ID      string
+	Owner   string
+	Amount  float64
+	OrderID string // set once the order is placed
+	At      time.Time
+}
+
+// FundsLedger shares one account's available margin between strategies.
+// Each strategy reserves the estimated margin of an order before sending it;
+// reservations count against the funds last fetched from the API, so
+// concurrent strategies cannot collectively spend the same money between
+// refreshes.
+//
+// A reservation is released when its order is rejected, when the strategy
+// releases it, or by the first Refresh that starts after the order was
+// placed, at which point the broker's own figures include the order.
+type FundsLedger struct {
+	m       *Manager
+	segment string
+
+	mu           sync.Mutex
+	available    float64
+	refreshed    time.Time
+	reservations map[string]*Reservation
+	seq          int
+}
+
+// NewFundsLedger tracks the equity segment's available margin. Call Refresh
+// before reserving.
+func (m *Manager) NewFundsLedger() *FundsLedger {
+	return &FundsLedger{m: m, segment: "SEC", reservations: make(map[string]*Reservation)}
+}
+
+// Refresh reloads available margin and drops reservations for orders placed
+// before the request was made.
+func (l *FundsLedger) Refresh(ctx context.Context) error {
+	start := time.Now()
+	funds, err := l.m.GetFundsAndMargin(ctx, l.segment)
+	if err != nil {
+		return fmt.Errorf("failed to refresh funds: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.available = funds.Data.Equity.AvailableMargin
+	l.refreshed = start
+	for id, r := range l.reservations {
+		if r.OrderID != "" && r.At.Before(start) {
+			delete(l.reservations, id)
+		}
+	}
+	return nil
+}
+
+// Run refreshes every interval until ctx is done.
+func (l *FundsLedger) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Refresh(ctx); err != nil {
+				l.m.logger.Error("funds ledger: refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Reserve estimates the margin for orders through the margin API and
+// reserves it for owner, failing with ErrInsufficientFunds if the account
+// cannot cover it alongside existing reservations.
+func (l *FundsLedger) Reserve(ctx context.Context, owner string, orders ...OrderRequest) (*Reservation, error) {
+	margin, err := l.m.GetMargin(ctx, orders...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate margin: %w", err)
+	}
+	return l.ReserveAmount(owner, margin.FinalMargin)
+}
+
+func (l *FundsLedger) ReserveAmount(owner string, amount float64) (*Reservation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.refreshed.IsZero() {
+		return nil, errors.New("funds ledger has not been refreshed")
+	}
+	if free := l.free(); amount > free {
+		return nil, fmt.Errorf("%w: need %.2f, %.2f free", ErrInsufficientFunds, amount, free)
+	}
+
+	l.seq++
+	r := &Reservation{ID: fmt.Sprintf("R%d", l.seq), Owner: owner, Amount: amount, At: time.Now()}
+	l.reservations[r.ID] = r
+	c := *r
+	return &c, nil
+}
+
+// Bind links a reservation to the order it paid for, so the next Refresh
+// releases it.
+func (l *FundsLedger) Bind(id, orderID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r, ok := l.reservations[id]; ok {
+		r.OrderID = orderID
+		r.At = time.Now()
+	}
+}
+
+func (l *FundsLedger) Release(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.reservations, id)
+}
+
+// ReleaseOwner drops every reservation held by owner, e.g. when a strategy
+// exits.
+func (l *FundsLedger) ReleaseOwner(owner string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, r := range l.reservations {
+		if r.Owner == owner {
+			delete(l.reservations, id)
+		}
+	}
+}
+
+// Place reserves margin for req, places it and binds the reservation to the
+// new order. The reservation is released if the order fails or is rejected.
+func (l *FundsLedger) Place(ctx context.Context, owner string, req OrderRequest) (*OrderResponse, error) {
+	r, err := l.Reserve(ctx, owner, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.m.placeOrder(ctx, req)
+	if err != nil || resp.Status != "success" || resp.Data == nil || len(resp.Data.OrderIDs) == 0 {
+		l.Release(r.ID)
+		return resp, err
+	}
+	l.Bind(r.ID, resp.Data.OrderIDs[0])
+	return resp, nil
+}
+
+// Free is the available margin less everything reserved.
+func (l *FundsLedger) Free() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.free()
+}
+
+func (l *FundsLedger) free() float64 {
+	free := l.available
+	for _, r := range l.reservations {
+		free -= r.Amount
+	}
+	return free
+}
+
+// Reservations lists outstanding reservations, for all owners if owner is
+// empty.
+func (l *FundsLedger) Reservations(owner string) []Reservation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []Reservation
+	for _, r := range l.reservations {
+		if owner == "" || r.Owner == owner {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
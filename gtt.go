@@ -0,0 +1,136 @@
+package upstox
+
+import "context"
+
+type GTTType string
+
+const (
+	GTTTypeSingle   GTTType = "SINGLE"
+	GTTTypeMultiple GTTType = "MULTIPLE"
+)
+
+type GTTStrategy string
+
+const (
+	GTTStrategyEntry    GTTStrategy = "ENTRY"
+	GTTStrategyTarget   GTTStrategy = "TARGET"
+	GTTStrategyStopLoss GTTStrategy = "STOPLOSS"
+)
+
+type GTTTriggerType string
+
+const (
+	GTTTriggerAbove     GTTTriggerType = "ABOVE"
+	GTTTriggerBelow     GTTTriggerType = "BELOW"
+	GTTTriggerImmediate GTTTriggerType = "IMMEDIATE"
+)
+
+type GTTRule struct {
+	Strategy        GTTStrategy    `json:"strategy"`
+	TriggerType     GTTTriggerType `json:"trigger_type"`
+	TriggerPrice    float64        `json:"trigger_price"`
+	TransactionType string         `json:"transaction_type,omitempty"`
+	Status          string         `json:"status,omitempty"`
+	OrderID         string         `json:"order_id,omitempty"`
+}
+
+type GTTOrderRequest struct {
+	Type            GTTType   `json:"type"`
+	Quantity        int       `json:"quantity"`
+	Product         string    `json:"product"`
+	Rules           []GTTRule `json:"rules"`
+	InstrumentToken string    `json:"instrument_token"`
+	TransactionType string    `json:"transaction_type"`
+}
+
+type GTTModifyRequest struct {
+	Type       GTTType   `json:"type"`
+	Quantity   int       `json:"quantity"`
+	Rules      []GTTRule `json:"rules"`
+	GTTOrderID string    `json:"gtt_order_id"`
+}
+
+type GTTOrder struct {
+	Type            GTTType   `json:"type"`
+	Exchange        string    `json:"exchange"`
+	Quantity        int       `json:"quantity"`
+	Product         string    `json:"product"`
+	Rules           []GTTRule `json:"rules"`
+	InstrumentToken string    `json:"instrument_token"`
+	TransactionType string    `json:"transaction_type"`
+	GTTOrderID      string    `json:"gtt_order_id"`
+	ExpiresAt       int64     `json:"expires_at"`
+	CreatedAt       int64     `json:"created_at"`
+}
+
+// Active reports whether any rule is still waiting to trigger.
+func (g *GTTOrder) Active() bool {
+	for _, r := range g.Rules {
+		switch r.Status {
+		case "CANCELLED", "TRIGGERED", "EXPIRED", "FAILED", "COMPLETED":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+type GTTOrderResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		GTTOrderIDs []string `json:"gtt_order_ids"`
+	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+type GTTOrdersResponse struct {
+	Status string       `json:"status"`
+	Data   []GTTOrder   `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *GTTOrderResponse) envelope() (string, []OrderError)  { return r.Status, r.Errors }
+func (r *GTTOrdersResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+func (m *Manager) PlaceGTTOrder(ctx context.Context, gttReq GTTOrderRequest) ([]string, error) {
+	if err := m.checkHalt(); err != nil {
+		return nil, err
+	}
+	return m.gttCall(ctx, "POST", "https://api.upstox.com/v3/order/gtt/place", gttReq)
+}
+
+func (m *Manager) ModifyGTTOrder(ctx context.Context, gttReq GTTModifyRequest) ([]string, error) {
+	return m.gttCall(ctx, "PUT", "https://api.upstox.com/v3/order/gtt/modify", gttReq)
+}
+
+func (m *Manager) CancelGTTOrder(ctx context.Context, gttOrderID string) ([]string, error) {
+	return m.gttCall(ctx, "DELETE", "https://api.upstox.com/v3/order/gtt/cancel", map[string]string{"gtt_order_id": gttOrderID})
+}
+
+func (m *Manager) gttCall(ctx context.Context, method, url string, payload any) ([]string, error) {
+	req, err := m.newRequest(ctx, method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var gttResp GTTOrderResponse
+	if err := m.do(req, &gttResp); err != nil {
+		return nil, err
+	}
+
+	return gttResp.Data.GTTOrderIDs, nil
+}
+
+func (m *Manager) GetGTTOrders(ctx context.Context) ([]GTTOrder, error) {
+	req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v3/order/gtt", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var gttResp GTTOrdersResponse
+	if err := m.do(req, &gttResp); err != nil {
+		return nil, err
+	}
+
+	return gttResp.Data, nil
+}
@@ -0,0 +1,303 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// GTTTrailConfig describes a server-side trailing stop for one open position.
+type GTTTrailConfig struct {
+	InstrumentKey string
+
+	// Trail is the distance kept between the best price seen and the stop
+	// trigger. TrailPct is used instead when Trail is zero.
+	Trail    float64
+	TrailPct float64
+
+	// TickSize rounds the trigger away from the market; MinStep is the
+	// smallest trigger move worth a modify call and defaults to TickSize.
+	TickSize float64
+	MinStep  float64
+}
+
+// GTTTrailer keeps one GTT stop order per tracked position and raises (or,
+// for shorts, lowers) its trigger as the feed price moves in the position's
+// favour. The stop lives on the exchange side, so it still protects the
+// position if the process dies; on restart, Track adopts the existing GTT
+// instead of placing a second one.
+type GTTTrailer struct {
+	m   *Manager
+	ctx context.Context
+
+	mu    sync.Mutex
+	stops map[string]*gttTrail
+}
+
+type gttTrail struct {
+	cfg      GTTTrailConfig
+	gttID    string
+	long     bool
+	quantity int
+	best     float64
+	trigger  float64
+	pending  float64
+	inflight bool
+}
+
+// NewGTTTrailer creates a GTTTrailer whose trigger updates are sent with ctx.
+func (m *Manager) NewGTTTrailer(ctx context.Context) *GTTTrailer {
+	return &GTTTrailer{m: m, ctx: ctx, stops: make(map[string]*gttTrail)}
+}
+
+// Track starts trailing the open position in cfg.InstrumentKey.
+func (t *GTTTrailer) Track(ctx context.Context, cfg GTTTrailConfig) error {
+	return t.TrackAll(ctx, []GTTTrailConfig{cfg})
+}
+
+// TrackAll starts trailing several positions, reading positions and existing
+// GTT orders once for the whole set.
+func (t *GTTTrailer) TrackAll(ctx context.Context, cfgs []GTTTrailConfig) error {
+	positions, err := t.m.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+	gtts, err := t.m.GetGTTOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get GTT orders: %w", err)
+	}
+
+	for _, cfg := range cfgs {
+		if err := t.track(ctx, cfg, positions, gtts); err != nil {
+			return fmt.Errorf("failed to track %s: %w", cfg.InstrumentKey, err)
+		}
+	}
+	return nil
+}
+
+func (t *GTTTrailer) track(ctx context.Context, cfg GTTTrailConfig, positions []Position, gtts []GTTOrder) error {
+	if cfg.Trail <= 0 && cfg.TrailPct <= 0 {
+		return fmt.Errorf("trail distance must be positive")
+	}
+
+	var pos *Position
+	for i := range positions {
+		if positions[i].InstrumentToken == cfg.InstrumentKey && positions[i].Quantity != 0 {
+			pos = &positions[i]
+			break
+		}
+	}
+	if pos == nil {
+		return fmt.Errorf("no open position")
+	}
+
+	s := &gttTrail{cfg: cfg, long: pos.Quantity > 0, quantity: pos.Quantity}
+	if !s.long {
+		s.quantity = -s.quantity
+	}
+	exitSide := string(OrderSideSell)
+	triggerType := GTTTriggerBelow
+	if !s.long {
+		exitSide = string(OrderSideBuy)
+		triggerType = GTTTriggerAbove
+	}
+
+	for i := range gtts {
+		g := &gtts[i]
+		if g.InstrumentToken != cfg.InstrumentKey || g.Type != GTTTypeSingle ||
+			g.TransactionType != exitSide || len(g.Rules) != 1 || !g.Active() {
+			continue
+		}
+		s.gttID = g.GTTOrderID
+		s.trigger = g.Rules[0].TriggerPrice
+		break
+	}
+
+	s.best = pos.LastPrice
+	if s.gttID != "" {
+		// Resume from whichever is further along: the adopted trigger or the
+		// current price, so a restart never loosens the stop.
+		if level := s.stopFor(s.best); (s.long && level < s.trigger) || (!s.long && level > s.trigger) {
+			s.best = s.bestFor(s.trigger)
+		}
+	} else {
+		if s.best <= 0 {
+			return fmt.Errorf("no last price for position")
+		}
+		s.trigger = s.stopFor(s.best)
+		ids, err := t.m.PlaceGTTOrder(ctx, GTTOrderRequest{
+			Type:            GTTTypeSingle,
+			Quantity:        s.quantity,
+			Product:         pos.Product,
+			InstrumentToken: cfg.InstrumentKey,
+			TransactionType: exitSide,
+			Rules: []GTTRule{{
+				Strategy:     GTTStrategyEntry,
+				TriggerType:  triggerType,
+				TriggerPrice: s.trigger,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("no GTT order ID returned")
+		}
+		s.gttID = ids[0]
+	}
+
+	t.mu.Lock()
+	t.stops[cfg.InstrumentKey] = s
+	t.mu.Unlock()
+	return nil
+}
+
+// Attach feeds ticks from wsm into the trailer. Call the returned function
+// to detach.
+func (t *GTTTrailer) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(func(tick Tick) {
+		t.OnPrice(tick.InstrumentKey, tick.LTP)
+	})
+}
+
+// OnPrice advances the trail for instrumentKey. It never blocks on the
+// network: modify calls run on their own goroutine, and levels that arrive
+// while one is in flight are coalesced into the next call.
+func (t *GTTTrailer) OnPrice(instrumentKey string, price float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stops[instrumentKey]
+	if !ok || price <= 0 {
+		return
+	}
+	if (s.long && price <= s.best) || (!s.long && price >= s.best) {
+		return
+	}
+	s.best = price
+
+	current := s.trigger
+	if s.pending != 0 {
+		current = s.pending
+	}
+	level := s.stopFor(price)
+	step := s.cfg.MinStep
+	if step <= 0 {
+		step = s.cfg.TickSize
+	}
+	if (s.long && level-current < step) || (!s.long && current-level < step) || level == current {
+		return
+	}
+
+	s.pending = level
+	if !s.inflight {
+		s.inflight = true
+		go t.push(instrumentKey, s)
+	}
+}
+
+func (t *GTTTrailer) push(instrumentKey string, s *gttTrail) {
+	for {
+		t.mu.Lock()
+		level := s.pending
+		s.pending = 0
+		if level == 0 {
+			s.inflight = false
+			t.mu.Unlock()
+			return
+		}
+		req := GTTModifyRequest{
+			Type:       GTTTypeSingle,
+			Quantity:   s.quantity,
+			GTTOrderID: s.gttID,
+			Rules: []GTTRule{{
+				Strategy:     GTTStrategyEntry,
+				TriggerType:  s.triggerType(),
+				TriggerPrice: level,
+			}},
+		}
+		t.mu.Unlock()
+
+		_, err := t.m.ModifyGTTOrder(t.ctx, req)
+
+		t.mu.Lock()
+		if err != nil {
+			t.m.logger.Error("failed to trail GTT", "gtt_order_id", req.GTTOrderID, "instrument_key", instrumentKey, "trigger_price", level, "error", err)
+		} else {
+			s.trigger = level
+		}
+		t.mu.Unlock()
+	}
+}
+
+// Untrack stops trailing instrumentKey and cancels its GTT order.
+func (t *GTTTrailer) Untrack(ctx context.Context, instrumentKey string) error {
+	t.mu.Lock()
+	s, ok := t.stops[instrumentKey]
+	delete(t.stops, instrumentKey)
+	t.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	_, err := t.m.CancelGTTOrder(ctx, s.gttID)
+	return err
+}
+
+// Trigger returns the last trigger price acknowledged by the server.
+func (t *GTTTrailer) Trigger(instrumentKey string) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stops[instrumentKey]
+	if !ok {
+		return 0, false
+	}
+	return s.trigger, true
+}
+
+func (s *gttTrail) triggerType() GTTTriggerType {
+	if s.long {
+		return GTTTriggerBelow
+	}
+	return GTTTriggerAbove
+}
+
+func (s *gttTrail) distance(price float64) float64 {
+	if s.cfg.Trail > 0 {
+		return s.cfg.Trail
+	}
+	return price * s.cfg.TrailPct / 100
+}
+
+func (s *gttTrail) stopFor(price float64) float64 {
+	level := price + s.distance(price)
+	if s.long {
+		level = price - s.distance(price)
+	}
+
+	tick := s.cfg.TickSize
+	if tick <= 0 {
+		return level
+	}
+	// Round away from the market so the stop is never tighter than asked.
+	if s.long {
+		return math.Floor(level/tick+1e-9) * tick
+	}
+	return math.Ceil(level/tick-1e-9) * tick
+}
+
+// bestFor inverts stopFor (ignoring rounding) to recover the price that an
+// adopted trigger corresponds to.
+func (s *gttTrail) bestFor(trigger float64) float64 {
+	if s.cfg.Trail > 0 {
+		if s.long {
+			return trigger + s.cfg.Trail
+		}
+		return trigger - s.cfg.Trail
+	}
+	if s.long {
+		return trigger / (1 - s.cfg.TrailPct/100)
+	}
+	return trigger / (1 + s.cfg.TrailPct/100)
+}
@@ -0,0 +1,81 @@
+package upstox
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultPingInterval = 10 * time.Second
+	defaultStaleTimeout = 30 * time.Second
+)
+
+func (c WebSocketConfig) pingInterval() time.Duration {
+	if c.PingInterval == 0 {
+		return defaultPingInterval
+	}
+	return c.PingInterval
+}
+
+func (c WebSocketConfig) staleTimeout() time.Duration {
+	if c.StaleTimeout == 0 {
+		return defaultStaleTimeout
+	}
+	return c.StaleTimeout
+}
+
+// startHeartbeat pings conn every PingInterval and arms a read deadline that
+// every frame and pong pushes back by StaleTimeout. A half-open connection
+// then fails the pending read within StaleTimeout, which ends the read loop
+// and reconnects, instead of waiting on the socket forever.
+func (wsm *WebSocketManager) startHeartbeat(conn *websocket.Conn) (stop func()) {
+	wsm.extendReadDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		wsm.extendReadDeadline(conn)
+		return nil
+	})
+
+	interval := wsm.config.pingInterval()
+	if interval < 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		labelGoroutine("feed_ping")
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// WriteControl may run concurrently with the subscription writer.
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+					wsm.log().Warn("websocket ping failed", "error", err)
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (wsm *WebSocketManager) extendReadDeadline(conn *websocket.Conn) {
+	if timeout := wsm.config.staleTimeout(); timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+}
+
+// dropConn closes conn and forgets it, unless a newer connection has
+// already replaced it.
+func (wsm *WebSocketManager) dropConn(conn *websocket.Conn) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	if wsm.ws == conn {
+		wsm.ws = nil
+	}
+	conn.Close()
+}
@@ -0,0 +1,135 @@
+package upstox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const historicalDateLayout = "2006-01-02"
+
+type Candle struct {
+	Timestamp    time.Time
+	Open         float64
+	High         float64
+	Low          float64
+	Close        float64
+	Volume       int64
+	OpenInterest int64
+}
+
+// UnmarshalJSON decodes the API's positional candle array:
+// [timestamp, open, high, low, close, volume, open_interest].
+func (c *Candle) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 6 {
+		return fmt.Errorf("candle has %d fields, want at least 6", len(raw))
+	}
+
+	var ts string
+	if err := json.Unmarshal(raw[0], &ts); err != nil {
+		return fmt.Errorf("invalid candle timestamp: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return fmt.Errorf("invalid candle timestamp: %w", err)
+	}
+	c.Timestamp = t
+
+	var volume, oi float64
+	fields := []any{&c.Open, &c.High, &c.Low, &c.Close, &volume}
+	for i, f := range fields {
+		if err := json.Unmarshal(raw[i+1], f); err != nil {
+			return fmt.Errorf("invalid candle field %d: %w", i+1, err)
+		}
+	}
+	if len(raw) > 6 {
+		if err := json.Unmarshal(raw[6], &oi); err != nil {
+			return fmt.Errorf("invalid candle open interest: %w", err)
+		}
+	}
+	c.Volume = int64(volume)
+	c.OpenInterest = int64(oi)
+	return nil
+}
+
+type CandleResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Candles []Candle `json:"candles"`
+	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *CandleResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetHistoricalCandles returns candles for [from, to] at the given interval,
+// newest first as the API returns them. The v2 endpoint only serves 1minute,
+// 30minute, day, week and month candles.
+func (m *Manager) GetHistoricalCandles(ctx context.Context, instrumentKey string, interval Interval, from, to time.Time) ([]Candle, error) {
+	base, v, err := m.endpointURL(ctx, EndpointHistoricalCandle)
+	if err != nil {
+		return nil, err
+	}
+	path, err := candlePath(v, interval, SourceHistorical)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s/%s/%s", base, url.PathEscape(instrumentKey), path,
+		to.Format(historicalDateLayout), from.Format(historicalDateLayout))
+	return m.getCandles(ctx, endpoint)
+}
+
+// GetIntradayCandles returns the current session's candles for an instrument,
+// at any minute, hour or daily interval (1minute or 30minute on v2).
+func (m *Manager) GetIntradayCandles(ctx context.Context, instrumentKey string, interval Interval) ([]Candle, error) {
+	base, v, err := m.endpointURL(ctx, EndpointIntradayCandle)
+	if err != nil {
+		return nil, err
+	}
+	path, err := candlePath(v, interval, SourceIntraday)
+	if err != nil {
+		return nil, err
+	}
+	return m.getCandles(ctx, fmt.Sprintf("%s/%s/%s", base, url.PathEscape(instrumentKey), path))
+}
+
+// candlePath renders interval as the version's path segment: "minutes/5" on
+// v3, the interval name on v2.
+func candlePath(v APIVersion, interval Interval, src CandleSource) (string, error) {
+	if !interval.SupportedBy(src) {
+		return "", fmt.Errorf("interval %q is not supported for these candles", interval)
+	}
+	if v == V2 {
+		switch interval {
+		case I1, I30:
+			return string(interval), nil
+		case D1, W1, MN1:
+			if src == SourceHistorical {
+				return string(interval), nil
+			}
+		}
+		return "", fmt.Errorf("interval %q is not available on the v2 candle API", interval)
+	}
+	unit, n, _ := interval.unit()
+	return fmt.Sprintf("%s/%d", unit, n), nil
+}
+
+func (m *Manager) getCandles(ctx context.Context, endpoint string) ([]Candle, error) {
+	req, err := m.newRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var candleResp CandleResponse
+	if err := m.do(req, &candleResp); err != nil {
+		return nil, err
+	}
+
+	return candleResp.Data.Candles, nil
+}
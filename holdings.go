@@ -0,0 +1,111 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+)
+
+// Holding is a delivery (CNC) position held in the demat account. T1Quantity
+// is the quantity bought but not yet settled by the exchange, so Quantity
+// plus T1Quantity is what can be sold today.
+type Holding struct {
+	ISIN                string  `json:"isin"`
+	CNCUsedQuantity     int     `json:"cnc_used_quantity"`
+	CompanyName         string  `json:"company_name"`
+	Product             string  `json:"product"`
+	Quantity            int     `json:"quantity"`
+	TradingSymbol       string  `json:"trading_symbol"`
+	LastPrice           float64 `json:"last_price"`
+	ClosePrice          float64 `json:"close_price"`
+	PNL                 float64 `json:"pnl"`
+	DayChange           float64 `json:"day_change"`
+	DayChangePercentage float64 `json:"day_change_percentage"`
+	InstrumentToken     string  `json:"instrument_token"`
+	AveragePrice        float64 `json:"average_price"`
+	T1Quantity          int     `json:"t1_quantity"`
+	Exchange            string  `json:"exchange"`
+
+	// CollateralQuantity is the quantity pledged as margin collateral and
+	// CollateralUpdateQuantity the pledge/unpledge change pending today.
+	// Haircut is the fraction of the pledged value the broker withholds.
+	CollateralQuantity       int     `json:"collateral_quantity"`
+	CollateralUpdateQuantity int     `json:"collateral_update_quantity"`
+	CollateralType           string  `json:"collateral_type"`
+	Haircut                  float64 `json:"haircut"`
+
+	// PledgedQuantity is the quantity that will be pledged once today's
+	// pending changes settle. The API does not report it; GetHoldings fills
+	// it in from the collateral fields.
+	PledgedQuantity int `json:"-"`
+}
+
+type HoldingsResponse struct {
+	Status string       `json:"status"`
+	Data   []Holding    `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *HoldingsResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetHoldings returns long-term holdings; intraday and not yet delivered
+// positions are reported by GetPositions instead.
+func (m *Manager) GetHoldings(ctx context.Context) ([]Holding, error) {
+	url := "https://api.upstox.com/v2/portfolio/long-term-holdings"
+
+	req, err := m.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var holdingsResp HoldingsResponse
+	if err := m.do(req, &holdingsResp); err != nil {
+		return nil, err
+	}
+
+	for i := range holdingsResp.Data {
+		h := &holdingsResp.Data[i]
+		h.PledgedQuantity = max(h.CollateralQuantity+h.CollateralUpdateQuantity, 0)
+	}
+	return holdingsResp.Data, nil
+}
+
+// CollateralValue is the margin the currently pledged quantity is worth at
+// the last price after the haircut. Haircuts outside 0-1 are clamped rather
+// than guessed at, so a bad value never inflates the result.
+func (h *Holding) CollateralValue() float64 {
+	haircut := min(max(h.Haircut, 0), 1)
+	return float64(h.CollateralQuantity) * h.LastPrice * (1 - haircut)
+}
+
+// CollateralMargin is the equity margin available from cash and from pledged
+// holdings.
+type CollateralMargin struct {
+	AvailableMargin float64
+	CollateralValue float64
+	UsableMargin    float64
+	Holdings        []Holding
+}
+
+// GetCollateralMargin combines GetFundsAndMargin with the post-haircut value
+// of pledged holdings. Collateral already counted by the broker against used
+// margin is not netted out, so treat UsableMargin as an upper bound.
+func (m *Manager) GetCollateralMargin(ctx context.Context) (*CollateralMargin, error) {
+	funds, err := m.GetFundsAndMargin(ctx, "SEC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funds: %w", err)
+	}
+	holdings, err := m.GetHoldings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings: %w", err)
+	}
+
+	cm := &CollateralMargin{AvailableMargin: funds.Data.Equity.AvailableMargin}
+	for _, h := range holdings {
+		if h.CollateralQuantity > 0 {
+			cm.CollateralValue += h.CollateralValue()
+			cm.Holdings = append(cm.Holdings, h)
+		}
+	}
+	cm.UsableMargin = cm.AvailableMargin + cm.CollateralValue
+	return cm, nil
+}
@@ -0,0 +1,55 @@
+package upstox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetHoldings(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":[
+			{"isin":"INE002A01018","trading_symbol":"RELIANCE","instrument_token":"NSE_EQ|INE002A01018",
+			 "quantity":10,"t1_quantity":2,"average_price":2400,"last_price":2500,"pnl":1000,
+			 "collateral_quantity":6,"collateral_update_quantity":-2,"haircut":0.2}
+		]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+
+	holdings, err := m.GetHoldings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holdings) != 1 {
+		t.Fatalf("holdings = %+v", holdings)
+	}
+	h := holdings[0]
+	if h.ISIN != "INE002A01018" || h.Quantity != 10 || h.T1Quantity != 2 || h.AveragePrice != 2400 || h.PNL != 1000 {
+		t.Errorf("holding = %+v", h)
+	}
+	if h.PledgedQuantity != 4 {
+		t.Errorf("PledgedQuantity = %d, want 4", h.PledgedQuantity)
+	}
+	if req := stub.requests[0]; req.URL.Path != "/v2/portfolio/long-term-holdings" {
+		t.Errorf("requested %s", req.URL)
+	}
+}
+
+func TestCollateralValue(t *testing.T) {
+	tests := []struct {
+		haircut float64
+		want    float64
+	}{
+		{0.2, 800},
+		{0, 1000},
+		{1, 0},
+		{20, 0},
+		{-0.5, 1000},
+	}
+	for _, tt := range tests {
+		h := Holding{CollateralQuantity: 10, LastPrice: 100, Haircut: tt.haircut}
+		if got := h.CollateralValue(); got != tt.want {
+			t.Errorf("CollateralValue with haircut %v = %v, want %v", tt.haircut, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package upstox
+
+type OrderPlacedCallback func(req OrderRequest, resp *OrderResponse)
+type OrderFilledCallback func(order *Order)
+type OrderRejectedCallback func(req OrderRequest, reason RejectionReason, message string)
+
+type orderHooks struct {
+	placed   []OrderPlacedCallback
+	filled   []OrderFilledCallback
+	rejected []OrderRejectedCallback
+}
+
+// OnOrderPlaced registers fn to run after the API accepts any order placed
+// through the Manager. Callbacks run synchronously on the placing goroutine.
+func (m *Manager) OnOrderPlaced(fn OrderPlacedCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks.placed = append(m.hooks.placed, fn)
+}
+
+// OnOrderFilled registers fn for orders the Manager itself sees complete:
+// paper orders as they fill, and live orders found filled by WithConfirmation
+// or ConfirmOrder. Live orders that fill later are not seen; watch them with
+// an OrderTracker and use its OnEvent for those.
+func (m *Manager) OnOrderFilled(fn OrderFilledCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks.filled = append(m.hooks.filled, fn)
+}
+
+// OnOrderRejected registers fn for orders refused either by the API itself or
+// later by RMS/exchange, with the status message classified into a reason.
+// Later refusals are only seen under WithConfirmation or through
+// ConfirmOrder, as for OnOrderFilled.
+func (m *Manager) OnOrderRejected(fn OrderRejectedCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks.rejected = append(m.hooks.rejected, fn)
+}
+
+func (m *Manager) firePlaced(req OrderRequest, resp *OrderResponse) {
+	m.mu.RLock()
+	hooks := m.hooks.placed
+	m.mu.RUnlock()
+
+	for _, fn := range hooks {
+		fn(req, resp)
+	}
+}
+
+func (m *Manager) fireFilled(order *Order) {
+	m.mu.RLock()
+	hooks := m.hooks.filled
+	m.mu.RUnlock()
+
+	for _, fn := range hooks {
+		fn(order)
+	}
+}
+
+func (m *Manager) fireRejected(req OrderRequest, message string) {
+	m.mu.RLock()
+	hooks := m.hooks.rejected
+	m.mu.RUnlock()
+
+	reason := ClassifyRejection(message)
+	for _, fn := range hooks {
+		fn(req, reason, message)
+	}
+}
@@ -0,0 +1,68 @@
+package upstox
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// The Manager uses two clients: one for market data, portfolio and account
+// calls, and one tuned for low-latency order placement (see
+// PrewarmOrderPath). The options below apply to both.
+
+// WithHTTPClient makes the Manager send every REST call through c. Later
+// options modify a copy, never c itself.
+func WithHTTPClient(c *http.Client) ManagerOption {
+	return func(m *Manager) {
+		m.httpClient = c
+		m.orderClient = c
+	}
+}
+
+// WithTimeout bounds each REST call, including reading the response body.
+func WithTimeout(d time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.httpClient = withClient(m.httpClient, func(c *http.Client) { c.Timeout = d })
+		m.orderClient = withClient(m.orderClient, func(c *http.Client) { c.Timeout = d })
+	}
+}
+
+// WithTransport replaces the transport of both clients, e.g. to supply
+// custom TLS settings, connection pool limits or instrumentation.
+func WithTransport(rt http.RoundTripper) ManagerOption {
+	return func(m *Manager) {
+		m.httpClient = withClient(m.httpClient, func(c *http.Client) { c.Transport = rt })
+		m.orderClient = withClient(m.orderClient, func(c *http.Client) { c.Transport = rt })
+	}
+}
+
+// WithProxy routes REST calls through proxy instead of the one from the
+// environment. It only applies to *http.Transport transports, so pass it
+// after WithTransport, or configure the proxy on a custom transport itself.
+func WithProxy(proxy *url.URL) ManagerOption {
+	return func(m *Manager) {
+		setProxy := func(c *http.Client) {
+			var t *http.Transport
+			switch rt := c.Transport.(type) {
+			case nil:
+				t = http.DefaultTransport.(*http.Transport).Clone()
+			case *http.Transport:
+				t = rt.Clone()
+			default:
+				return
+			}
+			t.Proxy = http.ProxyURL(proxy)
+			c.Transport = t
+		}
+		m.httpClient = withClient(m.httpClient, setProxy)
+		m.orderClient = withClient(m.orderClient, setProxy)
+	}
+}
+
+// withClient returns a modified copy of c, leaving c untouched for anyone
+// else holding it.
+func withClient(c *http.Client, modify func(*http.Client)) *http.Client {
+	clone := *c
+	modify(&clone)
+	return &clone
+}
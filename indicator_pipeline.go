@@ -0,0 +1,273 @@
+package upstox
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adeludedperson/go-upstox/indicators"
+)
+
+// CandleIndicator is an incremental indicator fed one completed candle at a
+// time. Update returns the new value and whether it is warmed up.
+type CandleIndicator interface {
+	Update(c Candle) (value float64, ready bool)
+}
+
+// IndicatorFactory creates a fresh indicator for each instrument/timeframe.
+type IndicatorFactory func() CandleIndicator
+
+type candleIndicatorFunc func(c Candle) (float64, bool)
+
+func (f candleIndicatorFunc) Update(c Candle) (float64, bool) { return f(c) }
+
+// ClosePrice passes the candle close through, so price can take part in
+// crossovers.
+func ClosePrice() IndicatorFactory {
+	return func() CandleIndicator {
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return c.Close, true })
+	}
+}
+
+func SMA(period int) IndicatorFactory {
+	return func() CandleIndicator {
+		s := indicators.NewSMA(period)
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return s.Update(c.Close), s.Ready() })
+	}
+}
+
+func EMA(period int) IndicatorFactory {
+	return func() CandleIndicator {
+		e := indicators.NewEMA(period)
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return e.Update(c.Close), e.Ready() })
+	}
+}
+
+func RSI(period int) IndicatorFactory {
+	return func() CandleIndicator {
+		r := indicators.NewRSI(period)
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return r.Update(c.Close), r.Ready() })
+	}
+}
+
+func ATR(period int) IndicatorFactory {
+	return func() CandleIndicator {
+		a := indicators.NewATR(period)
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return a.Update(c.High, c.Low, c.Close), a.Ready() })
+	}
+}
+
+func Supertrend(period int, multiplier float64) IndicatorFactory {
+	return func() CandleIndicator {
+		s := indicators.NewSupertrend(period, multiplier)
+		return candleIndicatorFunc(func(c Candle) (float64, bool) { return s.Update(c.High, c.Low, c.Close), s.Ready() })
+	}
+}
+
+// VWAP weights each candle's typical price by its volume and restarts every
+// IST trading day.
+func VWAP() IndicatorFactory {
+	return func() CandleIndicator {
+		v := indicators.NewVWAP()
+		var day time.Time
+		return candleIndicatorFunc(func(c Candle) (float64, bool) {
+			t := c.Timestamp.In(IST)
+			d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, IST)
+			if !d.Equal(day) {
+				v.Reset()
+				day = d
+			}
+			return v.Update((c.High+c.Low+c.Close)/3, float64(c.Volume)), v.Ready()
+		})
+	}
+}
+
+type CrossDirection int
+
+const (
+	CrossAbove CrossDirection = iota
+	CrossBelow
+)
+
+func (d CrossDirection) String() string {
+	if d == CrossAbove {
+		return "above"
+	}
+	return "below"
+}
+
+// CrossCallback receives the candle on which the first operand crossed the
+// second.
+type CrossCallback func(instrumentKey string, interval Interval, dir CrossDirection, candle Candle)
+
+// IndicatorPipeline keeps named indicators per instrument and timeframe,
+// updated from completed candles. Indicators start on the first candle seen
+// for each instrument/timeframe, so warm up by feeding historical candles
+// through OnCandle before going live.
+type IndicatorPipeline struct {
+	mu        sync.Mutex
+	names     []string
+	factories map[string]IndicatorFactory
+	series    map[barKey]*indicatorSeries
+	crosses   []*crossWatch
+}
+
+type indicatorSeries struct {
+	indicators map[string]CandleIndicator
+	values     map[string]float64
+}
+
+type crossWatch struct {
+	a, b  string
+	level float64
+	fn    CrossCallback
+	prev  map[barKey]float64
+}
+
+func NewIndicatorPipeline() *IndicatorPipeline {
+	return &IndicatorPipeline{
+		factories: make(map[string]IndicatorFactory),
+		series:    make(map[barKey]*indicatorSeries),
+	}
+}
+
+// Add registers an indicator under name, e.g. p.Add("ema20", EMA(20)). A
+// factory that cannot build its indicator, such as SMA(0), is rejected.
+func (p *IndicatorPipeline) Add(name string, factory IndicatorFactory) error {
+	if err := checkFactory(name, factory == nil, func() { factory() }); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.factories[name]; ok {
+		return fmt.Errorf("indicator %q already registered", name)
+	}
+	p.names = append(p.names, name)
+	p.factories[name] = factory
+	return nil
+}
+
+// checkFactory builds one indicator with build so that a bad factory fails
+// in Add rather than panicking later on the feed goroutine.
+func checkFactory(name string, isNil bool, build func()) (err error) {
+	if isNil {
+		return fmt.Errorf("indicator %q has no factory", name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid indicator %q: %v", name, r)
+		}
+	}()
+	build()
+	return nil
+}
+
+// OnCross calls fn whenever indicator a crosses indicator b. Both must be
+// warmed up on the previous and current candle.
+func (p *IndicatorPipeline) OnCross(a, b string, fn CrossCallback) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.crosses = append(p.crosses, &crossWatch{a: a, b: b, fn: fn, prev: make(map[barKey]float64)})
+}
+
+// OnCrossLevel calls fn whenever indicator name crosses a fixed level, such
+// as RSI crossing 70.
+func (p *IndicatorPipeline) OnCrossLevel(name string, level float64, fn CrossCallback) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.crosses = append(p.crosses, &crossWatch{a: name, level: level, fn: fn, prev: make(map[barKey]float64)})
+}
+
+// Attach feeds every candle closed by agg into the pipeline.
+func (p *IndicatorPipeline) Attach(agg *CandleAggregator) {
+	agg.OnCandleClose(p.OnCandle)
+}
+
+// OnCandle updates every indicator for the candle's instrument and interval.
+// It has the CandleCloseCallback shape, so it can also be registered on a
+// BrickAggregator or behind HeikinAshi.
+func (p *IndicatorPipeline) OnCandle(instrumentKey string, interval Interval, c Candle) {
+	k := barKey{instrumentKey, interval}
+
+	p.mu.Lock()
+	s := p.series[k]
+	if s == nil {
+		s = &indicatorSeries{indicators: make(map[string]CandleIndicator), values: make(map[string]float64)}
+		p.series[k] = s
+	}
+	for _, name := range p.names {
+		ind, ok := s.indicators[name]
+		if !ok {
+			ind = p.factories[name]()
+			s.indicators[name] = ind
+		}
+		if v, ready := ind.Update(c); ready {
+			s.values[name] = v
+		} else {
+			delete(s.values, name)
+		}
+	}
+
+	var fire []func()
+	for _, w := range p.crosses {
+		diff, ok := w.diff(s.values)
+		if !ok {
+			delete(w.prev, k)
+			continue
+		}
+		prev, had := w.prev[k]
+		w.prev[k] = diff
+		if !had {
+			continue
+		}
+		fn := w.fn
+		switch {
+		case prev <= 0 && diff > 0:
+			fire = append(fire, func() { fn(instrumentKey, interval, CrossAbove, c) })
+		case prev >= 0 && diff < 0:
+			fire = append(fire, func() { fn(instrumentKey, interval, CrossBelow, c) })
+		}
+	}
+	p.mu.Unlock()
+
+	for _, f := range fire {
+		f()
+	}
+}
+
+func (w *crossWatch) diff(values map[string]float64) (float64, bool) {
+	a, ok := values[w.a]
+	if !ok {
+		return 0, false
+	}
+	if w.b == "" {
+		return a - w.level, true
+	}
+	b, ok := values[w.b]
+	return a - b, ok
+}
+
+// Value returns the latest warmed-up value of an indicator.
+func (p *IndicatorPipeline) Value(instrumentKey string, interval Interval, name string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.series[barKey{instrumentKey, interval}]
+	if !ok {
+		return 0, false
+	}
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Values returns every warmed-up indicator for an instrument and interval.
+func (p *IndicatorPipeline) Values(instrumentKey string, interval Interval) map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string]float64)
+	if s, ok := p.series[barKey{instrumentKey, interval}]; ok {
+		for name, v := range s.values {
+			out[name] = v
+		}
+	}
+	return out
+}
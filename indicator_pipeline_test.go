@@ -0,0 +1,25 @@
+package upstox
+
+import "testing"
+
+func TestIndicatorAddRejectsBadPeriod(t *testing.T) {
+	p := NewIndicatorPipeline()
+	if err := p.Add("sma", SMA(0)); err == nil {
+		t.Error("pipeline accepted SMA(0)")
+	}
+	if err := p.Add("sma", SMA(3)); err != nil {
+		t.Errorf("SMA(3) after a rejected SMA(0): %v", err)
+	}
+
+	ti := NewTickIndicators()
+	if err := ti.Add("ema", TickEMA(-1)); err == nil {
+		t.Error("tick indicators accepted TickEMA(-1)")
+	}
+	if err := ti.Add("ema", nil); err == nil {
+		t.Error("tick indicators accepted a nil factory")
+	}
+	ti.OnTick(Tick{InstrumentKey: "NSE_EQ|X", LTP: 100})
+	if _, ok := ti.Value("NSE_EQ|X", "ema"); ok {
+		t.Error("rejected indicator has a value")
+	}
+}
@@ -0,0 +1,238 @@
+// Package indicators implements streaming technical indicators. Every
+// indicator is updated one value at a time in O(1) and knows nothing about
+// instruments or candles, so the same code serves candle pipelines, raw tick
+// streams and backtests.
+//
+// Indicators are not safe for concurrent use; keep one per instrument and
+// timeframe.
+package indicators
+
+import "math"
+
+func checkPeriod(period int) {
+	if period < 1 {
+		panic("indicators: period must be at least 1")
+	}
+}
+
+// SMA is a simple moving average over the last period values.
+type SMA struct {
+	period int
+	buf    []float64
+	next   int
+	sum    float64
+}
+
+func NewSMA(period int) *SMA {
+	checkPeriod(period)
+	return &SMA{period: period, buf: make([]float64, 0, period)}
+}
+
+func (s *SMA) Update(v float64) float64 {
+	if len(s.buf) < s.period {
+		s.buf = append(s.buf, v)
+	} else {
+		s.sum -= s.buf[s.next]
+		s.buf[s.next] = v
+		s.next = (s.next + 1) % s.period
+	}
+	s.sum += v
+	return s.Value()
+}
+
+func (s *SMA) Value() float64 {
+	if len(s.buf) == 0 {
+		return 0
+	}
+	return s.sum / float64(len(s.buf))
+}
+
+// Ready reports whether a full period has been seen.
+func (s *SMA) Ready() bool { return len(s.buf) == s.period }
+
+// EMA is an exponential moving average seeded with the SMA of its first
+// period values.
+type EMA struct {
+	period int
+	k      float64
+	n      int
+	value  float64
+}
+
+func NewEMA(period int) *EMA {
+	checkPeriod(period)
+	return &EMA{period: period, k: 2 / float64(period+1)}
+}
+
+func (e *EMA) Update(v float64) float64 {
+	e.n++
+	if e.n <= e.period {
+		e.value += (v - e.value) / float64(e.n)
+	} else {
+		e.value += e.k * (v - e.value)
+	}
+	return e.value
+}
+
+func (e *EMA) Value() float64 { return e.value }
+func (e *EMA) Ready() bool    { return e.n >= e.period }
+
+// wilder is Wilder's smoothing: a plain average for the first period values,
+// then value = (value*(period-1) + v) / period.
+type wilder struct {
+	period int
+	n      int
+	value  float64
+}
+
+func (w *wilder) update(v float64) float64 {
+	w.n++
+	if w.n <= w.period {
+		w.value += (v - w.value) / float64(w.n)
+	} else {
+		w.value = (w.value*float64(w.period-1) + v) / float64(w.period)
+	}
+	return w.value
+}
+
+func (w *wilder) ready() bool { return w.n >= w.period }
+
+// RSI is Wilder's relative strength index on closing prices.
+type RSI struct {
+	gain, loss wilder
+	prev       float64
+	seen       bool
+}
+
+func NewRSI(period int) *RSI {
+	checkPeriod(period)
+	return &RSI{gain: wilder{period: period}, loss: wilder{period: period}}
+}
+
+func (r *RSI) Update(close float64) float64 {
+	if !r.seen {
+		r.prev, r.seen = close, true
+		return r.Value()
+	}
+	change := close - r.prev
+	r.prev = close
+	r.gain.update(math.Max(change, 0))
+	r.loss.update(math.Max(-change, 0))
+	return r.Value()
+}
+
+func (r *RSI) Value() float64 {
+	if r.gain.n == 0 {
+		return 0
+	}
+	if r.loss.value == 0 {
+		if r.gain.value == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+r.gain.value/r.loss.value)
+}
+
+// Ready reports whether period price changes, i.e. period+1 closes, have
+// been seen.
+func (r *RSI) Ready() bool { return r.gain.ready() }
+
+// ATR is Wilder's average true range.
+type ATR struct {
+	tr        wilder
+	prevClose float64
+	seen      bool
+}
+
+func NewATR(period int) *ATR {
+	checkPeriod(period)
+	return &ATR{tr: wilder{period: period}}
+}
+
+func (a *ATR) Update(high, low, close float64) float64 {
+	tr := high - low
+	if a.seen {
+		tr = math.Max(tr, math.Max(math.Abs(high-a.prevClose), math.Abs(low-a.prevClose)))
+	}
+	a.prevClose, a.seen = close, true
+	return a.tr.update(tr)
+}
+
+func (a *ATR) Value() float64 { return a.tr.value }
+func (a *ATR) Ready() bool    { return a.tr.ready() }
+
+// VWAP is the volume weighted average price since the last Reset. Callers
+// decide the anchor, typically resetting at each session open.
+type VWAP struct {
+	pv, volume float64
+}
+
+func NewVWAP() *VWAP { return &VWAP{} }
+
+func (v *VWAP) Update(price, volume float64) float64 {
+	v.pv += price * volume
+	v.volume += volume
+	return v.Value()
+}
+
+func (v *VWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+	return v.pv / v.volume
+}
+
+func (v *VWAP) Ready() bool { return v.volume > 0 }
+func (v *VWAP) Reset()      { v.pv, v.volume = 0, 0 }
+
+// Supertrend follows price with an ATR band: below price in an uptrend,
+// above it in a downtrend, flipping when the close crosses the band.
+type Supertrend struct {
+	atr        *ATR
+	multiplier float64
+
+	upper, lower float64
+	prevClose    float64
+	up           bool
+	seen         bool
+}
+
+func NewSupertrend(period int, multiplier float64) *Supertrend {
+	return &Supertrend{atr: NewATR(period), multiplier: multiplier, up: true}
+}
+
+func (s *Supertrend) Update(high, low, close float64) float64 {
+	atr := s.atr.Update(high, low, close)
+	mid := (high + low) / 2
+	upper, lower := mid+s.multiplier*atr, mid-s.multiplier*atr
+
+	if s.seen {
+		// The bands only tighten, unless the previous close broke through.
+		if upper > s.upper && s.prevClose <= s.upper {
+			upper = s.upper
+		}
+		if lower < s.lower && s.prevClose >= s.lower {
+			lower = s.lower
+		}
+		switch {
+		case s.up && close < lower:
+			s.up = false
+		case !s.up && close > upper:
+			s.up = true
+		}
+	}
+	s.upper, s.lower, s.prevClose, s.seen = upper, lower, close, true
+	return s.Value()
+}
+
+func (s *Supertrend) Value() float64 {
+	if s.up {
+		return s.lower
+	}
+	return s.upper
+}
+
+// Uptrend reports the current direction.
+func (s *Supertrend) Uptrend() bool { return s.up }
+func (s *Supertrend) Ready() bool   { return s.atr.Ready() }
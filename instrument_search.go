@@ -0,0 +1,131 @@
+package upstox
+
+import (
+	"sort"
+	"strings"
+)
+
+// Exchanges as reported in Instrument.Exchange.
+const (
+	ExchangeNSE = "NSE"
+	ExchangeBSE = "BSE"
+	ExchangeMCX = "MCX"
+)
+
+// FindBySymbol returns the instrument with the given trading symbol on
+// exchange, or on any exchange when exchange is empty, e.g.
+// FindBySymbol("SBIN", ExchangeNSE). Ties between segments are broken as in
+// ResolveSymbols.
+func (idx *InstrumentIndex) FindBySymbol(symbol, exchange string) (Instrument, bool) {
+	var matches []Instrument
+	for _, inst := range idx.BySymbol(symbol) {
+		if exchange == "" || inst.Exchange == exchange {
+			matches = append(matches, inst)
+		}
+	}
+	if len(matches) == 0 {
+		return Instrument{}, false
+	}
+	for _, segment := range symbolSegmentPreference {
+		for _, inst := range matches {
+			if inst.Segment == segment {
+				return inst, true
+			}
+		}
+	}
+	return matches[0], true
+}
+
+// FindByISIN returns the instruments with the given ISIN, one per exchange
+// the security is listed on, restricted to exchange unless it is empty.
+func (idx *InstrumentIndex) FindByISIN(isin, exchange string) []Instrument {
+	var out []Instrument
+	for i, v := range idx.isins {
+		if v == "" || !strings.EqualFold(v, isin) {
+			continue
+		}
+		if exchange != "" && idx.dict[idx.exchanges[i]] != exchange {
+			continue
+		}
+		out = append(out, idx.At(i))
+	}
+	return out
+}
+
+// Search returns up to limit instruments matching query, best first:
+// exact trading symbols, then symbol prefixes, then name prefixes, then
+// substrings of symbol or name, then symbols containing the query's
+// characters in order (so "bnkfty" finds "BANKNIFTY"). Matching ignores case
+// and spaces. A limit of zero or less returns every match.
+func (idx *InstrumentIndex) Search(query string, limit int) []Instrument {
+	q := searchFold(query)
+	if q == "" {
+		return nil
+	}
+
+	type hit struct {
+		row   int
+		score int
+	}
+	var hits []hit
+	for i := range idx.keys {
+		if score, ok := searchScore(q, idx.tradingSymbols[i], idx.names[i], idx.shortNames[i]); ok {
+			hits = append(hits, hit{i, score})
+		}
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		ha, hb := hits[a], hits[b]
+		if ha.score != hb.score {
+			return ha.score < hb.score
+		}
+		sa, sb := idx.tradingSymbols[ha.row], idx.tradingSymbols[hb.row]
+		if len(sa) != len(sb) {
+			return len(sa) < len(sb)
+		}
+		return sa < sb
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	out := make([]Instrument, len(hits))
+	for i, h := range hits {
+		out[i] = idx.At(h.row)
+	}
+	return out
+}
+
+func searchScore(q, symbol, name, shortName string) (int, bool) {
+	sym := searchFold(symbol)
+	names := [2]string{searchFold(name), searchFold(shortName)}
+	switch {
+	case sym == q:
+		return 0, true
+	case strings.HasPrefix(sym, q):
+		return 1, true
+	case strings.HasPrefix(names[0], q) || strings.HasPrefix(names[1], q):
+		return 2, true
+	case strings.Contains(sym, q):
+		return 3, true
+	case strings.Contains(names[0], q) || strings.Contains(names[1], q):
+		return 4, true
+	case subsequence(q, sym):
+		return 5, true
+	}
+	return 0, false
+}
+
+func searchFold(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+// subsequence reports whether every byte of q appears in s in order.
+func subsequence(q, s string) bool {
+	for i := 0; i < len(s) && q != ""; i++ {
+		if s[i] == q[0] {
+			q = q[1:]
+		}
+	}
+	return q == ""
+}
@@ -0,0 +1,355 @@
+package upstox
+
+import (
+	"bytes"
+	"compress/flate"
+	"container/list"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	instrumentStoreMagic     = "UPXSTORE1"
+	instrumentStoreBlockRows = 256
+)
+
+// InstrumentLookup is satisfied by both the in-memory InstrumentIndex and the
+// on-disk InstrumentStore, so helpers that need instrument metadata can work
+// with either.
+type InstrumentLookup interface {
+	ByKey(instrumentKey string) (Instrument, bool)
+	BySymbol(symbol string) []Instrument
+}
+
+// InstrumentStore serves instrument lookups from a compressed file, decoding
+// only the blocks a lookup touches and keeping a small LRU of decoded blocks.
+// Only the block directory (one entry per 256 rows) is held in memory.
+//
+// File layout: magic, flate-compressed blocks, JSON footer, 8-byte footer
+// length. Key blocks hold instruments sorted by key; symbol blocks hold
+// [symbol, key] pairs sorted by case-folded symbol.
+//
+// Lookups are safe for concurrent use. A read or decode failure makes the
+// lookup report "not found"; the first such failure is available from Err.
+type InstrumentStore struct {
+	file   *os.File
+	footer storeFooter
+
+	mu       sync.Mutex
+	cache    map[storeCacheKey]*list.Element
+	lru      *list.List
+	maxCache int
+	err      error
+}
+
+type storeBlock struct {
+	First  string `json:"first"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+}
+
+type storeFooter struct {
+	Rows    int          `json:"rows"`
+	Keys    []storeBlock `json:"keys"`
+	Symbols []storeBlock `json:"symbols"`
+}
+
+type storeCacheKey struct {
+	symbols bool
+	block   int
+}
+
+type storeCacheEntry struct {
+	key         storeCacheKey
+	instruments []Instrument
+	symbols     [][2]string
+}
+
+var (
+	_ InstrumentLookup = (*InstrumentIndex)(nil)
+	_ InstrumentLookup = (*InstrumentStore)(nil)
+)
+
+// SaveInstrumentStore writes idx to path in the InstrumentStore format.
+func SaveInstrumentStore(path string, idx *InstrumentIndex) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create instrument store: %w", err)
+	}
+
+	if err := writeInstrumentStore(f, idx); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeInstrumentStore(w io.Writer, idx *InstrumentIndex) error {
+	cw := &countingWriter{w: w}
+	if _, err := io.WriteString(cw, instrumentStoreMagic); err != nil {
+		return fmt.Errorf("failed to write instrument store: %w", err)
+	}
+
+	footer := storeFooter{Rows: idx.Len()}
+
+	for start := 0; start < idx.Len(); start += instrumentStoreBlockRows {
+		end := min(start+instrumentStoreBlockRows, idx.Len())
+		rows := make([]Instrument, 0, end-start)
+		for i := start; i < end; i++ {
+			rows = append(rows, idx.At(i))
+		}
+		block, err := writeStoreBlock(cw, rows)
+		if err != nil {
+			return err
+		}
+		block.First = rows[0].InstrumentKey
+		footer.Keys = append(footer.Keys, block)
+	}
+
+	for start := 0; start < len(idx.bySymbol); start += instrumentStoreBlockRows {
+		end := min(start+instrumentStoreBlockRows, len(idx.bySymbol))
+		pairs := make([][2]string, 0, end-start)
+		for _, row := range idx.bySymbol[start:end] {
+			pairs = append(pairs, [2]string{idx.tradingSymbols[row], idx.keys[row]})
+		}
+		block, err := writeStoreBlock(cw, pairs)
+		if err != nil {
+			return err
+		}
+		block.First = pairs[0][0]
+		footer.Symbols = append(footer.Symbols, block)
+	}
+
+	footerBytes, err := json.Marshal(footer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instrument store footer: %w", err)
+	}
+	if _, err := cw.Write(footerBytes); err != nil {
+		return fmt.Errorf("failed to write instrument store: %w", err)
+	}
+	if err := binary.Write(cw, binary.BigEndian, uint64(len(footerBytes))); err != nil {
+		return fmt.Errorf("failed to write instrument store: %w", err)
+	}
+	return nil
+}
+
+func writeStoreBlock(cw *countingWriter, v any) (storeBlock, error) {
+	var buf bytes.Buffer
+	zw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return storeBlock{}, err
+	}
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return storeBlock{}, fmt.Errorf("failed to encode instrument store block: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return storeBlock{}, err
+	}
+
+	block := storeBlock{Offset: cw.n, Length: int64(buf.Len())}
+	if _, err := cw.Write(buf.Bytes()); err != nil {
+		return storeBlock{}, fmt.Errorf("failed to write instrument store: %w", err)
+	}
+	return block, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// OpenInstrumentStore opens a store written by SaveInstrumentStore, keeping
+// at most cacheBlocks decoded blocks in memory (a block is 256 rows).
+func OpenInstrumentStore(path string, cacheBlocks int) (*InstrumentStore, error) {
+	if cacheBlocks <= 0 {
+		cacheBlocks = 8
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open instrument store: %w", err)
+	}
+
+	footer, err := readStoreFooter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &InstrumentStore{
+		file:     f,
+		footer:   footer,
+		cache:    make(map[storeCacheKey]*list.Element),
+		lru:      list.New(),
+		maxCache: cacheBlocks,
+	}, nil
+}
+
+func readStoreFooter(f *os.File) (storeFooter, error) {
+	var footer storeFooter
+
+	info, err := f.Stat()
+	if err != nil {
+		return footer, fmt.Errorf("failed to stat instrument store: %w", err)
+	}
+	size := info.Size()
+	if size < int64(len(instrumentStoreMagic))+8 {
+		return footer, fmt.Errorf("instrument store is truncated")
+	}
+
+	magic := make([]byte, len(instrumentStoreMagic))
+	if _, err := f.ReadAt(magic, 0); err != nil || string(magic) != instrumentStoreMagic {
+		return footer, fmt.Errorf("not an instrument store file")
+	}
+
+	var lenBuf [8]byte
+	if _, err := f.ReadAt(lenBuf[:], size-8); err != nil {
+		return footer, fmt.Errorf("failed to read instrument store footer: %w", err)
+	}
+	footerLen := int64(binary.BigEndian.Uint64(lenBuf[:]))
+	if footerLen <= 0 || footerLen > size-8 {
+		return footer, fmt.Errorf("instrument store footer is corrupt")
+	}
+
+	footerBytes := make([]byte, footerLen)
+	if _, err := f.ReadAt(footerBytes, size-8-footerLen); err != nil {
+		return footer, fmt.Errorf("failed to read instrument store footer: %w", err)
+	}
+	if err := json.Unmarshal(footerBytes, &footer); err != nil {
+		return footer, fmt.Errorf("instrument store footer is corrupt: %w", err)
+	}
+	return footer, nil
+}
+
+func (s *InstrumentStore) Len() int {
+	return s.footer.Rows
+}
+
+func (s *InstrumentStore) Close() error {
+	return s.file.Close()
+}
+
+// Err returns the first read or decode error encountered by a lookup.
+func (s *InstrumentStore) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *InstrumentStore) ByKey(instrumentKey string) (Instrument, bool) {
+	blocks := s.footer.Keys
+	b := sort.Search(len(blocks), func(i int) bool { return blocks[i].First > instrumentKey }) - 1
+	if b < 0 {
+		return Instrument{}, false
+	}
+
+	entry := s.block(storeCacheKey{block: b})
+	if entry == nil {
+		return Instrument{}, false
+	}
+
+	rows := entry.instruments
+	i := sort.Search(len(rows), func(i int) bool { return rows[i].InstrumentKey >= instrumentKey })
+	if i < len(rows) && rows[i].InstrumentKey == instrumentKey {
+		return rows[i], true
+	}
+	return Instrument{}, false
+}
+
+// BySymbol returns every instrument whose trading symbol matches symbol,
+// case-insensitively. Matches may span adjacent symbol blocks.
+func (s *InstrumentStore) BySymbol(symbol string) []Instrument {
+	blocks := s.footer.Symbols
+	// Start from the last block whose first symbol sorts strictly before
+	// symbol, since matches may begin at the tail of that block.
+	b := sort.Search(len(blocks), func(i int) bool { return compareFold(blocks[i].First, symbol) >= 0 }) - 1
+	if b < 0 {
+		b = 0
+	}
+
+	var out []Instrument
+	for ; b < len(blocks); b++ {
+		if compareFold(blocks[b].First, symbol) > 0 {
+			break
+		}
+		entry := s.block(storeCacheKey{symbols: true, block: b})
+		if entry == nil {
+			break
+		}
+		for _, pair := range entry.symbols {
+			if compareFold(pair[0], symbol) == 0 {
+				if inst, ok := s.ByKey(pair[1]); ok {
+					out = append(out, inst)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (s *InstrumentStore) block(key storeCacheKey) *storeCacheEntry {
+	s.mu.Lock()
+	if el, ok := s.cache[key]; ok {
+		s.lru.MoveToFront(el)
+		s.mu.Unlock()
+		return el.Value.(*storeCacheEntry)
+	}
+	s.mu.Unlock()
+
+	entry, err := s.readBlock(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return nil
+	}
+	if el, ok := s.cache[key]; ok {
+		// Another goroutine decoded the same block concurrently.
+		s.lru.MoveToFront(el)
+		return el.Value.(*storeCacheEntry)
+	}
+	s.cache[key] = s.lru.PushFront(entry)
+	for s.lru.Len() > s.maxCache {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.cache, oldest.Value.(*storeCacheEntry).key)
+	}
+	return entry
+}
+
+func (s *InstrumentStore) readBlock(key storeCacheKey) (*storeCacheEntry, error) {
+	blocks := s.footer.Keys
+	if key.symbols {
+		blocks = s.footer.Symbols
+	}
+	meta := blocks[key.block]
+
+	zr := flate.NewReader(io.NewSectionReader(s.file, meta.Offset, meta.Length))
+	defer zr.Close()
+
+	entry := &storeCacheEntry{key: key}
+	var err error
+	if key.symbols {
+		err = json.NewDecoder(zr).Decode(&entry.symbols)
+	} else {
+		err = json.NewDecoder(zr).Decode(&entry.instruments)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode instrument store block %d: %w", key.block, err)
+	}
+	return entry, nil
+}
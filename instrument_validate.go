@@ -0,0 +1,165 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ValidationError is one way an order breaks its instrument's trading rules.
+// Field is the OrderRequest JSON field at fault. It matches ErrInvalidInput
+// with errors.Is.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// OrderValidationError lists every rule an order breaks. errors.As finds
+// the individual ValidationErrors through it.
+type OrderValidationError struct {
+	InstrumentKey string
+	Errors        []*ValidationError
+}
+
+func (e *OrderValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, ve := range e.Errors {
+		msgs[i] = ve.Error()
+	}
+	return fmt.Sprintf("invalid order for %s: %s", e.InstrumentKey, strings.Join(msgs, "; "))
+}
+
+func (e *OrderValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, ve := range e.Errors {
+		errs[i] = ve
+	}
+	return errs
+}
+
+// WithInstrumentLookup gives the Manager instrument metadata. Every order is
+// then checked against its instrument's lot size, tick size and freeze
+// quantity before it is sent, as in ValidateOrder but without the price band.
+func WithInstrumentLookup(lookup InstrumentLookup) ManagerOption {
+	return func(m *Manager) {
+		m.instruments = lookup
+	}
+}
+
+// ValidateOrder checks orderReq the way the exchange would, without placing
+// it: the local sanity checks every order gets, then, against the instrument
+// master given through WithInstrumentLookup, lot size multiples, tick-size
+// aligned prices and the freeze quantity (unless Slice is set), and finally
+// the day's circuit band. Rule violations are returned together as an
+// *OrderValidationError.
+func (m *Manager) ValidateOrder(ctx context.Context, orderReq OrderRequest) error {
+	if err := validateOrderRequest(orderReq); err != nil {
+		return fmt.Errorf("invalid order: %w", err)
+	}
+	if m.instruments == nil {
+		return errors.New("no instrument lookup configured; use WithInstrumentLookup")
+	}
+
+	inst, ok := m.instruments.ByKey(orderReq.InstrumentToken)
+	if !ok {
+		return &OrderValidationError{
+			InstrumentKey: orderReq.InstrumentToken,
+			Errors:        []*ValidationError{{Field: "instrument_token", Reason: "unknown instrument"}},
+		}
+	}
+	errs := instrumentViolations(orderReq, inst)
+
+	if orderReq.Price != 0 || orderReq.TriggerPrice != 0 {
+		band, err := m.GetCircuitBand(ctx, orderReq.InstrumentToken)
+		if err != nil {
+			return err
+		}
+		// Instruments without circuit limits, such as most derivatives, report zeros.
+		if band.Upper > 0 {
+			for _, p := range []struct {
+				field string
+				value float64
+			}{{"price", orderReq.Price}, {"trigger_price", orderReq.TriggerPrice}} {
+				if p.value != 0 && (p.value < band.Lower || p.value > band.Upper) {
+					errs = append(errs, &ValidationError{
+						Field:  p.field,
+						Reason: fmt.Sprintf("%.2f is outside the circuit band %.2f-%.2f", p.value, band.Lower, band.Upper),
+					})
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &OrderValidationError{InstrumentKey: orderReq.InstrumentToken, Errors: errs}
+	}
+	return nil
+}
+
+// checkInstrument applies the instrument master checks to every order when
+// a lookup is configured. Unknown instruments are left to the API.
+func (m *Manager) checkInstrument(orderReq OrderRequest) error {
+	if m.instruments == nil {
+		return nil
+	}
+	inst, ok := m.instruments.ByKey(orderReq.InstrumentToken)
+	if !ok {
+		return nil
+	}
+	if errs := instrumentViolations(orderReq, inst); len(errs) > 0 {
+		return &OrderValidationError{InstrumentKey: orderReq.InstrumentToken, Errors: errs}
+	}
+	return nil
+}
+
+func instrumentViolations(orderReq OrderRequest, inst Instrument) []*ValidationError {
+	var errs []*ValidationError
+
+	if inst.LotSize > 1 && orderReq.Quantity%inst.LotSize != 0 {
+		errs = append(errs, &ValidationError{
+			Field:  "quantity",
+			Reason: fmt.Sprintf("%d is not a multiple of the lot size %d", orderReq.Quantity, inst.LotSize),
+		})
+	}
+	if freeze := int(inst.FreezeQuantity); freeze > 0 && orderReq.Quantity >= freeze && !orderReq.Slice {
+		errs = append(errs, &ValidationError{
+			Field:  "quantity",
+			Reason: fmt.Sprintf("%d reaches the freeze quantity %d; set Slice or use PlaceSliced", orderReq.Quantity, freeze),
+		})
+	}
+
+	tick := instrumentTick(inst)
+	for _, p := range []struct {
+		field string
+		value float64
+	}{{"price", orderReq.Price}, {"trigger_price", orderReq.TriggerPrice}} {
+		if p.value != 0 && tick > 0 && !onTick(p.value, tick) {
+			errs = append(errs, &ValidationError{
+				Field:  p.field,
+				Reason: fmt.Sprintf("%g is not a multiple of the tick size %g", p.value, tick),
+			})
+		}
+	}
+	return errs
+}
+
+// instrumentTick converts the instrument master's tick size, quoted in
+// paise, to rupees.
+func instrumentTick(inst Instrument) float64 {
+	return inst.TickSize / 100
+}
+
+func onTick(price, tick float64) bool {
+	n := price / tick
+	return math.Abs(n-math.Round(n)) < 1e-6
+}
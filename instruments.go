@@ -0,0 +1,112 @@
+package upstox
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const instrumentMasterBaseURL = "https://assets.upstox.com/market-quote/instruments/exchange"
+
+type Instrument struct {
+	Segment          string  `json:"segment"`
+	Name             string  `json:"name"`
+	Exchange         string  `json:"exchange"`
+	ISIN             string  `json:"isin"`
+	InstrumentType   string  `json:"instrument_type"`
+	InstrumentKey    string  `json:"instrument_key"`
+	LotSize          int     `json:"lot_size"`
+	FreezeQuantity   float64 `json:"freeze_quantity"`
+	ExchangeToken    string  `json:"exchange_token"`
+	TickSize         float64 `json:"tick_size"`
+	TradingSymbol    string  `json:"trading_symbol"`
+	ShortName        string  `json:"short_name"`
+	Expiry           int64   `json:"expiry"`
+	StrikePrice      float64 `json:"strike_price"`
+	UnderlyingKey    string  `json:"underlying_key"`
+	UnderlyingSymbol string  `json:"underlying_symbol"`
+	UnderlyingType   string  `json:"underlying_type"`
+	AssetSymbol      string  `json:"asset_symbol"`
+	Weekly           bool    `json:"weekly"`
+	MinimumLot       int     `json:"minimum_lot"`
+}
+
+// StreamInstruments decodes a JSON array of instruments one record at a time,
+// calling fn for each. Returning an error from fn stops decoding and is
+// returned as-is, so callers can bail out early once they have what they need.
+func StreamInstruments(r io.Reader, fn func(Instrument) error) error {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read instrument list: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected instrument list token: %v", tok)
+	}
+
+	for dec.More() {
+		var inst Instrument
+		if err := dec.Decode(&inst); err != nil {
+			return fmt.Errorf("failed to decode instrument: %w", err)
+		}
+		if err := fn(inst); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to read end of instrument list: %w", err)
+	}
+
+	return nil
+}
+
+// StreamInstrumentMaster downloads the gzipped instrument master for the given
+// exchange ("NSE", "BSE", "MCX") or the complete master when exchange is empty,
+// and streams it through fn without holding the whole file in memory.
+func (m *Manager) StreamInstrumentMaster(ctx context.Context, exchange string, fn func(Instrument) error) error {
+	name := "complete"
+	if exchange != "" {
+		name = exchange
+	}
+	url := fmt.Sprintf("%s/%s.json.gz", instrumentMasterBaseURL, name)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("instrument master download failed: status %d", resp.StatusCode)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	return StreamInstruments(gz, fn)
+}
+
+func (m *Manager) GetInstruments(ctx context.Context, exchange string) ([]Instrument, error) {
+	var instruments []Instrument
+	err := m.StreamInstrumentMaster(ctx, exchange, func(inst Instrument) error {
+		instruments = append(instruments, inst)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instruments, nil
+}
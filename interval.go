@@ -0,0 +1,149 @@
+package upstox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval is a candle interval shared by the historical and intraday candle
+// APIs and by local candle aggregation. Any "<n>minute" (1-300) or "<n>hour"
+// (1-5) value is accepted besides the named constants, and for aggregation
+// only, "<n>second" (1-3600).
+type Interval string
+
+const (
+	S1  Interval = "1second"
+	S5  Interval = "5second"
+	S15 Interval = "15second"
+	S30 Interval = "30second"
+	I1  Interval = "1minute"
+	I3  Interval = "3minute"
+	I5  Interval = "5minute"
+	I10 Interval = "10minute"
+	I15 Interval = "15minute"
+	I30 Interval = "30minute"
+	H1  Interval = "1hour"
+	D1  Interval = "day"
+	W1  Interval = "week"
+	MN1 Interval = "month"
+)
+
+// CandleSource names a consumer of intervals for SupportedBy.
+type CandleSource int
+
+const (
+	SourceHistorical CandleSource = iota
+	SourceIntraday
+	SourceAggregator
+)
+
+var intervalAliases = map[string]Interval{
+	"1s": S1, "5s": S5, "15s": S15, "30s": S30,
+	"1m": I1, "3m": I3, "5m": I5, "10m": I10, "15m": I15, "30m": I30,
+	"1h": H1, "1d": D1, "1w": W1, "1mo": MN1,
+}
+
+// ParseInterval accepts an Interval value or a short alias such as "1s", "5m",
+// "1h", "1d", "1w" or "1mo".
+func ParseInterval(s string) (Interval, error) {
+	if i, ok := intervalAliases[strings.ToLower(s)]; ok {
+		return i, nil
+	}
+	i := Interval(s)
+	if !i.Valid() {
+		return "", fmt.Errorf("invalid candle interval %q", s)
+	}
+	return i, nil
+}
+
+// unit splits the interval into the API's unit and multiplier.
+func (i Interval) unit() (unit string, n int, ok bool) {
+	switch i {
+	case D1:
+		return "days", 1, true
+	case W1:
+		return "weeks", 1, true
+	case MN1:
+		return "months", 1, true
+	}
+
+	s := string(i)
+	var max int
+	switch {
+	case strings.HasSuffix(s, "second"):
+		s, unit, max = strings.TrimSuffix(s, "second"), "seconds", 3600
+	case strings.HasSuffix(s, "minute"):
+		s, unit, max = strings.TrimSuffix(s, "minute"), "minutes", 300
+	case strings.HasSuffix(s, "hour"):
+		s, unit, max = strings.TrimSuffix(s, "hour"), "hours", 5
+	default:
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > max {
+		return "", 0, false
+	}
+	return unit, n, true
+}
+
+func (i Interval) Valid() bool {
+	_, _, ok := i.unit()
+	return ok
+}
+
+// Duration is the fixed length of a candle; ok is false for weekly and
+// monthly candles, which follow the calendar.
+func (i Interval) Duration() (d time.Duration, ok bool) {
+	unit, n, ok := i.unit()
+	switch {
+	case !ok:
+		return 0, false
+	case unit == "seconds":
+		return time.Duration(n) * time.Second, true
+	case unit == "minutes":
+		return time.Duration(n) * time.Minute, true
+	case unit == "hours":
+		return time.Duration(n) * time.Hour, true
+	case unit == "days":
+		return 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// SupportedBy reports whether src can produce candles at this interval: the
+// historical API takes every valid interval but seconds, the intraday API
+// minutes to daily, and aggregation only fixed-length intervals.
+func (i Interval) SupportedBy(src CandleSource) bool {
+	unit, _, ok := i.unit()
+	if !ok {
+		return false
+	}
+	switch src {
+	case SourceHistorical:
+		return unit != "seconds"
+	case SourceIntraday:
+		return unit == "minutes" || unit == "hours" || unit == "days"
+	case SourceAggregator:
+		return unit == "seconds" || unit == "minutes" || unit == "hours" || unit == "days"
+	}
+	return false
+}
+
+// maxSpan is the widest date range the historical API serves in one call.
+func (i Interval) maxSpan() (time.Duration, bool) {
+	const day = 24 * time.Hour
+	unit, n, ok := i.unit()
+	switch {
+	case !ok:
+		return 0, false
+	case unit == "minutes" && n <= 15:
+		return 30 * day, true
+	case unit == "minutes" || unit == "hours":
+		return 90 * day, true
+	case unit == "days":
+		return 10 * 365 * day, true
+	}
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrIteratorDone is returned by Iterator.Next once every item has been read.
+var ErrIteratorDone = errors.New("no more items")
+
+// PageFetcher loads one page of a paginated endpoint. more reports whether a
+// further page may exist.
+type PageFetcher[T any] func(ctx context.Context, page int) (items []T, more bool, err error)
+
+type IteratorOptions struct {
+	// StartPage is the first page to fetch, defaulting to 1. Pass a value
+	// saved from Page to resume an earlier run.
+	StartPage int
+	// PageInterval is the minimum gap between page requests, to stay within
+	// the endpoint's rate limit.
+	PageInterval time.Duration
+}
+
+// Iterator walks a paginated endpoint one item at a time, fetching pages on
+// demand. A failed fetch is returned from Next and retried on the next call,
+// so a loop can back off and continue without losing its place.
+type Iterator[T any] struct {
+	fetch    PageFetcher[T]
+	interval time.Duration
+
+	page    int
+	buf     []T
+	done    bool
+	fetched time.Time
+}
+
+func NewIterator[T any](fetch PageFetcher[T], opts IteratorOptions) *Iterator[T] {
+	if opts.StartPage < 1 {
+		opts.StartPage = 1
+	}
+	return &Iterator[T]{fetch: fetch, interval: opts.PageInterval, page: opts.StartPage}
+}
+
+// Next returns the next item, ErrIteratorDone at the end, or the error from
+// fetching the next page.
+func (it *Iterator[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for len(it.buf) == 0 {
+		if it.done {
+			return zero, ErrIteratorDone
+		}
+		if wait := it.interval - time.Since(it.fetched); !it.fetched.IsZero() && wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return zero, ctx.Err()
+			}
+		}
+
+		items, more, err := it.fetch(ctx, it.page)
+		it.fetched = time.Now()
+		if err != nil {
+			return zero, err
+		}
+		it.buf, it.done = items, !more
+		it.page++
+	}
+
+	item := it.buf[0]
+	it.buf = it.buf[1:]
+	return item, nil
+}
+
+// Page is the next page the iterator will fetch. Items buffered from the
+// previous page are not covered by it, so save it for resumption after Next
+// fails, when the buffer is always empty.
+func (it *Iterator[T]) Page() int { return it.page }
+
+// All drains the iterator.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		item, err := it.Next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return all, nil
+		}
+		if err != nil {
+			return all, err
+		}
+		all = append(all, item)
+	}
+}
@@ -0,0 +1,243 @@
+package upstox
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type JSONLSinkConfig struct {
+	Dir    string
+	Prefix string // file name prefix, "feed" if empty
+	// PerInstrument writes one file per instrument per day instead of one
+	// combined file per day.
+	PerInstrument bool
+	// FlushInterval bounds how long records sit in memory; 0 means one second.
+	FlushInterval time.Duration
+	// Logger receives write failures from OnTick, OnCandle and the
+	// background flush; nil discards them.
+	Logger Logger
+}
+
+// feedRecord is one line of a JSONL feed file: a tick, or a candle when
+// Interval is set. Times are kept at millisecond precision in IST.
+type feedRecord struct {
+	Time          time.Time  `json:"time"`
+	ReceivedAt    *time.Time `json:"received_at,omitempty"`
+	InstrumentKey string     `json:"instrument_key"`
+
+	LTP float64 `json:"ltp,omitempty"`
+	LTQ int64   `json:"ltq,omitempty"`
+	LTT int64   `json:"ltt,omitempty"`
+	CP  float64 `json:"cp,omitempty"`
+
+	Interval     Interval `json:"interval,omitempty"`
+	Open         float64  `json:"open,omitempty"`
+	High         float64  `json:"high,omitempty"`
+	Low          float64  `json:"low,omitempty"`
+	Close        float64  `json:"close,omitempty"`
+	Volume       int64    `json:"volume,omitempty"`
+	OpenInterest int64    `json:"oi,omitempty"`
+}
+
+// JSONLSink writes ticks and candles as JSON lines, one object per record,
+// keeping every tick field so files can be replayed exactly. Files rotate at
+// IST midnight and are named like CSVSink's with a .jsonl extension; existing
+// files are appended to. Call Close on shutdown so nothing buffered is lost.
+type JSONLSink struct {
+	cfg JSONLSinkConfig
+
+	mu     sync.Mutex
+	files  map[string]*jsonlFile
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+type jsonlFile struct {
+	day string
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewJSONLSink(cfg JSONLSinkConfig) (*JSONLSink, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "feed"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create JSONL directory: %w", err)
+	}
+
+	s := &JSONLSink{
+		cfg:   cfg,
+		files: make(map[string]*jsonlFile),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+func (s *JSONLSink) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(s.OnTick)
+}
+
+// OnTick writes a tick, logging failures so it can be used as a listener.
+func (s *JSONLSink) OnTick(tick Tick) {
+	if err := s.WriteTick(tick); err != nil {
+		s.cfg.Logger.Error("jsonl sink: write failed", "error", err)
+	}
+}
+
+// OnCandle has the CandleCloseCallback shape, for use with
+// CandleAggregator.OnCandleClose.
+func (s *JSONLSink) OnCandle(instrumentKey string, interval Interval, c Candle) {
+	if err := s.WriteCandle(instrumentKey, interval, c); err != nil {
+		s.cfg.Logger.Error("jsonl sink: write failed", "error", err)
+	}
+}
+
+func (s *JSONLSink) WriteTick(tick Tick) error {
+	t := tick.ReceivedAt
+	if tick.LTT > 0 {
+		t = time.UnixMilli(tick.LTT)
+	}
+	rec := feedRecord{
+		Time:          t.In(IST).Truncate(time.Millisecond),
+		InstrumentKey: tick.InstrumentKey,
+		LTP:           tick.LTP,
+		LTQ:           tick.LTQ,
+		LTT:           tick.LTT,
+		CP:            tick.CP,
+	}
+	if !tick.ReceivedAt.IsZero() {
+		at := tick.ReceivedAt.In(IST).Truncate(time.Millisecond)
+		rec.ReceivedAt = &at
+	}
+	return s.write(tick.InstrumentKey, t, &rec)
+}
+
+func (s *JSONLSink) WriteCandle(instrumentKey string, interval Interval, c Candle) error {
+	return s.write(instrumentKey, c.Timestamp, &feedRecord{
+		Time:          c.Timestamp.In(IST),
+		InstrumentKey: instrumentKey,
+		Interval:      interval,
+		Open:          c.Open,
+		High:          c.High,
+		Low:           c.Low,
+		Close:         c.Close,
+		Volume:        c.Volume,
+		OpenInterest:  c.OpenInterest,
+	})
+}
+
+func (s *JSONLSink) write(instrumentKey string, t time.Time, rec *feedRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("JSONL sink is closed")
+	}
+
+	name := ""
+	if s.cfg.PerInstrument {
+		name = instrumentKey
+	}
+	day := t.In(IST).Format("2006-01-02")
+
+	jf := s.files[name]
+	if jf != nil && jf.day != day {
+		if err := jf.close(); err != nil {
+			s.cfg.Logger.Error("jsonl sink: failed to close file", "file", jf.f.Name(), "error", err)
+		}
+		jf = nil
+	}
+	if jf == nil {
+		path := sinkPath(s.cfg.Dir, s.cfg.Prefix, name, day, ".jsonl")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			delete(s.files, name)
+			return fmt.Errorf("failed to open JSONL file: %w", err)
+		}
+		buf := bufio.NewWriter(f)
+		jf = &jsonlFile{day: day, f: f, buf: buf, enc: json.NewEncoder(buf)}
+		s.files[name] = jf
+	}
+
+	if err := jf.enc.Encode(rec); err != nil {
+		return fmt.Errorf("failed to write %s: %w", jf.f.Name(), err)
+	}
+	return nil
+}
+
+func (jf *jsonlFile) close() error {
+	err := jf.buf.Flush()
+	if cerr := jf.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Flush pushes buffered records to disk.
+func (s *JSONLSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, jf := range s.files {
+		if err := jf.buf.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush %s: %w", jf.f.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *JSONLSink) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				s.cfg.Logger.Error("jsonl sink: flush failed", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close flushes and closes every file. Writes after Close fail.
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for name, jf := range s.files {
+		if err := jf.close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", jf.f.Name(), err))
+		}
+		delete(s.files, name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package upstox
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTradingHalted = errors.New("trading halted")
+
+type haltState struct {
+	reason string
+	since  time.Time
+}
+
+// Halt blocks every new order placement through the Manager, including GTT
+// orders, prepared orders and orders fired by helpers such as the scheduler
+// and multi-leg execution, until Resume. Cancels, modifications and exits
+// (ClosePosition, CloseAllPositions, expiry square-off and multi-leg
+// rollbacks) keep working so positions can still be flattened.
+func (m *Manager) Halt(reason string) {
+	m.halt.Store(&haltState{reason: reason, since: time.Now()})
+	m.logger.Warn("trading halted", "reason", reason)
+}
+
+func (m *Manager) Resume() {
+	if m.halt.Swap(nil) != nil {
+		m.logger.Info("trading resumed")
+	}
+}
+
+// Halted reports whether the kill switch is engaged, and why and since when.
+func (m *Manager) Halted() (reason string, since time.Time, halted bool) {
+	h := m.halt.Load()
+	if h == nil {
+		return "", time.Time{}, false
+	}
+	return h.reason, h.since, true
+}
+
+func (m *Manager) checkHalt() error {
+	if h := m.halt.Load(); h != nil {
+		return fmt.Errorf("%w: %s", ErrTradingHalted, h.reason)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package upstox
+
+import (
+	"context"
+	"math/bits"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram buckets are log-linear in the HDR style: values below 32ns get
+// one bucket each, and every power of two above that is split into 16 equal
+// sub-buckets, bounding the relative error of any reported quantile to ~6%
+// across the full int64 nanosecond range.
+const (
+	latencySubBuckets  = 16
+	latencyBucketCount = (64-5)*latencySubBuckets + 2*latencySubBuckets
+)
+
+// LatencyHistogram records durations with lock-free atomic counters, so it
+// can sit on the order and tick hot paths without adding contention.
+type LatencyHistogram struct {
+	counts [latencyBucketCount]atomic.Uint64
+	total  atomic.Uint64
+	sum    atomic.Uint64
+	max    atomic.Int64
+}
+
+type LatencySnapshot struct {
+	Count uint64
+	Mean  time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+}
+
+func latencyBucket(v uint64) int {
+	if v < 2*latencySubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - 5
+	return shift*latencySubBuckets + int(v>>shift)
+}
+
+// latencyBucketUpper returns the largest value that maps to bucket i.
+func latencyBucketUpper(i int) uint64 {
+	if i < 2*latencySubBuckets {
+		return uint64(i)
+	}
+	shift := i/latencySubBuckets - 1
+	top := uint64(i - shift*latencySubBuckets)
+	return (top+1)<<shift - 1
+}
+
+func (h *LatencyHistogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	v := uint64(d)
+	h.counts[latencyBucket(v)].Add(1)
+	h.total.Add(1)
+	h.sum.Add(v)
+	for {
+		cur := h.max.Load()
+		if int64(v) <= cur || h.max.CompareAndSwap(cur, int64(v)) {
+			return
+		}
+	}
+}
+
+func (h *LatencyHistogram) Since(start time.Time) {
+	h.Record(time.Since(start))
+}
+
+func (h *LatencyHistogram) Count() uint64 {
+	return h.total.Load()
+}
+
+// Quantile returns the upper bound of the bucket holding the q-th quantile
+// (0 < q <= 1). Concurrent Records may make it slightly stale.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.total.Load()
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(total))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i := range h.counts {
+		seen += h.counts[i].Load()
+		if seen >= rank {
+			upper := latencyBucketUpper(i)
+			if m := uint64(h.max.Load()); upper > m {
+				upper = m
+			}
+			return time.Duration(upper)
+		}
+	}
+	return time.Duration(h.max.Load())
+}
+
+func (h *LatencyHistogram) Snapshot() LatencySnapshot {
+	count := h.total.Load()
+	snap := LatencySnapshot{
+		Count: count,
+		Max:   time.Duration(h.max.Load()),
+		P50:   h.Quantile(0.50),
+		P90:   h.Quantile(0.90),
+		P99:   h.Quantile(0.99),
+		P999:  h.Quantile(0.999),
+	}
+	if count > 0 {
+		snap.Mean = time.Duration(h.sum.Load() / count)
+	}
+	return snap
+}
+
+func (h *LatencyHistogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.total.Store(0)
+	h.sum.Store(0)
+	h.max.Store(0)
+}
+
+// OrderLatency reports the round-trip time of order placement requests, from
+// just before the request is written until the response is decoded.
+func (m *Manager) OrderLatency() *LatencyHistogram {
+	return &m.orderLatency
+}
+
+// DecodeLatency reports the time spent unmarshalling each feed frame. In
+// OnFastPrice mode scanning and handler calls are fused, so the whole frame
+// is recorded here.
+func (wsm *WebSocketManager) DecodeLatency() *LatencyHistogram {
+	return &wsm.decodeLatency
+}
+
+// DispatchLatency reports the time spent handing one decoded frame's ticks to
+// callbacks, batchers, conflators and dispatchers.
+func (wsm *WebSocketManager) DispatchLatency() *LatencyHistogram {
+	return &wsm.dispatchLatency
+}
+
+// labelGoroutine tags the calling goroutine for CPU and goroutine profiles,
+// e.g. `go tool pprof -tagfocus upstox=feed_read`. Long-lived goroutines set
+// their label once, so the per-tick path pays nothing for it.
+func labelGoroutine(name string) {
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("upstox", name)))
+}
@@ -0,0 +1,87 @@
+package upstox
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFeedStopped is the disconnect reason when the connection was closed by
+// Stop rather than lost.
+var ErrFeedStopped = errors.New("feed stopped")
+
+type (
+	ConnectCallback    func()
+	DisconnectCallback func(reason error)
+	ReconnectCallback  func(attempt int, delay time.Duration)
+	FeedErrorCallback  func(err error)
+)
+
+type feedHooks struct {
+	connect    ConnectCallback
+	disconnect DisconnectCallback
+	reconnect  ReconnectCallback
+	err        FeedErrorCallback
+	giveUp     GiveUpCallback
+}
+
+// OnConnect registers fn to run each time the connection is established and
+// the initial subscription has been sent, including after a reconnect.
+func (wsm *WebSocketManager) OnConnect(fn ConnectCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.hooks.connect = fn
+}
+
+// OnDisconnect registers fn to run when the connection drops, with the read
+// error that ended it, or ErrFeedStopped after Stop. Ticks stop until
+// OnConnect fires again, so this is the place to pause trading.
+func (wsm *WebSocketManager) OnDisconnect(fn DisconnectCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.hooks.disconnect = fn
+}
+
+// OnReconnect registers fn to run when a reconnection attempt is scheduled.
+func (wsm *WebSocketManager) OnReconnect(fn ReconnectCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.hooks.reconnect = fn
+}
+
+// OnError registers fn for errors that do not end the connection by
+// themselves: failed reconnection attempts and frames that fail to decode.
+func (wsm *WebSocketManager) OnError(fn FeedErrorCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.hooks.err = fn
+}
+
+func (wsm *WebSocketManager) feedHooks() feedHooks {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	return wsm.hooks
+}
+
+func (wsm *WebSocketManager) fireConnect() {
+	if fn := wsm.feedHooks().connect; fn != nil {
+		fn()
+	}
+}
+
+func (wsm *WebSocketManager) fireDisconnect(reason error) {
+	if fn := wsm.feedHooks().disconnect; fn != nil {
+		fn(reason)
+	}
+}
+
+func (wsm *WebSocketManager) fireReconnect(attempt int, delay time.Duration) {
+	if fn := wsm.feedHooks().reconnect; fn != nil {
+		fn(attempt, delay)
+	}
+}
+
+func (wsm *WebSocketManager) fireError(err error) {
+	if fn := wsm.feedHooks().err; fn != nil {
+		fn(err)
+	}
+}
@@ -0,0 +1,122 @@
+package upstox
+
+import (
+	pb "github.com/adeludedperson/go-upstox/pb"
+)
+
+// OnLiveFeed delivers every live and initial feed frame in full, with depth,
+// OHLC, greeks, OI, ATP and traded volume as sent for each instrument's mode.
+// fn runs on the read goroutine alongside the tick callbacks, and the message
+// is a copy that fn may retain. Frames are not delivered while OnFastPrice is
+// set.
+func (wsm *WebSocketManager) OnLiveFeed(fn LiveFeedCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.onLiveFeed = fn
+}
+
+func liveFeedMessage(fr *pb.FeedResponse) LiveFeedMessage {
+	msg := LiveFeedMessage{
+		Type:      fr.Type.String(),
+		Feeds:     make(map[string]*FeedData, len(fr.Feeds)),
+		CurrentTS: fr.CurrentTs,
+	}
+	for key, feed := range fr.Feeds {
+		msg.Feeds[key] = feedData(feed)
+	}
+	return msg
+}
+
+func feedData(feed *pb.Feed) *FeedData {
+	fd := &FeedData{
+		LTPC:        ltpcData(feed.GetLtpc()),
+		RequestMode: requestMode(feed.GetRequestMode()),
+	}
+
+	if ff := feed.GetFullFeed(); ff != nil {
+		fd.FullFeed = &FullFeedData{}
+		if m := ff.GetMarketFF(); m != nil {
+			fd.FullFeed.MarketFF = &MarketFullFeed{
+				LTPC:         ltpcData(m.GetLtpc()),
+				MarketLevel:  quotes(m.GetMarketLevel().GetBidAskQuote()),
+				OptionGreeks: optionGreeks(m.GetOptionGreeks()),
+				MarketOHLC:   ohlcs(m.GetMarketOHLC().GetOhlc()),
+				ATP:          m.GetAtp(),
+				VTT:          m.GetVtt(),
+				OI:           m.GetOi(),
+				IV:           m.GetIv(),
+				TBQ:          m.GetTbq(),
+				TSQ:          m.GetTsq(),
+			}
+		}
+		if idx := ff.GetIndexFF(); idx != nil {
+			fd.FullFeed.IndexFF = &IndexFullFeed{
+				LTPC:       ltpcData(idx.GetLtpc()),
+				MarketOHLC: ohlcs(idx.GetMarketOHLC().GetOhlc()),
+			}
+		}
+	}
+
+	if g := feed.GetFirstLevelWithGreeks(); g != nil {
+		fd.FirstLevelWithGreeks = &FirstLevelWithGreeks{
+			LTPC:         ltpcData(g.GetLtpc()),
+			OptionGreeks: optionGreeks(g.GetOptionGreeks()),
+			VTT:          g.GetVtt(),
+			OI:           g.GetOi(),
+			IV:           g.GetIv(),
+		}
+		if q := g.GetFirstDepth(); q != nil {
+			fd.FirstLevelWithGreeks.FirstDepth = &Quote{BidQ: q.BidQ, BidP: q.BidP, AskQ: q.AskQ, AskP: q.AskP}
+		}
+	}
+	return fd
+}
+
+// requestMode maps the feed's mode enum onto the names used to subscribe.
+func requestMode(mode pb.RequestMode) SubscriptionMode {
+	switch mode {
+	case pb.RequestMode_full_d5:
+		return ModeFull
+	case pb.RequestMode_option_greeks:
+		return ModeOptionGreeks
+	case pb.RequestMode_full_d30:
+		return ModeFullD30
+	}
+	return ModeLTPC
+}
+
+func ltpcData(l *pb.LTPC) *LTPCData {
+	if l == nil {
+		return nil
+	}
+	return &LTPCData{LTP: l.Ltp, LTT: l.Ltt, LTQ: l.Ltq, CP: l.Cp}
+}
+
+func quotes(qs []*pb.Quote) []Quote {
+	if len(qs) == 0 {
+		return nil
+	}
+	out := make([]Quote, len(qs))
+	for i, q := range qs {
+		out[i] = Quote{BidQ: q.GetBidQ(), BidP: q.GetBidP(), AskQ: q.GetAskQ(), AskP: q.GetAskP()}
+	}
+	return out
+}
+
+func optionGreeks(g *pb.OptionGreeks) *OptionGreeks {
+	if g == nil {
+		return nil
+	}
+	return &OptionGreeks{Delta: g.Delta, Theta: g.Theta, Gamma: g.Gamma, Vega: g.Vega, Rho: g.Rho}
+}
+
+func ohlcs(os []*pb.OHLC) []OHLC {
+	if len(os) == 0 {
+		return nil
+	}
+	out := make([]OHLC, len(os))
+	for i, o := range os {
+		out[i] = OHLC{Interval: o.GetInterval(), Open: o.GetOpen(), High: o.GetHigh(), Low: o.GetLow(), Close: o.GetClose(), Volume: o.GetVol(), TS: o.GetTs()}
+	}
+	return out
+}
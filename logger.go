@@ -0,0 +1,67 @@
+package upstox
+
+// Logger receives the library's diagnostics: dry-run reports, feed
+// disconnects, background job failures and the like. args are alternating
+// key/value pairs, as in log/slog, so a *slog.Logger can be passed as is.
+// The default discards everything.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// SugaredLogger is the key/value logging subset of zap's *SugaredLogger.
+type SugaredLogger interface {
+	Debugw(msg string, keysAndValues ...any)
+	Infow(msg string, keysAndValues ...any)
+	Warnw(msg string, keysAndValues ...any)
+	Errorw(msg string, keysAndValues ...any)
+}
+
+// ZapLogger adapts a zap SugaredLogger, e.g. ZapLogger(zapLogger.Sugar()),
+// without this package depending on zap.
+func ZapLogger(l SugaredLogger) Logger {
+	return zapLogger{l}
+}
+
+type zapLogger struct{ l SugaredLogger }
+
+func (z zapLogger) Debug(msg string, args ...any) { z.l.Debugw(msg, args...) }
+func (z zapLogger) Info(msg string, args ...any)  { z.l.Infow(msg, args...) }
+func (z zapLogger) Warn(msg string, args ...any)  { z.l.Warnw(msg, args...) }
+func (z zapLogger) Error(msg string, args ...any) { z.l.Errorw(msg, args...) }
+
+// WithLogger sends the Manager's diagnostics, and those of feeds it creates,
+// to l, e.g. WithLogger(slog.Default()).
+func WithLogger(l Logger) ManagerOption {
+	return func(m *Manager) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		m.logger = l
+	}
+}
+
+// SetLogger sends the feed's diagnostics to l.
+func (wsm *WebSocketManager) SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.logger = l
+}
+
+func (wsm *WebSocketManager) log() Logger {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	return wsm.logger
+}
@@ -0,0 +1,119 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	authorizationDialogURL = "https://api.upstox.com/v2/login/authorization/dialog"
+	authorizationTokenURL  = "https://api.upstox.com/v2/login/authorization/token"
+)
+
+var ErrLoginStateMismatch = errors.New("login state mismatch")
+
+// TokenResponse is the result of exchanging an authorization code. The API
+// returns the profile fields and tokens at the top level, without the usual
+// status envelope.
+type TokenResponse struct {
+	Email         string   `json:"email"`
+	Exchanges     []string `json:"exchanges"`
+	Products      []string `json:"products"`
+	Broker        string   `json:"broker"`
+	UserID        string   `json:"user_id"`
+	UserName      string   `json:"user_name"`
+	OrderTypes    []string `json:"order_types"`
+	UserType      string   `json:"user_type"`
+	POA           bool     `json:"poa"`
+	IsActive      bool     `json:"is_active"`
+	AccessToken   string   `json:"access_token"`
+	ExtendedToken string   `json:"extended_token,omitempty"`
+
+	Status string       `json:"status,omitempty"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *TokenResponse) envelope() (string, []OrderError) {
+	if r.AccessToken != "" {
+		return "success", nil
+	}
+	return r.Status, r.Errors
+}
+
+// AuthorizationURL is the login page to send the user to. After login Upstox
+// redirects to redirectURI, which must match the one registered for the app,
+// with ?code=...&state=state.
+func (m *Manager) AuthorizationURL(redirectURI, state string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", m.clientID)
+	q.Set("redirect_uri", redirectURI)
+	if state != "" {
+		q.Set("state", state)
+	}
+	return authorizationDialogURL + "?" + q.Encode()
+}
+
+// ExchangeCode trades the code from the login redirect for an access token,
+// which the Manager then uses for every call. Codes are single use.
+func (m *Manager) ExchangeCode(ctx context.Context, code, redirectURI string) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("code", code)
+	form.Set("client_id", m.clientID)
+	form.Set("client_secret", m.clientSecret)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", authorizationTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokenResp TokenResponse
+	if err := m.do(req, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	m.SetAccessToken(tokenResp.AccessToken)
+	if tokenResp.ExtendedToken != "" {
+		m.SetExtendedToken(tokenResp.ExtendedToken)
+	}
+	return &tokenResp, nil
+}
+
+// LoginHandler serves the redirect URI: it checks state, exchanges the code
+// and reports the outcome to done, so a local server can complete the login
+// without any copy and paste:
+//
+//	http.Handle("/callback", m.LoginHandler(redirectURI, state, done))
+func (m *Manager) LoginHandler(redirectURI, state string, done func(*TokenResponse, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if state != "" && q.Get("state") != state {
+			http.Error(w, "login failed: state mismatch", http.StatusBadRequest)
+			done(nil, ErrLoginStateMismatch)
+			return
+		}
+		code := q.Get("code")
+		if code == "" {
+			http.Error(w, "login failed: no authorization code", http.StatusBadRequest)
+			done(nil, errors.New("login redirect carried no authorization code"))
+			return
+		}
+
+		tokenResp, err := m.ExchangeCode(r.Context(), code, redirectURI)
+		if err != nil {
+			http.Error(w, "login failed: "+err.Error(), http.StatusBadGateway)
+			done(nil, err)
+			return
+		}
+		fmt.Fprintf(w, "Logged in as %s. You can close this window.\n", tokenResp.UserName)
+		done(tokenResp, nil)
+	})
+}
@@ -1,11 +1,14 @@
 package upstox
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,21 +17,91 @@ type Manager struct {
 	clientSecret string
 	accessToken  string
 	httpClient   *http.Client
+	orderClient  *http.Client
+	codec        Codec
+	dryRun       bool
+	hooks        orderHooks
+	mu           sync.RWMutex
+
+	duplicateWindow time.Duration
+	recentOrders    map[string]time.Time
+
+	feeds map[*WebSocketManager]struct{}
+
+	orderLatency LatencyHistogram
+
+	scheduler orderScheduler
+	presets   map[string]OrderRequest
+
+	expiryGuard *ExpiryGuard
+
+	circuitMode  CircuitMode
+	circuitBands map[string]circuitCacheEntry
+
+	instruments InstrumentLookup
+
+	audit *AuditLog
+
+	halt atomic.Pointer[haltState]
+
+	endpointVersions map[Endpoint]APIVersion
+
+	tokenProvider TokenProvider
+	extendedToken string
+
+	logger   Logger
+	retry    *retrier
+	limiters map[RateFamily][]*tokenBucket
+	quota    atomic.Pointer[Quota]
+
+	confirm        bool
+	confirmDelay   time.Duration
+	confirmTracker *OrderTracker
+
+	paper *PaperBroker
 }
 
-func NewManager(clientID, clientSecret, accessToken string) *Manager {
-	return &Manager{
+func NewManager(clientID, clientSecret, accessToken string, opts ...ManagerOption) *Manager {
+	m := &Manager{
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		accessToken:  accessToken,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		orderClient: newOrderClient(10 * time.Second),
+		codec:       stdCodec{},
+		logger:      nopLogger{},
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// PlaceOrder places an arbitrary order. It goes through the same local
+// validation and guards as every other placement helper.
+func (m *Manager) PlaceOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error) {
+	return m.placeOrder(ctx, orderReq)
+}
+
+// PlaceLimitOrder places an intraday DAY limit order; use PlaceOrder for
+// other products or validities.
+func (m *Manager) PlaceLimitOrder(ctx context.Context, instrumentToken string, quantity int, side string, price float64) (*OrderResponse, error) {
+	orderReq := marketOrderRequest(instrumentToken, quantity, side)
+	orderReq.OrderType = string(OrderTypeLimit)
+	orderReq.Price = price
+	return m.placeOrder(ctx, orderReq)
 }
 
-func (m *Manager) PlaceMarketOrder(instrumentToken string, quantity int, side string) (*OrderResponse, error) {
-	orderReq := OrderRequest{
+func (m *Manager) PlaceMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string) (*OrderResponse, error) {
+	return m.placeOrder(ctx, marketOrderRequest(instrumentToken, quantity, side))
+}
+
+func marketOrderRequest(instrumentToken string, quantity int, side string) OrderRequest {
+	return OrderRequest{
 		Quantity:          quantity,
 		Product:           string(ProductIntraday),
 		Validity:          string(ValidityDay),
@@ -41,140 +114,137 @@ func (m *Manager) PlaceMarketOrder(instrumentToken string, quantity int, side st
 		IsAMO:             false,
 		Slice:             true,
 	}
-
-	return m.placeOrder(orderReq)
 }
 
-func (m *Manager) PlaceBuyOrder(instrumentToken string, quantity int) (*OrderResponse, error) {
-	return m.PlaceMarketOrder(instrumentToken, quantity, string(OrderSideBuy))
+func (m *Manager) PlaceBuyOrder(ctx context.Context, instrumentToken string, quantity int) (*OrderResponse, error) {
+	return m.PlaceMarketOrder(ctx, instrumentToken, quantity, string(OrderSideBuy))
 }
 
-func (m *Manager) PlaceSellOrder(instrumentToken string, quantity int) (*OrderResponse, error) {
-	return m.PlaceMarketOrder(instrumentToken, quantity, string(OrderSideSell))
+func (m *Manager) PlaceSellOrder(ctx context.Context, instrumentToken string, quantity int) (*OrderResponse, error) {
+	return m.PlaceMarketOrder(ctx, instrumentToken, quantity, string(OrderSideSell))
 }
 
-func (m *Manager) placeOrder(orderReq OrderRequest) (*OrderResponse, error) {
-	url := "https://api-hft.upstox.com/v3/order/place"
-
-	reqBody, err := json.Marshal(orderReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal order request: %w", err)
+func (m *Manager) placeOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error) {
+	if err := m.checkHalt(); err != nil {
+		return nil, err
 	}
+	return m.submitOrder(ctx, orderReq)
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// submitOrder places an order without consulting the kill switch. Only exits
+// go through it directly.
+func (m *Manager) submitOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error) {
+	if err := m.preflight(ctx, &orderReq); err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	if m.paper != nil {
+		return m.paper.place(orderReq), nil
+	}
+	if m.dryRun {
+		resp, err := m.dryRunOrder(ctx, orderReq)
+		if err == nil {
+			m.firePlaced(orderReq, resp)
+		}
+		return resp, err
+	}
 
-	resp, err := m.httpClient.Do(req)
+	endpoint, _, err := m.endpointURL(ctx, EndpointPlaceOrder)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	req, err := m.newRequest(ctx, "POST", endpoint, orderReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Log raw response for debugging
-	fmt.Printf("Order Place Response - Status: %d, Body: %s\n", resp.StatusCode, string(body))
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	orderResp, err := m.sendOrder(req, orderReq)
+	if err != nil {
+		return nil, err
 	}
 
-	var orderResp OrderResponse
-	if err := json.Unmarshal(body, &orderResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if !m.confirm {
+		return orderResp, nil
 	}
+	return m.confirmPlaced(ctx, orderReq, orderResp), nil
+}
 
-	// Validate the API response status even if HTTP status is OK
-	if orderResp.Status != "success" {
-		errorMsg := "Order placement failed"
-		if len(orderResp.Errors) > 0 {
-			errorMsg = orderResp.Errors[0].Message
-		}
-		return nil, fmt.Errorf("API returned error status '%s': %s", orderResp.Status, errorMsg)
+// preflight runs the local checks every order must pass before it is sent,
+// whichever placement path it takes. Circuit clamping may adjust orderReq.
+func (m *Manager) preflight(ctx context.Context, orderReq *OrderRequest) error {
+	if err := validateOrderRequest(*orderReq); err != nil {
+		return fmt.Errorf("invalid order: %w", err)
 	}
-
-	// Verify that we have order IDs
-	if orderResp.Data == nil || len(orderResp.Data.OrderIDs) == 0 {
-		return nil, fmt.Errorf("no order IDs returned in successful response")
+	if err := m.checkInstrument(*orderReq); err != nil {
+		return err
 	}
-
-	// Wait briefly and get the actual order details to see the real status
-	time.Sleep(500 * time.Millisecond)
-	
-	orderID := orderResp.Data.OrderIDs[0]
-	orderDetails, err := m.GetOrderDetails(orderID)
-	if err != nil {
-		// If we can't get order details, return the original response
-		fmt.Printf("Warning: Could not get order details for ID %s: %v\n", orderID, err)
-		return &orderResp, nil
+	if err := m.checkPreOpen(*orderReq); err != nil {
+		return err
 	}
-
-	// Create a response with the actual order status
-	detailedResponse := &OrderResponse{
-		Status: "success",
-		Data: &OrderResponseData{
-			OrderIDs: orderResp.Data.OrderIDs,
-		},
-		Metadata: orderResp.Metadata,
+	if err := m.checkExpiryEntry(ctx, *orderReq); err != nil {
+		return err
 	}
-
-	// If order was rejected, add error details
-	if orderDetails.Status == "rejected" {
-		detailedResponse.Status = "error"
-		detailedResponse.Errors = []OrderError{{
-			ErrorCode: "ORDER_REJECTED",
-			Message:   orderDetails.StatusMessage,
-		}}
+	if err := m.checkCircuit(ctx, orderReq); err != nil {
+		return err
 	}
 
-	return detailedResponse, nil
+	return m.checkDuplicate(*orderReq)
 }
 
-func (m *Manager) GetPositions() ([]Position, error) {
-	url := "https://api.upstox.com/v2/portfolio/short-term-positions"
+func (m *Manager) sendOrder(req *http.Request, orderReq OrderRequest) (*OrderResponse, error) {
+	var orderResp OrderResponse
+	var err error
+
+	start := time.Now()
+	pprof.Do(req.Context(), pprof.Labels("upstox", "order_place"), func(context.Context) {
+		err = m.doWith(m.orderClient, req, &orderResp)
+	})
+	m.orderLatency.Since(start)
 
-	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			m.fireRejected(orderReq, apiErr.Message())
+		}
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
+	// v2 returns a single order_id rather than a list.
+	if orderResp.Data != nil && len(orderResp.Data.OrderIDs) == 0 && orderResp.Data.OrderID != "" {
+		orderResp.Data.OrderIDs = []string{orderResp.Data.OrderID}
+	}
 
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+	// Verify that we have order IDs
+	if orderResp.Data == nil || len(orderResp.Data.OrderIDs) == 0 {
+		return nil, fmt.Errorf("no order IDs returned in successful response")
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	m.firePlaced(orderReq, &orderResp)
+	return &orderResp, nil
+}
+
+func (m *Manager) GetPositions(ctx context.Context) ([]Position, error) {
+	if m.paper != nil {
+		return m.paper.positionList(), nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	url := "https://api.upstox.com/v2/portfolio/short-term-positions"
+
+	req, err := m.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var posResp PositionResponse
-	if err := json.Unmarshal(body, &posResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := m.do(req, &posResp); err != nil {
+		return nil, err
 	}
 
 	return posResp.Data, nil
 }
 
-func (m *Manager) ClosePosition(instrumentToken string) (*OrderResponse, error) {
-	positions, err := m.GetPositions()
+func (m *Manager) ClosePosition(ctx context.Context, instrumentToken string) (*OrderResponse, error) {
+	positions, err := m.GetPositions(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get positions: %w", err)
 	}
@@ -200,38 +270,28 @@ func (m *Manager) ClosePosition(instrumentToken string) (*OrderResponse, error)
 		quantity = -quantity
 	}
 
-	return m.PlaceMarketOrder(instrumentToken, quantity, side)
+	return m.submitOrder(ctx, marketOrderRequest(instrumentToken, quantity, side))
 }
 
-func (m *Manager) CloseAllPositions() ([]OrderResponse, error) {
+func (m *Manager) CloseAllPositions(ctx context.Context) ([]OrderResponse, error) {
 	url := "https://api.upstox.com/v2/order/positions/exit"
 
-	req, err := http.NewRequest("POST", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if m.paper != nil {
+		return m.paperCloseAll(ctx)
 	}
-
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+	if m.dryRun {
+		m.logger.Info("dry run: would exit all open positions")
+		return []OrderResponse{{Status: "success", DryRun: true}}, nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	req, err := m.newRequest(ctx, "POST", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var exitResp OrderResponse
-	if err := json.Unmarshal(body, &exitResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := m.do(req, &exitResp); err != nil {
+		return nil, err
 	}
 
 	var responses []OrderResponse
@@ -239,124 +299,105 @@ func (m *Manager) CloseAllPositions() ([]OrderResponse, error) {
 	return responses, nil
 }
 
-func (m *Manager) GetOrderBook() ([]Order, error) {
-	url := "https://api.upstox.com/v2/order/retrieve-all"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+func (m *Manager) GetOrderBook(ctx context.Context) ([]Order, error) {
+	if m.paper != nil {
+		return m.paper.orderBook(), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
+	url := "https://api.upstox.com/v2/order/retrieve-all"
 
-	body, err := io.ReadAll(resp.Body)
+	req, err := m.newRequest(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
+	// The order book can run to thousands of entries late in the session;
+	// do decodes the response straight off the wire instead of buffering the
+	// whole body first.
 	var orderBookResp OrderBookResponse
-	if err := json.Unmarshal(body, &orderBookResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := m.do(req, &orderBookResp); err != nil {
+		return nil, err
 	}
 
 	return orderBookResp.Data, nil
 }
 
-func (m *Manager) GetOrderDetails(orderID string) (*Order, error) {
-	url := fmt.Sprintf("https://api.upstox.com/v2/order/details?order_id=%s", orderID)
+func (m *Manager) GetOrderDetails(ctx context.Context, orderID string) (*Order, error) {
+	if m.paper != nil {
+		return m.paper.orderDetails(orderID)
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/order/details?order_id="+url.QueryEscape(orderID), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+	var orderDetailResp OrderDetailResponse
+	if err := m.do(req, &orderDetailResp); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	return &orderDetailResp.Data, nil
+}
+
+// GetTradesForOrder returns the individual fills of an order.
+func (m *Manager) GetTradesForOrder(ctx context.Context, orderID string) ([]Trade, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("order ID is required")
+	}
+	if m.paper != nil {
+		return m.paper.tradesFor(orderID), nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
+	req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/order/trades?order_id="+url.QueryEscape(orderID), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var orderDetailResp OrderDetailResponse
-	if err := json.Unmarshal(body, &orderDetailResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	var tradesResp TradesResponse
+	if err := m.do(req, &tradesResp); err != nil {
+		return nil, err
 	}
 
-	return &orderDetailResp.Data, nil
+	return tradesResp.Data, nil
 }
 
-func (m *Manager) NewWebSocketManager(instrumentKeys []string, onPriceUpdate func(string, float64, *int32)) (*WebSocketManager, error) {
-	wsURL, err := m.getAuthorizedWebSocketURL()
+func (m *Manager) NewWebSocketManager(ctx context.Context, instrumentKeys []string, onPriceUpdate func(string, float64, *int32)) (*WebSocketManager, error) {
+	wsURL, err := m.getAuthorizedWebSocketURL(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get authorized WebSocket URL: %w", err)
 	}
 
 	config := WebSocketConfig{
 		InstrumentKeys: instrumentKeys,
-		Token:          m.accessToken,
+		Token:          m.GetAccessToken(),
 	}
 
-	return NewWebSocketManager(wsURL, config, onPriceUpdate), nil
+	wsm := NewWebSocketManager(wsURL, config, onPriceUpdate)
+	wsm.logger = m.logger
+	m.registerFeed(wsm)
+	return wsm, nil
 }
 
-func (m *Manager) getAuthorizedWebSocketURL() (string, error) {
+func (m *Manager) getAuthorizedWebSocketURL(ctx context.Context) (string, error) {
 	authorizeURL := "https://api.upstox.com/v3/feed/market-data-feed/authorize"
-	
-	req, err := http.NewRequest("GET", authorizeURL, nil)
-	if err != nil {
-		return "", err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	req, err := m.newRequest(ctx, "GET", authorizeURL, nil)
 	if err != nil {
 		return "", err
 	}
 
 	var authResp AuthorizeResponse
-	if err := json.Unmarshal(body, &authResp); err != nil {
+	if err := m.do(req, &authResp); err != nil {
 		return "", err
 	}
 
-	if authResp.Status != "success" {
-		return "", fmt.Errorf("authorization failed: %s", authResp.Status)
-	}
-
 	return authResp.Data.AuthorizedRedirectURI, nil
 }
 
 func (m *Manager) GetAccessToken() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.accessToken
 }
 
@@ -368,12 +409,12 @@ func (m *Manager) GetClientSecret() string {
 	return m.clientSecret
 }
 
-func (m *Manager) GetFundsAndMargin(segment ...string) (*FundsResponse, error) {
+func (m *Manager) GetFundsAndMargin(ctx context.Context, segment ...string) (*FundsResponse, error) {
 	url := "https://api.upstox.com/v2/user/get-funds-and-margin"
-	
-	req, err := http.NewRequest("GET", url, nil)
+
+	req, err := m.newRequest(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
 	if len(segment) > 0 {
@@ -382,31 +423,9 @@ func (m *Manager) GetFundsAndMargin(segment ...string) (*FundsResponse, error) {
 		req.URL.RawQuery = q.Encode()
 	}
 
-	req.Header.Set("Authorization", "Bearer "+m.accessToken)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
 	var fundsResp FundsResponse
-	if err := json.Unmarshal(body, &fundsResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if fundsResp.Status != "success" {
-		return nil, fmt.Errorf("API returned error status: %s", fundsResp.Status)
+	if err := m.do(req, &fundsResp); err != nil {
+		return nil, err
 	}
 
 	return &fundsResp, nil
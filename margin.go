@@ -0,0 +1,123 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+type MarginInstrument struct {
+	InstrumentKey   string  `json:"instrument_key"`
+	Quantity        int     `json:"quantity"`
+	TransactionType string  `json:"transaction_type"`
+	Product         string  `json:"product"`
+	Price           float64 `json:"price"`
+}
+
+type MarginRequest struct {
+	Instruments []MarginInstrument `json:"instruments"`
+}
+
+type InstrumentMargin struct {
+	SpanMargin       float64 `json:"span_margin"`
+	ExposureMargin   float64 `json:"exposure_margin"`
+	EquityMargin     float64 `json:"equity_margin"`
+	NetBuyPremium    float64 `json:"net_buy_premium"`
+	AdditionalMargin float64 `json:"additional_margin"`
+	TotalMargin      float64 `json:"total_margin"`
+	TenderMargin     float64 `json:"tender_margin"`
+}
+
+type MarginRequirement struct {
+	Margins        []InstrumentMargin `json:"margins"`
+	RequiredMargin float64            `json:"required_margin"`
+	FinalMargin    float64            `json:"final_margin"`
+}
+
+type MarginResponse struct {
+	Status string            `json:"status"`
+	Data   MarginRequirement `json:"data"`
+	Errors []OrderError      `json:"errors,omitempty"`
+}
+
+type ChargeTaxes struct {
+	GST       float64 `json:"gst"`
+	STT       float64 `json:"stt"`
+	StampDuty float64 `json:"stamp_duty"`
+}
+
+type OtherCharges struct {
+	Transaction  float64 `json:"transaction"`
+	Clearing     float64 `json:"clearing"`
+	IPFT         float64 `json:"ipft"`
+	SEBITurnover float64 `json:"sebi_turnover"`
+}
+
+type BrokerageCharges struct {
+	Total        float64      `json:"total"`
+	Brokerage    float64      `json:"brokerage"`
+	Taxes        ChargeTaxes  `json:"taxes"`
+	OtherCharges OtherCharges `json:"other_charges"`
+}
+
+type BrokerageResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Charges BrokerageCharges `json:"charges"`
+	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *MarginResponse) envelope() (string, []OrderError)    { return r.Status, r.Errors }
+func (r *BrokerageResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetMargin returns the combined margin requirement for the given orders,
+// including any hedge benefit the exchange grants across legs.
+func (m *Manager) GetMargin(ctx context.Context, orders ...OrderRequest) (*MarginRequirement, error) {
+	url := "https://api.upstox.com/v2/charges/margin"
+
+	marginReq := MarginRequest{}
+	for _, o := range orders {
+		marginReq.Instruments = append(marginReq.Instruments, MarginInstrument{
+			InstrumentKey:   o.InstrumentToken,
+			Quantity:        o.Quantity,
+			TransactionType: o.TransactionType,
+			Product:         o.Product,
+			Price:           o.Price,
+		})
+	}
+
+	req, err := m.newRequest(ctx, "POST", url, marginReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var marginResp MarginResponse
+	if err := m.do(req, &marginResp); err != nil {
+		return nil, err
+	}
+
+	return &marginResp.Data, nil
+}
+
+func (m *Manager) GetBrokerage(ctx context.Context, order OrderRequest) (*BrokerageCharges, error) {
+	q := url.Values{}
+	q.Set("instrument_token", order.InstrumentToken)
+	q.Set("quantity", strconv.Itoa(order.Quantity))
+	q.Set("product", order.Product)
+	q.Set("transaction_type", order.TransactionType)
+	q.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
+
+	req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/charges/brokerage?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var brokerageResp BrokerageResponse
+	if err := m.do(req, &brokerageResp); err != nil {
+		return nil, fmt.Errorf("failed to get brokerage: %w", err)
+	}
+
+	return &brokerageResp.Data.Charges, nil
+}
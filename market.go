@@ -0,0 +1,164 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const marketInfoBaseURL = "https://api.upstox.com/v2/market"
+
+type HolidayType string
+
+const (
+	HolidayTrading       HolidayType = "TRADING_HOLIDAY"
+	HolidaySettlement    HolidayType = "SETTLEMENT_HOLIDAY"
+	HolidaySpecialTiming HolidayType = "SPECIAL_TIMING"
+)
+
+// ExchangeTiming is an exchange's trading window on a day, with times as Unix
+// milliseconds.
+type ExchangeTiming struct {
+	Exchange  string `json:"exchange"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
+}
+
+func (t ExchangeTiming) Start() time.Time { return time.UnixMilli(t.StartTime).In(IST) }
+func (t ExchangeTiming) End() time.Time   { return time.UnixMilli(t.EndTime).In(IST) }
+
+// MarketHoliday is a day on which ClosedExchanges do not trade. Exchanges in
+// OpenExchanges trade, possibly in a special session such as Muhurat trading.
+// Date is YYYY-MM-DD.
+type MarketHoliday struct {
+	Date            string           `json:"date"`
+	Description     string           `json:"description"`
+	HolidayType     HolidayType      `json:"holiday_type"`
+	ClosedExchanges []string         `json:"closed_exchanges"`
+	OpenExchanges   []ExchangeTiming `json:"open_exchanges"`
+}
+
+type MarketHolidaysResponse struct {
+	Status string          `json:"status"`
+	Data   []MarketHoliday `json:"data"`
+	Errors []OrderError    `json:"errors,omitempty"`
+}
+
+func (r *MarketHolidaysResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+type MarketTimingsResponse struct {
+	Status string           `json:"status"`
+	Data   []ExchangeTiming `json:"data"`
+	Errors []OrderError     `json:"errors,omitempty"`
+}
+
+func (r *MarketTimingsResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetMarketHolidays returns the exchange holidays of the current year.
+func (m *Manager) GetMarketHolidays(ctx context.Context) ([]MarketHoliday, error) {
+	return m.getMarketHolidays(ctx, marketInfoBaseURL+"/holidays")
+}
+
+// GetMarketHoliday returns the holiday entries for a single day, which is
+// empty on a regular trading day.
+func (m *Manager) GetMarketHoliday(ctx context.Context, date time.Time) ([]MarketHoliday, error) {
+	return m.getMarketHolidays(ctx, marketInfoBaseURL+"/holidays/"+date.In(IST).Format(time.DateOnly))
+}
+
+func (m *Manager) getMarketHolidays(ctx context.Context, endpoint string) ([]MarketHoliday, error) {
+	req, err := m.newRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var holidayResp MarketHolidaysResponse
+	if err := m.do(req, &holidayResp); err != nil {
+		return nil, fmt.Errorf("failed to get market holidays: %w", err)
+	}
+
+	return holidayResp.Data, nil
+}
+
+// GetMarketTimings returns each exchange's trading window on date. Exchanges
+// closed that day are absent.
+func (m *Manager) GetMarketTimings(ctx context.Context, date time.Time) ([]ExchangeTiming, error) {
+	req, err := m.newRequest(ctx, "GET", marketInfoBaseURL+"/timings/"+date.In(IST).Format(time.DateOnly), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var timingsResp MarketTimingsResponse
+	if err := m.do(req, &timingsResp); err != nil {
+		return nil, fmt.Errorf("failed to get market timings: %w", err)
+	}
+
+	return timingsResp.Data, nil
+}
+
+// IsTradingDay reports whether exchange ("NSE", "NFO", "MCX", ...) trades on
+// date's IST calendar day. Weekends count as trading days only when a
+// special session lists the exchange as open.
+func (m *Manager) IsTradingDay(ctx context.Context, exchange string, date time.Time) (bool, error) {
+	holidays, err := m.GetMarketHoliday(ctx, date)
+	if err != nil {
+		return false, err
+	}
+	return isTradingDay(holidays, exchange, date), nil
+}
+
+// isTradingDay decides from holiday entries, which may include other days.
+func isTradingDay(holidays []MarketHoliday, exchange string, date time.Time) bool {
+	day := date.In(IST).Format(time.DateOnly)
+	for _, h := range holidays {
+		if h.Date != day {
+			continue
+		}
+		for _, open := range h.OpenExchanges {
+			if open.Exchange == exchange {
+				return true
+			}
+		}
+		if slices.Contains(h.ClosedExchanges, exchange) {
+			return false
+		}
+	}
+	return !isWeekend(date)
+}
+
+// ExchangeStatus is an exchange's session state at LastUpdated (Unix
+// milliseconds).
+type ExchangeStatus struct {
+	Exchange    string       `json:"exchange"`
+	Status      MarketStatus `json:"status"`
+	LastUpdated int64        `json:"last_updated"`
+}
+
+type MarketStatusResponse struct {
+	Status string         `json:"status"`
+	Data   ExchangeStatus `json:"data"`
+	Errors []OrderError   `json:"errors,omitempty"`
+}
+
+func (r *MarketStatusResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetMarketStatus fetches an exchange's session state over REST, for callers
+// that have no feed to learn it from as with SegmentStatus.
+func (m *Manager) GetMarketStatus(ctx context.Context, exchange string) (*ExchangeStatus, error) {
+	if exchange == "" {
+		return nil, fmt.Errorf("exchange is required")
+	}
+
+	req, err := m.newRequest(ctx, "GET", marketInfoBaseURL+"/status/"+url.PathEscape(exchange), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statusResp MarketStatusResponse
+	if err := m.do(req, &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to get market status: %w", err)
+	}
+
+	return &statusResp.Data, nil
+}
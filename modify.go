@@ -0,0 +1,225 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type ModifyOrderRequest struct {
+	OrderID           string  `json:"order_id"`
+	Quantity          int     `json:"quantity,omitempty"`
+	Validity          string  `json:"validity"`
+	Price             float64 `json:"price"`
+	OrderType         string  `json:"order_type"`
+	DisclosedQuantity int     `json:"disclosed_quantity"`
+	TriggerPrice      float64 `json:"trigger_price"`
+}
+
+type OrderIDResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		OrderID string `json:"order_id"`
+	} `json:"data"`
+	Metadata *OrderMetadata `json:"metadata,omitempty"`
+	Errors   []OrderError   `json:"errors,omitempty"`
+}
+
+func (r *OrderIDResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// ModifyOrder amends a working order. The order is looked up first so that
+// modifications the exchange would refuse (IOC orders, orders that are no
+// longer working, quantities below what has filled) fail locally with
+// ErrNotModifiable.
+func (m *Manager) ModifyOrder(ctx context.Context, modReq ModifyOrderRequest) (*OrderIDResponse, error) {
+	if err := validateModifyRequest(modReq); err != nil {
+		return nil, fmt.Errorf("invalid modification: %w", err)
+	}
+	if m.dryRun && strings.HasPrefix(modReq.OrderID, dryRunOrderPrefix) {
+		return m.sendModify(ctx, modReq)
+	}
+
+	order, err := m.GetOrderDetails(ctx, modReq.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order %s: %w", modReq.OrderID, err)
+	}
+	return m.modifyOrder(ctx, order, modReq)
+}
+
+// sendModify sends a modification that has already been checked.
+func (m *Manager) sendModify(ctx context.Context, modReq ModifyOrderRequest) (*OrderIDResponse, error) {
+	if m.paper != nil {
+		return m.paper.modify(modReq)
+	}
+	if m.dryRun {
+		m.logger.Info("dry run: would modify order", "order_id", modReq.OrderID,
+			"price", modReq.Price, "trigger_price", modReq.TriggerPrice, "quantity", modReq.Quantity)
+		return dryRunOrderID(modReq.OrderID), nil
+	}
+
+	endpoint, _, err := m.endpointURL(ctx, EndpointModifyOrder)
+	if err != nil {
+		return nil, err
+	}
+	req, err := m.newRequest(ctx, "PUT", endpoint, modReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var modResp OrderIDResponse
+	if err := m.doWith(m.orderClient, req, &modResp); err != nil {
+		return nil, err
+	}
+
+	return &modResp, nil
+}
+
+// modifyFromOrder builds a modify request that keeps every field of order
+// except the ones the caller then overrides.
+func modifyFromOrder(order *Order) ModifyOrderRequest {
+	return ModifyOrderRequest{
+		OrderID:           order.OrderID,
+		Quantity:          order.Quantity,
+		Validity:          order.Validity,
+		Price:             order.Price,
+		OrderType:         order.OrderType,
+		DisclosedQuantity: order.DisclosedQuantity,
+		TriggerPrice:      order.TriggerPrice,
+	}
+}
+
+func (m *Manager) CancelOrder(ctx context.Context, orderID string) (*OrderIDResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("order ID is required")
+	}
+
+	if m.paper != nil {
+		return m.paper.cancel(orderID)
+	}
+	if m.dryRun {
+		m.logger.Info("dry run: would cancel order", "order_id", orderID)
+		return dryRunOrderID(orderID), nil
+	}
+
+	endpoint, _, err := m.endpointURL(ctx, EndpointCancelOrder)
+	if err != nil {
+		return nil, err
+	}
+	req, err := m.newRequest(ctx, "DELETE", endpoint+"?order_id="+url.QueryEscape(orderID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelResp OrderIDResponse
+	if err := m.doWith(m.orderClient, req, &cancelResp); err != nil {
+		return nil, err
+	}
+
+	return &cancelResp, nil
+}
+
+const cancelAllOrdersURL = "https://api.upstox.com/v2/order/multi/cancel"
+
+// CancelAllFilter narrows CancelAllOrders.
+type CancelAllFilter func(url.Values)
+
+// CancelSegment limits cancellation to one segment, e.g. "NSE_FO".
+func CancelSegment(segment string) CancelAllFilter {
+	return func(q url.Values) { q.Set("segment", segment) }
+}
+
+// CancelTag limits cancellation to orders placed with tag.
+func CancelTag(tag string) CancelAllFilter {
+	return func(q url.Values) { q.Set("tag", tag) }
+}
+
+type MultiOrderSummary struct {
+	Total        int `json:"total"`
+	Success      int `json:"success"`
+	Error        int `json:"error"`
+	PayloadError int `json:"payload_error"`
+}
+
+// MultiOrderResponse reports a bulk operation. Status is "partial_success"
+// when some orders failed; those are listed in Errors with their order IDs.
+type MultiOrderResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		OrderIDs []string `json:"order_ids"`
+	} `json:"data"`
+	Errors  []OrderError      `json:"errors,omitempty"`
+	Summary MultiOrderSummary `json:"summary"`
+}
+
+func (r *MultiOrderResponse) envelope() (string, []OrderError) {
+	if r.Status == "partial_success" {
+		return "success", nil
+	}
+	return r.Status, r.Errors
+}
+
+// CancelAllOrders cancels every open order, or those matching all filters.
+// A partial success is not an error; check Summary and Errors.
+func (m *Manager) CancelAllOrders(ctx context.Context, filters ...CancelAllFilter) (*MultiOrderResponse, error) {
+	q := url.Values{}
+	for _, f := range filters {
+		f(q)
+	}
+
+	if m.paper != nil {
+		return m.paper.cancelAll(q), nil
+	}
+	if m.dryRun {
+		return m.dryRunCancelAll(ctx, q)
+	}
+
+	endpoint := cancelAllOrdersURL
+	if len(q) > 0 {
+		endpoint += "?" + q.Encode()
+	}
+	req, err := m.newRequest(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelResp MultiOrderResponse
+	if err := m.doWith(m.orderClient, req, &cancelResp); err != nil {
+		return nil, err
+	}
+
+	return &cancelResp, nil
+}
+
+// dryRunCancelAll reports the open orders CancelAllOrders would cancel.
+func (m *Manager) dryRunCancelAll(ctx context.Context, q url.Values) (*MultiOrderResponse, error) {
+	orders, err := m.GetOrderBook(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	resp := &MultiOrderResponse{Status: "success"}
+	for _, o := range orders {
+		switch o.Status {
+		case "complete", "cancelled", "rejected":
+			continue
+		}
+		if seg := q.Get("segment"); seg != "" && !strings.HasPrefix(o.InstrumentToken, seg+"|") {
+			continue
+		}
+		if tag := q.Get("tag"); tag != "" && o.Tag != tag {
+			continue
+		}
+		m.logger.Info("dry run: would cancel order", "order_id", o.OrderID)
+		resp.Data.OrderIDs = append(resp.Data.OrderIDs, o.OrderID)
+	}
+	resp.Summary.Total = len(resp.Data.OrderIDs)
+	resp.Summary.Success = len(resp.Data.OrderIDs)
+	return resp, nil
+}
+
+func dryRunOrderID(orderID string) *OrderIDResponse {
+	resp := &OrderIDResponse{Status: "success"}
+	resp.Data.OrderID = orderID
+	return resp
+}
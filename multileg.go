@@ -0,0 +1,199 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrLegFailed = errors.New("multi-leg execution failed")
+
+// LegFailureAction decides what ExecuteLegs does with the legs already on the
+// book when a later leg fails.
+type LegFailureAction int
+
+const (
+	// LegFailureRollback cancels what is still open and squares off every
+	// filled quantity, returning the book to where it started.
+	LegFailureRollback LegFailureAction = iota
+	// LegFailureHedge squares off only filled SELL legs, whose risk is open
+	// ended, and keeps BUY legs, whose risk is capped at what was paid.
+	LegFailureHedge
+	// LegFailureLeave leaves everything as it is and only reports it.
+	LegFailureLeave
+)
+
+type MultiLegOptions struct {
+	OnFailure LegFailureAction
+
+	// OnAlert, if set, is called with the final report whenever a leg fails,
+	// after the failure action has run.
+	OnAlert func(*ExecutionReport)
+}
+
+type LegResult struct {
+	Request        OrderRequest
+	OrderID        string
+	Status         string
+	FilledQuantity int
+	AveragePrice   float64
+	Err            error
+
+	// RollbackOrderID is the exit order placed for this leg, if any.
+	RollbackOrderID string
+	RollbackErr     error
+}
+
+// ExecutionReport describes what ExecuteLegs left on the book.
+type ExecutionReport struct {
+	Legs []LegResult
+
+	// FailedLeg is the index of the leg that failed, or -1.
+	FailedLeg int
+	Action    LegFailureAction
+}
+
+// OpenLegs returns the legs that still carry filled quantity after any
+// rollback, i.e. what actually ended up on the book.
+func (r *ExecutionReport) OpenLegs() []LegResult {
+	var out []LegResult
+	for _, leg := range r.Legs {
+		if leg.FilledQuantity > 0 && (leg.RollbackOrderID == "" || leg.RollbackErr != nil) {
+			out = append(out, leg)
+		}
+	}
+	return out
+}
+
+// ExecuteLegs places legs in order, stopping at the first leg that errors or
+// is rejected, and then applies opts.OnFailure to the legs placed before it.
+// The returned error wraps ErrLegFailed; the report is always returned.
+func (m *Manager) ExecuteLegs(ctx context.Context, legs []OrderRequest, opts MultiLegOptions) (*ExecutionReport, error) {
+	for i, leg := range legs {
+		if err := validateOrderRequest(leg); err != nil {
+			return nil, fmt.Errorf("invalid leg %d: %w", i, err)
+		}
+	}
+
+	report := &ExecutionReport{FailedLeg: -1, Action: opts.OnFailure}
+	for i, leg := range legs {
+		result := m.placeLeg(ctx, leg)
+		report.Legs = append(report.Legs, result)
+		if result.Err != nil {
+			report.FailedLeg = i
+			break
+		}
+	}
+
+	if report.FailedLeg < 0 {
+		return report, nil
+	}
+
+	switch opts.OnFailure {
+	case LegFailureRollback:
+		m.unwindLegs(ctx, report, func(LegResult) bool { return true })
+	case LegFailureHedge:
+		m.unwindLegs(ctx, report, func(leg LegResult) bool {
+			return leg.Request.TransactionType == string(OrderSideSell)
+		})
+	}
+
+	if opts.OnAlert != nil {
+		opts.OnAlert(report)
+	}
+
+	failed := report.Legs[report.FailedLeg]
+	return report, fmt.Errorf("%w: leg %d (%s %s): %v", ErrLegFailed, report.FailedLeg,
+		failed.Request.TransactionType, failed.Request.InstrumentToken, failed.Err)
+}
+
+func (m *Manager) placeLeg(ctx context.Context, leg OrderRequest) LegResult {
+	result := LegResult{Request: leg}
+
+	resp, err := m.placeOrder(ctx, leg)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if resp.Data != nil && len(resp.Data.OrderIDs) > 0 {
+		result.OrderID = resp.Data.OrderIDs[0]
+	}
+
+	if resp.DryRun {
+		result.Status = "complete"
+		result.FilledQuantity = leg.Quantity
+		return result
+	}
+
+	m.confirmLeg(ctx, &result)
+	return result
+}
+
+// confirmLeg looks up result's order and records its status, failing the leg
+// if the order was rejected or cancelled. It returns the order, or nil if the
+// lookup failed.
+func (m *Manager) confirmLeg(ctx context.Context, result *LegResult) *Order {
+	order, err := m.GetOrderDetails(ctx, result.OrderID)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to confirm order %s: %w", result.OrderID, err)
+		return nil
+	}
+	result.Status = order.Status
+	result.FilledQuantity = order.FilledQuantity
+	result.AveragePrice = order.AveragePrice
+
+	if order.Status == "rejected" || order.Status == "cancelled" {
+		result.Err = fmt.Errorf("order %s %s: %s", result.OrderID, order.Status, order.StatusMessage)
+	}
+	return order
+}
+
+// unwindLegs cancels the open remainder and squares off the filled quantity of
+// every leg before the failed one that match selects.
+func (m *Manager) unwindLegs(ctx context.Context, report *ExecutionReport, selects func(LegResult) bool) {
+	for i := 0; i < report.FailedLeg; i++ {
+		leg := &report.Legs[i]
+		if !selects(*leg) {
+			continue
+		}
+
+		if leg.Status != "complete" && leg.OrderID != "" && !strings.HasPrefix(leg.OrderID, dryRunOrderPrefix) {
+			_, cancelErr := m.CancelOrder(ctx, leg.OrderID)
+			// Whatever filled before the cancel landed is what needs exiting.
+			if order, err := m.GetOrderDetails(ctx, leg.OrderID); err == nil {
+				leg.Status = order.Status
+				leg.FilledQuantity = order.FilledQuantity
+				leg.AveragePrice = order.AveragePrice
+			}
+			if cancelErr != nil && leg.Status != "complete" && leg.Status != "cancelled" {
+				leg.RollbackErr = fmt.Errorf("failed to cancel order %s: %w", leg.OrderID, cancelErr)
+				continue
+			}
+		}
+
+		if leg.FilledQuantity == 0 {
+			continue
+		}
+
+		side := string(OrderSideBuy)
+		if leg.Request.TransactionType == string(OrderSideBuy) {
+			side = string(OrderSideSell)
+		}
+		exit := marketOrderRequest(leg.Request.InstrumentToken, leg.FilledQuantity, side)
+		exit.Product = leg.Request.Product
+		exit.Force = true
+
+		resp, err := m.submitOrder(ctx, exit)
+		if err != nil {
+			leg.RollbackErr = fmt.Errorf("failed to square off leg %d: %w", i, err)
+			continue
+		}
+		if resp.Data != nil && len(resp.Data.OrderIDs) > 0 {
+			leg.RollbackOrderID = resp.Data.OrderIDs[0]
+		}
+		if resp.Status != "success" {
+			leg.RollbackErr = fmt.Errorf("square-off of leg %d was rejected", i)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// OCOResult is how an OCOGroup ended. Filled is the order that executed, or
+// nil if every order was cancelled or rejected without filling.
+type OCOResult struct {
+	Filled    *Order
+	Cancelled []string
+}
+
+// OCOGroup links pending orders so that when one fills the rest are
+// cancelled. A partial fill of one shrinks the others to the quantity it
+// still has open; an order cancelled or rejected outside the group simply
+// leaves it. Order changes come from an OrderTracker, which must be started
+// (or fed updates) for the group to act.
+type OCOGroup struct {
+	m      *Manager
+	ctx    context.Context
+	remove func()
+
+	mu      sync.Mutex
+	pending []string
+	result  OCOResult
+	done    chan struct{}
+	onDone  func(OCOResult)
+}
+
+// NewOCOGroup links orderIDs, at least two. ctx bounds the cancels and
+// modifications the group makes.
+func (m *Manager) NewOCOGroup(ctx context.Context, tracker *OrderTracker, orderIDs ...string) (*OCOGroup, error) {
+	if len(orderIDs) < 2 {
+		return nil, fmt.Errorf("an OCO group needs at least two orders, got %d", len(orderIDs))
+	}
+	g := &OCOGroup{
+		m:       m,
+		ctx:     ctx,
+		pending: slices.Clone(orderIDs),
+		done:    make(chan struct{}),
+	}
+	g.remove = tracker.OnEvent(g.onEvent)
+	tracker.Watch(orderIDs...)
+	return g, nil
+}
+
+// OnDone registers fn to run once the group has ended.
+func (g *OCOGroup) OnDone(fn func(OCOResult)) {
+	g.mu.Lock()
+	select {
+	case <-g.done:
+		result := g.result
+		g.mu.Unlock()
+		fn(result)
+		return
+	default:
+	}
+	g.onDone = fn
+	g.mu.Unlock()
+}
+
+// Done is closed once the group has ended.
+func (g *OCOGroup) Done() <-chan struct{} {
+	return g.done
+}
+
+// Result returns the outcome once Done is closed.
+func (g *OCOGroup) Result() OCOResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.result
+}
+
+// Pending returns the orders still working.
+func (g *OCOGroup) Pending() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.pending)
+}
+
+// Cancel cancels every order still working. The group ends, with no order
+// filled, once the tracker reports the cancellations.
+func (g *OCOGroup) Cancel(ctx context.Context) error {
+	g.mu.Lock()
+	pending := g.pending
+	g.mu.Unlock()
+
+	for _, orderID := range pending {
+		if _, err := g.m.CancelOrder(ctx, orderID); err != nil {
+			return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+		}
+	}
+	return nil
+}
+
+func (g *OCOGroup) onEvent(ev OrderEvent) {
+	g.mu.Lock()
+	if !slices.Contains(g.pending, ev.OrderID) {
+		g.mu.Unlock()
+		return
+	}
+	if ev.Type.Final() {
+		g.pending = slices.DeleteFunc(g.pending, func(id string) bool { return id == ev.OrderID })
+	}
+	others := slices.Clone(g.pending)
+	g.mu.Unlock()
+
+	switch ev.Type {
+	case OrderPartiallyFilled:
+		g.shrink(others, ev.Order.Quantity-ev.Order.FilledQuantity)
+	case OrderFilled:
+		for _, orderID := range others {
+			if _, err := g.m.CancelOrder(g.ctx, orderID); err != nil {
+				g.m.logger.Warn("oco: failed to cancel linked order", "order_id", orderID, "error", err)
+			}
+		}
+		g.finish(ev.Order, others)
+	case OrderCancelled, OrderRejected:
+		if ev.Order.FilledQuantity > 0 {
+			g.shrink(others, ev.Order.Quantity-ev.Order.FilledQuantity)
+		}
+		if len(others) == 0 {
+			g.finish(nil, nil)
+		}
+	}
+}
+
+// shrink reduces the other orders to the quantity the filling one still has
+// open.
+func (g *OCOGroup) shrink(orderIDs []string, remaining int) {
+	if remaining <= 0 {
+		return
+	}
+	for _, orderID := range orderIDs {
+		order, err := g.m.GetOrderDetails(g.ctx, orderID)
+		if err != nil {
+			g.m.logger.Warn("oco: failed to look up linked order", "order_id", orderID, "error", err)
+			continue
+		}
+		if order.Quantity <= remaining {
+			continue
+		}
+		modReq := modifyFromOrder(order)
+		modReq.Quantity = remaining
+		if _, err := g.m.modifyOrder(g.ctx, order, modReq); err != nil {
+			g.m.logger.Warn("oco: failed to resize linked order", "order_id", orderID, "error", err)
+		}
+	}
+}
+
+func (g *OCOGroup) finish(filled *Order, cancelled []string) {
+	g.remove()
+
+	g.mu.Lock()
+	select {
+	case <-g.done:
+		g.mu.Unlock()
+		return
+	default:
+	}
+	g.result = OCOResult{Filled: filled, Cancelled: cancelled}
+	g.pending = nil
+	close(g.done)
+	fn, result := g.onDone, g.result
+	g.mu.Unlock()
+
+	if fn != nil {
+		fn(result)
+	}
+}
@@ -0,0 +1,89 @@
+package upstox
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// newTestOCO links a paper target and stop for a long of 10, with a tracker
+// the test feeds by hand.
+func newTestOCO(t *testing.T) (*Manager, *OrderTracker, *OCOGroup, string, string) {
+	ctx := context.Background()
+	m := NewPaperManager("id", "secret", "token")
+	key := "NSE_EQ|X"
+	m.Paper().OnTick(paperTick(key, 100))
+
+	target, err := m.PlaceLimitOrder(ctx, key, 10, "SELL", 110)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopReq := marketOrderRequest(key, 10, "SELL")
+	stopReq.OrderType = string(OrderTypeSLM)
+	stopReq.TriggerPrice = 90
+	stop, err := m.PlaceOrder(ctx, stopReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := m.NewOrderTracker(OrderTrackerConfig{})
+	targetID, stopID := target.Data.OrderIDs[0], stop.Data.OrderIDs[0]
+	g, err := m.NewOCOGroup(ctx, tr, targetID, stopID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, tr, g, targetID, stopID
+}
+
+func TestOCOFillCancelsTheRest(t *testing.T) {
+	m, tr, g, targetID, stopID := newTestOCO(t)
+
+	tr.Update(&Order{OrderID: targetID, Status: "complete", Quantity: 10, FilledQuantity: 10, AveragePrice: 110})
+	select {
+	case <-g.Done():
+	default:
+		t.Fatal("group still running after a fill")
+	}
+	result := g.Result()
+	if result.Filled == nil || result.Filled.OrderID != targetID || !slices.Equal(result.Cancelled, []string{stopID}) {
+		t.Errorf("result = %+v", result)
+	}
+	if order, _ := m.GetOrderDetails(context.Background(), stopID); order.Status != "cancelled" {
+		t.Errorf("linked stop is %s", order.Status)
+	}
+}
+
+func TestOCOPartialFillShrinksTheRest(t *testing.T) {
+	m, tr, g, targetID, stopID := newTestOCO(t)
+
+	tr.Update(&Order{OrderID: targetID, Status: "open", Quantity: 10, FilledQuantity: 4, AveragePrice: 110})
+	if order, _ := m.GetOrderDetails(context.Background(), stopID); order.Quantity != 6 {
+		t.Errorf("linked stop quantity = %d, want 6", order.Quantity)
+	}
+	if got := g.Pending(); len(got) != 2 {
+		t.Errorf("pending = %v after a partial fill", got)
+	}
+}
+
+func TestOCOEndsWhenEverythingIsCancelled(t *testing.T) {
+	_, tr, g, targetID, stopID := newTestOCO(t)
+
+	var results []OCOResult
+	g.OnDone(func(r OCOResult) { results = append(results, r) })
+
+	tr.Update(&Order{OrderID: stopID, Status: "cancelled", Quantity: 10})
+	if got := g.Pending(); !slices.Equal(got, []string{targetID}) {
+		t.Fatalf("pending = %v", got)
+	}
+	tr.Update(&Order{OrderID: targetID, Status: "rejected", Quantity: 10})
+	if len(results) != 1 || results[0].Filled != nil {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestOCONeedsTwoOrders(t *testing.T) {
+	m := NewPaperManager("id", "secret", "token")
+	if _, err := m.NewOCOGroup(context.Background(), m.NewOrderTracker(OrderTrackerConfig{}), "1"); err == nil {
+		t.Error("linked a single order")
+	}
+}
@@ -0,0 +1,11 @@
+package upstox
+
+type ManagerOption func(*Manager)
+
+// WithDryRun makes every order method validate the request, price its margin
+// and charges and log it, without ever sending it to the exchange.
+func WithDryRun() ManagerOption {
+	return func(m *Manager) {
+		m.dryRun = true
+	}
+}
@@ -0,0 +1,225 @@
+package upstox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// roundTripFunc stands in for the API in tests.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func jsonResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// apiStub counts requests and answers each with respond.
+type apiStub struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	respond  func(req *http.Request, n int) (*http.Response, error)
+}
+
+func (s *apiStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.bodies = append(s.bodies, body)
+	n := len(s.requests)
+	s.mu.Unlock()
+	return s.respond(req, n)
+}
+
+func (s *apiStub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func (s *apiStub) countMethod(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, req := range s.requests {
+		if req.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestValidateOrderRequest(t *testing.T) {
+	valid := OrderRequest{
+		Quantity:        1,
+		Product:         string(ProductIntraday),
+		Validity:        string(ValidityDay),
+		InstrumentToken: "NSE_EQ|INE062A01020",
+		OrderType:       string(OrderTypeLimit),
+		TransactionType: string(OrderSideBuy),
+		Price:           100,
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(*OrderRequest)
+		wantErr string
+	}{
+		{"valid", func(*OrderRequest) {}, ""},
+		{"no instrument", func(r *OrderRequest) { r.InstrumentToken = "" }, "instrument token is required"},
+		{"zero quantity", func(r *OrderRequest) { r.Quantity = 0 }, "quantity must be positive"},
+		{"bad side", func(r *OrderRequest) { r.TransactionType = "HOLD" }, "invalid transaction type"},
+		{"disclosed above quantity", func(r *OrderRequest) { r.DisclosedQuantity = 2 }, "disclosed quantity"},
+		{"market with price", func(r *OrderRequest) { r.OrderType = string(OrderTypeMarket) }, "must not carry a price"},
+		{"limit without price", func(r *OrderRequest) { r.Price = 0 }, "positive price"},
+		{"SL without trigger", func(r *OrderRequest) { r.OrderType = string(OrderTypeSL) }, "both price and trigger"},
+		{"SL-M without trigger", func(r *OrderRequest) { r.OrderType = string(OrderTypeSLM); r.Price = 0 }, "trigger price"},
+		{"bad product", func(r *OrderRequest) { r.Product = "X" }, "invalid product"},
+		{"bad validity", func(r *OrderRequest) { r.Validity = "GTC" }, "invalid validity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.edit(&req)
+			err := validateOrderRequest(req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderSendsRequest(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		// v2 returns a single order_id.
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_id":"2501"}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), WithEndpointVersion(EndpointPlaceOrder, V2))
+
+	var placed []string
+	m.OnOrderPlaced(func(req OrderRequest, resp *OrderResponse) {
+		placed = append(placed, resp.Data.OrderIDs[0])
+	})
+
+	resp, err := m.PlaceLimitOrder(context.Background(), "NSE_EQ|INE062A01020", 5, "BUY", 812.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Data.OrderIDs; len(got) != 1 || got[0] != "2501" {
+		t.Fatalf("OrderIDs = %v", got)
+	}
+	if len(placed) != 1 {
+		t.Fatalf("placed hook ran %d times", len(placed))
+	}
+
+	req := stub.requests[0]
+	if req.Method != "POST" || req.URL.String() != "https://"+orderHost+"/v2/order/place" {
+		t.Errorf("request = %s %s", req.Method, req.URL)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	var sent OrderRequest
+	if err := json.Unmarshal([]byte(stub.bodies[0]), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Quantity != 5 || sent.Price != 812.5 || sent.OrderType != "LIMIT" || sent.TransactionType != "BUY" {
+		t.Errorf("sent %+v", sent)
+	}
+}
+
+func TestPlaceOrderAPIError(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusBadRequest,
+			`{"status":"error","errors":[{"error_code":"UDAPI100049","message":"Insufficient funds"}]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+
+	var reasons []RejectionReason
+	m.OnOrderRejected(func(req OrderRequest, reason RejectionReason, message string) {
+		reasons = append(reasons, reason)
+	})
+
+	_, err := m.PlaceMarketOrder(context.Background(), "NSE_EQ|INE062A01020", 1, "BUY")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Message() != "Insufficient funds" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if len(reasons) != 1 || reasons[0] != RejectionInsufficientMargin {
+		t.Errorf("rejection reasons = %v", reasons)
+	}
+}
+
+func TestPlaceOrderLocalChecks(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_ids":["1"]}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), WithDuplicateWindow(time.Minute))
+	ctx := context.Background()
+
+	if _, err := m.PlaceMarketOrder(ctx, "NSE_EQ|X", 0, "BUY"); err == nil {
+		t.Error("zero quantity order was placed")
+	}
+	if _, err := m.PlaceMarketOrder(ctx, "NSE_EQ|X", 1, "BUY"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.PlaceMarketOrder(ctx, "NSE_EQ|X", 1, "BUY"); !errors.Is(err, ErrDuplicateOrder) {
+		t.Errorf("repeat order error = %v, want ErrDuplicateOrder", err)
+	}
+	if _, err := m.ForcePlaceMarketOrder(ctx, "NSE_EQ|X", 1, "BUY"); err != nil {
+		t.Errorf("forced repeat: %v", err)
+	}
+
+	m.Halt("test")
+	if _, err := m.PlaceMarketOrder(ctx, "NSE_EQ|Y", 1, "BUY"); !errors.Is(err, ErrTradingHalted) {
+		t.Errorf("halted order error = %v, want ErrTradingHalted", err)
+	}
+	m.Resume()
+
+	if got := stub.countMethod("POST"); got != 2 {
+		t.Errorf("API saw %d orders, want 2", got)
+	}
+}
+
+func TestGetOrderDetailsEscapesID(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, _ int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_id":"`+req.URL.Query().Get("order_id")+`"}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+
+	order, err := m.GetOrderDetails(context.Background(), "1&x=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.OrderID != "1&x=2" {
+		t.Errorf("order ID = %q", order.OrderID)
+	}
+}
@@ -0,0 +1,379 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const paperOrderPrefix = "PAPER-"
+
+// PaperConfig tunes simulated execution.
+type PaperConfig struct {
+	// SlippagePct worsens market and stop-market fills by this percentage of
+	// the last price.
+	SlippagePct float64
+}
+
+// WithPaperTrading simulates order placement, modification and cancellation
+// locally. Orders fill against the last traded price from the feeds this
+// Manager creates (or ticks passed to the PaperBroker directly); the order
+// book, trades and positions reads are answered from memory. Market data
+// calls still go to the API.
+func WithPaperTrading(cfg PaperConfig) ManagerOption {
+	return func(m *Manager) {
+		m.paper = newPaperBroker(m, cfg)
+	}
+}
+
+// NewPaperManager is NewManager with WithPaperTrading and default settings.
+func NewPaperManager(clientID, clientSecret, accessToken string, opts ...ManagerOption) *Manager {
+	return NewManager(clientID, clientSecret, accessToken, append(opts, WithPaperTrading(PaperConfig{}))...)
+}
+
+// Paper returns the simulated broker, or nil outside paper trading.
+func (m *Manager) Paper() *PaperBroker {
+	return m.paper
+}
+
+// PaperBroker is the in-memory exchange behind a paper-trading Manager.
+// Orders fill whole: market orders at the next known price, limit orders
+// once the price reaches the limit, stop orders once the trigger trades.
+type PaperBroker struct {
+	m         *Manager
+	cfg       PaperConfig
+	positions *PositionTracker
+
+	mu     sync.Mutex
+	seq    int
+	prices map[string]float64
+	orders map[string]*Order
+	order  []string
+	open   []*Order
+	trades map[string][]Trade
+}
+
+func newPaperBroker(m *Manager, cfg PaperConfig) *PaperBroker {
+	return &PaperBroker{
+		m:         m,
+		cfg:       cfg,
+		positions: m.NewPositionTracker(),
+		prices:    make(map[string]float64),
+		orders:    make(map[string]*Order),
+		trades:    make(map[string][]Trade),
+	}
+}
+
+// Positions returns the tracker holding simulated positions, for snapshots
+// and P&L thresholds.
+func (p *PaperBroker) Positions() *PositionTracker {
+	return p.positions
+}
+
+// Attach feeds wsm's ticks to the broker. Feeds created by the Manager are
+// attached automatically.
+func (p *PaperBroker) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(p.OnTick)
+}
+
+// OnTick records the price and fills any orders it crosses.
+func (p *PaperBroker) OnTick(tick Tick) {
+	if tick.LTP <= 0 {
+		return
+	}
+	p.mu.Lock()
+	p.prices[tick.InstrumentKey] = tick.LTP
+	var filled []*Order
+	for _, o := range p.open {
+		if o.InstrumentToken == tick.InstrumentKey && p.tryFillLocked(o) {
+			filled = append(filled, o)
+		}
+	}
+	p.open = slices.DeleteFunc(p.open, func(o *Order) bool { return !isWorking(o) })
+	p.mu.Unlock()
+
+	p.positions.OnTick(tick)
+	p.settle(filled)
+}
+
+// place books orderReq and fills it at once if the last price allows. It
+// runs the placed hooks itself so that they precede any fill hooks.
+func (p *PaperBroker) place(orderReq OrderRequest) *OrderResponse {
+	now := time.Now()
+
+	p.mu.Lock()
+	p.seq++
+	o := &Order{
+		OrderID:           paperOrderPrefix + strconv.Itoa(p.seq),
+		Exchange:          strings.SplitN(orderReq.InstrumentToken, "_", 2)[0],
+		Product:           orderReq.Product,
+		Price:             orderReq.Price,
+		Quantity:          orderReq.Quantity,
+		Status:            "open",
+		Tag:               orderReq.Tag,
+		InstrumentToken:   orderReq.InstrumentToken,
+		OrderType:         orderReq.OrderType,
+		Validity:          orderReq.Validity,
+		TriggerPrice:      orderReq.TriggerPrice,
+		DisclosedQuantity: orderReq.DisclosedQuantity,
+		TransactionType:   orderReq.TransactionType,
+		PendingQuantity:   orderReq.Quantity,
+		IsAMO:             orderReq.IsAMO,
+		OrderTimestamp:    now.Format(time.DateTime),
+	}
+	if isStopOrder(o.OrderType) {
+		o.Status = "trigger pending"
+	}
+	p.orders[o.OrderID] = o
+	p.order = append(p.order, o.OrderID)
+
+	var filled []*Order
+	if p.tryFillLocked(o) {
+		filled = append(filled, o)
+	} else if o.Validity == string(ValidityIOC) {
+		p.cancelLocked(o, "IOC order cancelled: not immediately fillable")
+	} else {
+		p.open = append(p.open, o)
+	}
+	p.mu.Unlock()
+
+	resp := &OrderResponse{
+		Status: "success",
+		Data:   &OrderResponseData{OrderIDs: []string{o.OrderID}},
+	}
+	p.m.firePlaced(orderReq, resp)
+	p.settle(filled)
+	return resp
+}
+
+// tryFillLocked fills o in full if the last price allows it.
+func (p *PaperBroker) tryFillLocked(o *Order) bool {
+	if !isWorking(o) {
+		return false
+	}
+	ltp, ok := p.prices[o.InstrumentToken]
+	if !ok {
+		return false
+	}
+	buy := strings.EqualFold(o.TransactionType, string(OrderSideBuy))
+
+	if o.Status == "trigger pending" {
+		if (buy && ltp < o.TriggerPrice) || (!buy && ltp > o.TriggerPrice) {
+			return false
+		}
+		o.Status = "open"
+	}
+
+	var price float64
+	switch o.OrderType {
+	case string(OrderTypeMarket), string(OrderTypeSLM):
+		slip := ltp * p.cfg.SlippagePct / 100
+		if buy {
+			price = ltp + slip
+		} else {
+			price = ltp - slip
+		}
+	default:
+		if (buy && ltp > o.Price) || (!buy && ltp < o.Price) {
+			return false
+		}
+		price = ltp
+	}
+
+	o.Status = "complete"
+	o.AveragePrice = price
+	o.FilledQuantity = o.Quantity
+	o.PendingQuantity = 0
+	o.ExchangeTimestamp = time.Now().Format(time.DateTime)
+
+	trade := Trade{
+		Exchange:          o.Exchange,
+		Product:           o.Product,
+		InstrumentToken:   o.InstrumentToken,
+		OrderType:         o.OrderType,
+		TransactionType:   o.TransactionType,
+		Quantity:          o.Quantity,
+		OrderID:           o.OrderID,
+		ExchangeTimestamp: o.ExchangeTimestamp,
+		AveragePrice:      price,
+		TradeID:           o.OrderID + "-1",
+		OrderTimestamp:    o.OrderTimestamp,
+	}
+	p.trades[o.OrderID] = append(p.trades[o.OrderID], trade)
+	return true
+}
+
+// settle books fills into positions and runs the fill hooks. Call without
+// the lock held.
+func (p *PaperBroker) settle(filled []*Order) {
+	for _, o := range filled {
+		p.mu.Lock()
+		trades := slices.Clone(p.trades[o.OrderID])
+		order := *o
+		p.mu.Unlock()
+
+		for _, t := range trades {
+			p.positions.ApplyTrade(t)
+		}
+		p.m.fireFilled(&order)
+	}
+}
+
+func (p *PaperBroker) modify(modReq ModifyOrderRequest) (*OrderIDResponse, error) {
+	p.mu.Lock()
+	o, ok := p.orders[modReq.OrderID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, modReq.OrderID)
+	}
+	if !isWorking(o) {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: order %s is %s", ErrNotModifiable, o.OrderID, o.Status)
+	}
+	o.Quantity, o.PendingQuantity = modReq.Quantity, modReq.Quantity
+	o.Validity = modReq.Validity
+	o.Price = modReq.Price
+	o.OrderType = modReq.OrderType
+	o.DisclosedQuantity = modReq.DisclosedQuantity
+	o.TriggerPrice = modReq.TriggerPrice
+	if o.Status == "open" && isStopOrder(o.OrderType) {
+		o.Status = "trigger pending"
+	}
+
+	var filled []*Order
+	if p.tryFillLocked(o) {
+		filled = append(filled, o)
+	}
+	p.mu.Unlock()
+
+	p.settle(filled)
+	return dryRunOrderID(modReq.OrderID), nil
+}
+
+func (p *PaperBroker) cancel(orderID string) (*OrderIDResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if !isWorking(o) {
+		return nil, fmt.Errorf("%w: order %s is %s", ErrNotModifiable, orderID, o.Status)
+	}
+	p.cancelLocked(o, "cancelled by user")
+	return dryRunOrderID(orderID), nil
+}
+
+func (p *PaperBroker) cancelLocked(o *Order, message string) {
+	o.Status = "cancelled"
+	o.StatusMessage = message
+	o.PendingQuantity = 0
+}
+
+// cancelAll applies the same segment and tag filters as CancelAllOrders.
+func (p *PaperBroker) cancelAll(q url.Values) *MultiOrderResponse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resp := &MultiOrderResponse{Status: "success"}
+	for _, id := range p.order {
+		o := p.orders[id]
+		if !isWorking(o) {
+			continue
+		}
+		if seg := q.Get("segment"); seg != "" && !strings.HasPrefix(o.InstrumentToken, seg+"|") {
+			continue
+		}
+		if tag := q.Get("tag"); tag != "" && o.Tag != tag {
+			continue
+		}
+		p.cancelLocked(o, "cancelled by user")
+		resp.Data.OrderIDs = append(resp.Data.OrderIDs, id)
+	}
+	resp.Summary.Total = len(resp.Data.OrderIDs)
+	resp.Summary.Success = len(resp.Data.OrderIDs)
+	return resp
+}
+
+func (p *PaperBroker) orderBook() []Order {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	orders := make([]Order, 0, len(p.order))
+	for _, id := range p.order {
+		orders = append(orders, *p.orders[id])
+	}
+	return orders
+}
+
+func (p *PaperBroker) orderDetails(orderID string) (*Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	order := *o
+	return &order, nil
+}
+
+func (p *PaperBroker) tradesFor(orderID string) []Trade {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.trades[orderID])
+}
+
+// positionList renders simulated positions in the API's shape.
+func (p *PaperBroker) positionList() []Position {
+	snap := p.positions.GetSnapshot()
+	positions := make([]Position, 0, len(snap.Positions))
+	for _, pp := range snap.Positions {
+		positions = append(positions, Position{
+			InstrumentToken: pp.InstrumentKey,
+			TradingSymbol:   pp.TradingSymbol,
+			Product:         pp.Product,
+			Quantity:        pp.Quantity,
+			AveragePrice:    pp.AveragePrice,
+			LastPrice:       pp.LastPrice,
+			Multiplier:      pp.Multiplier,
+			Realised:        pp.Realised,
+			Unrealised:      pp.Unrealised,
+			PNL:             pp.Total(),
+		})
+	}
+	return positions
+}
+
+func isStopOrder(orderType string) bool {
+	return orderType == string(OrderTypeSL) || orderType == string(OrderTypeSLM)
+}
+
+// paperCloseAll flattens every simulated position with market orders.
+func (m *Manager) paperCloseAll(ctx context.Context) ([]OrderResponse, error) {
+	var responses []OrderResponse
+	for _, pos := range m.paper.positionList() {
+		if pos.Quantity == 0 {
+			continue
+		}
+		side, qty := string(OrderSideSell), pos.Quantity
+		if qty < 0 {
+			side, qty = string(OrderSideBuy), -qty
+		}
+		exit := marketOrderRequest(pos.InstrumentToken, qty, side)
+		exit.Product = pos.Product
+		resp, err := m.submitOrder(ctx, exit)
+		if err != nil {
+			return responses, fmt.Errorf("failed to close %s: %w", pos.InstrumentToken, err)
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+func isWorking(o *Order) bool {
+	return o.Status == "open" || o.Status == "trigger pending"
+}
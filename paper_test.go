@@ -0,0 +1,175 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func paperTick(key string, ltp float64) Tick {
+	now := time.Now()
+	return Tick{InstrumentKey: key, LTP: ltp, LTT: now.UnixMilli(), ReceivedAt: now}
+}
+
+func TestPaperMarketOrderFills(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager("id", "secret", "token", WithPaperTrading(PaperConfig{SlippagePct: 1}))
+	key := "NSE_EQ|INE062A01020"
+	m.Paper().OnTick(paperTick(key, 200))
+
+	var filled []*Order
+	m.OnOrderFilled(func(o *Order) { filled = append(filled, o) })
+
+	resp, err := m.PlaceMarketOrder(ctx, key, 10, "BUY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := m.GetOrderDetails(ctx, resp.Data.OrderIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "complete" || order.AveragePrice != 202 || order.FilledQuantity != 10 {
+		t.Fatalf("order = %+v", order)
+	}
+	if len(filled) != 1 {
+		t.Errorf("fill hook ran %d times", len(filled))
+	}
+
+	positions, err := m.GetPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0].Quantity != 10 || positions[0].AveragePrice != 202 {
+		t.Fatalf("positions = %+v", positions)
+	}
+	trades, err := m.GetTradesForOrder(ctx, order.OrderID)
+	if err != nil || len(trades) != 1 || trades[0].Quantity != 10 {
+		t.Fatalf("trades = %+v, %v", trades, err)
+	}
+}
+
+func TestPaperLimitAndStopOrders(t *testing.T) {
+	ctx := context.Background()
+	m := NewPaperManager("id", "secret", "token")
+	key := "NSE_EQ|X"
+	p := m.Paper()
+	p.OnTick(paperTick(key, 100))
+
+	limit, err := m.PlaceLimitOrder(ctx, key, 5, "BUY", 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop, err := m.PlaceOrder(ctx, OrderRequest{
+		Quantity:        5,
+		Product:         string(ProductIntraday),
+		Validity:        string(ValidityDay),
+		InstrumentToken: key,
+		OrderType:       string(OrderTypeSLM),
+		TransactionType: "SELL",
+		TriggerPrice:    90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := func(resp *OrderResponse) string {
+		o, err := m.GetOrderDetails(ctx, resp.Data.OrderIDs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o.Status
+	}
+	if s := status(limit); s != "open" {
+		t.Fatalf("limit order %s before the price reached it", s)
+	}
+	if s := status(stop); s != "trigger pending" {
+		t.Fatalf("stop order %s before its trigger", s)
+	}
+
+	p.OnTick(paperTick(key, 94))
+	if s := status(limit); s != "complete" {
+		t.Errorf("limit order %s after the price crossed it", s)
+	}
+	if s := status(stop); s != "trigger pending" {
+		t.Errorf("stop order %s above its trigger", s)
+	}
+
+	p.OnTick(paperTick(key, 89))
+	if s := status(stop); s != "complete" {
+		t.Errorf("stop order %s after its trigger traded", s)
+	}
+
+	positions, _ := m.GetPositions(ctx)
+	if len(positions) != 1 || positions[0].Quantity != 0 {
+		t.Errorf("positions = %+v, want flat", positions)
+	}
+}
+
+func TestPaperModifyAndCancel(t *testing.T) {
+	ctx := context.Background()
+	m := NewPaperManager("id", "secret", "token")
+	key := "NSE_EQ|X"
+	m.Paper().OnTick(paperTick(key, 100))
+
+	resp, err := m.PlaceLimitOrder(ctx, key, 5, "SELL", 110)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := resp.Data.OrderIDs[0]
+
+	order, _ := m.GetOrderDetails(ctx, id)
+	mod := modifyFromOrder(order)
+	mod.Price = 105
+	if _, err := m.ModifyOrder(ctx, mod); err != nil {
+		t.Fatal(err)
+	}
+	if order, _ := m.GetOrderDetails(ctx, id); order.Price != 105 {
+		t.Errorf("price after modify = %v", order.Price)
+	}
+
+	if _, err := m.CancelOrder(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if order, _ := m.GetOrderDetails(ctx, id); order.Status != "cancelled" {
+		t.Errorf("status after cancel = %s", order.Status)
+	}
+	if _, err := m.CancelOrder(ctx, id); err == nil {
+		t.Error("cancelled a cancelled order")
+	}
+	if _, err := m.ModifyOrder(ctx, mod); !errors.Is(err, ErrNotModifiable) {
+		t.Errorf("modifying a cancelled order: %v, want ErrNotModifiable", err)
+	}
+
+	m.Paper().OnTick(paperTick(key, 120))
+	if order, _ := m.GetOrderDetails(ctx, id); order.FilledQuantity != 0 {
+		t.Error("cancelled order filled")
+	}
+}
+
+func TestPaperIOCAndHalt(t *testing.T) {
+	ctx := context.Background()
+	m := NewPaperManager("id", "secret", "token")
+	key := "NSE_EQ|X"
+	m.Paper().OnTick(paperTick(key, 100))
+
+	req := marketOrderRequest(key, 1, "BUY")
+	req.OrderType = string(OrderTypeLimit)
+	req.Price = 90
+	req.Validity = string(ValidityIOC)
+	resp, err := m.PlaceOrder(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order, _ := m.GetOrderDetails(ctx, resp.Data.OrderIDs[0]); order.Status != "cancelled" {
+		t.Errorf("unfillable IOC order is %s", order.Status)
+	}
+
+	m.Halt("test")
+	if _, err := m.PlaceMarketOrder(ctx, key, 1, "BUY"); !errors.Is(err, ErrTradingHalted) {
+		t.Errorf("error = %v, want ErrTradingHalted", err)
+	}
+	if orders, _ := m.GetOrderBook(ctx); len(orders) != 1 {
+		t.Errorf("order book has %d orders, want 1", len(orders))
+	}
+}
@@ -0,0 +1,151 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type PartialFillAction int
+
+const (
+	// PartialFillToMarket converts the unfilled remainder to a market order.
+	PartialFillToMarket PartialFillAction = iota
+	// PartialFillReprice moves the limit to the latest price from the
+	// policy's Source, and cancels the remainder if that also stalls.
+	PartialFillReprice
+	// PartialFillCancel cancels the unfilled remainder.
+	PartialFillCancel
+)
+
+func (a PartialFillAction) String() string {
+	switch a {
+	case PartialFillToMarket:
+		return "to_market"
+	case PartialFillReprice:
+		return "reprice"
+	case PartialFillCancel:
+		return "cancel"
+	}
+	return fmt.Sprintf("PartialFillAction(%d)", int(a))
+}
+
+// PartialFillPolicy decides what happens to an order that has filled in part
+// and then stalled for Timeout.
+type PartialFillPolicy struct {
+	Timeout time.Duration
+	Action  PartialFillAction
+
+	// Source prices the remainder for PartialFillReprice.
+	Source PriceSource
+
+	// PollInterval defaults to 500ms.
+	PollInterval time.Duration
+}
+
+// FillResult is the final state of an order handled by ManagePartialFill.
+// The remainder is always amended on the same order, so AveragePrice is the
+// exchange's blended price over every fill.
+type FillResult struct {
+	Order          *Order
+	FilledQuantity int
+	AveragePrice   float64
+
+	// Actions lists what was done to the order, in order; it is empty when
+	// the order completed without intervention.
+	Actions []PartialFillAction
+}
+
+// ManagePartialFill watches orderID until it reaches a final state, applying
+// policy when it stays partially filled for longer than policy.Timeout. The
+// timeout is measured from the last change in filled quantity.
+func (m *Manager) ManagePartialFill(ctx context.Context, orderID string, policy PartialFillPolicy) (*FillResult, error) {
+	if policy.Timeout <= 0 {
+		return nil, fmt.Errorf("partial fill policy requires a positive timeout")
+	}
+	if policy.Action == PartialFillReprice && policy.Source == nil {
+		return nil, fmt.Errorf("reprice policy requires a price source")
+	}
+	interval := policy.PollInterval
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	result := &FillResult{}
+	lastFilled := -1
+	var stalledSince time.Time
+
+	for {
+		order, err := m.GetOrderDetails(ctx, orderID)
+		if err != nil {
+			return result, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		result.Order = order
+		result.FilledQuantity = order.FilledQuantity
+		result.AveragePrice = order.AveragePrice
+
+		switch order.Status {
+		case "complete", "cancelled", "rejected":
+			return result, nil
+		}
+
+		now := time.Now()
+		if order.FilledQuantity != lastFilled {
+			lastFilled = order.FilledQuantity
+			stalledSince = now
+		}
+
+		// Each action is applied once, except that a stalled reprice is
+		// followed by a cancel.
+		canAct := len(result.Actions) == 0 || (len(result.Actions) == 1 && result.Actions[0] == PartialFillReprice)
+		if canAct && order.FilledQuantity > 0 && order.PendingQuantity > 0 && now.Sub(stalledSince) >= policy.Timeout {
+			action := policy.Action
+			if len(result.Actions) > 0 {
+				action = PartialFillCancel
+			}
+			if err := m.applyPartialFillAction(ctx, order, action, policy); err != nil {
+				return result, err
+			}
+			result.Actions = append(result.Actions, action)
+			stalledSince = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) applyPartialFillAction(ctx context.Context, order *Order, action PartialFillAction, policy PartialFillPolicy) error {
+	switch action {
+	case PartialFillToMarket:
+		modReq := modifyFromOrder(order)
+		modReq.OrderType = string(OrderTypeMarket)
+		modReq.Price = 0
+		if _, err := m.modifyOrder(ctx, order, modReq); err != nil {
+			return fmt.Errorf("failed to convert order %s to market: %w", order.OrderID, err)
+		}
+	case PartialFillReprice:
+		price, ok := policy.Source.LastPrice(order.InstrumentToken)
+		if !ok {
+			return fmt.Errorf("no price to reprice order %s", order.OrderID)
+		}
+		modReq := modifyFromOrder(order)
+		modReq.Price = price
+		if _, err := m.modifyOrder(ctx, order, modReq); err != nil {
+			return fmt.Errorf("failed to reprice order %s: %w", order.OrderID, err)
+		}
+	case PartialFillCancel:
+		if _, err := m.CancelOrder(ctx, order.OrderID); err != nil {
+			return fmt.Errorf("failed to cancel order %s: %w", order.OrderID, err)
+		}
+	default:
+		return fmt.Errorf("unknown partial fill action %v", action)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package upstox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderServer serves a single order from the details endpoint. Modifies and
+// cancels are recorded and applied through onModify and onCancel, so a test
+// decides how the order reacts to them.
+type orderServer struct {
+	mu       sync.Mutex
+	order    Order
+	modifies []ModifyOrderRequest
+
+	// onModify returns the error body to send back, or "" to accept.
+	onModify func(o *Order, req ModifyOrderRequest) string
+	onCancel func(o *Order)
+}
+
+func (s *orderServer) stub() *apiStub {
+	api := &apiStub{}
+	api.respond = func(req *http.Request, n int) (*http.Response, error) {
+		api.mu.Lock()
+		body := api.bodies[n-1]
+		api.mu.Unlock()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch req.Method {
+		case "GET":
+			data, _ := json.Marshal(s.order)
+			return jsonResponse(req, http.StatusOK, `{"status":"success","data":`+string(data)+`}`), nil
+		case "PUT":
+			var modReq ModifyOrderRequest
+			if err := json.Unmarshal([]byte(body), &modReq); err != nil {
+				return nil, err
+			}
+			s.modifies = append(s.modifies, modReq)
+			if s.onModify != nil {
+				if errBody := s.onModify(&s.order, modReq); errBody != "" {
+					return jsonResponse(req, http.StatusBadRequest, errBody), nil
+				}
+			}
+			return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_id":"`+s.order.OrderID+`"}}`), nil
+		case "DELETE":
+			if s.onCancel != nil {
+				s.onCancel(&s.order)
+			} else {
+				s.order.Status = "cancelled"
+			}
+			return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_id":"`+s.order.OrderID+`"}}`), nil
+		}
+		return jsonResponse(req, http.StatusNotFound, `{"status":"error"}`), nil
+	}
+	return api
+}
+
+func (s *orderServer) modified() []ModifyOrderRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.modifies)
+}
+
+type fixedPrice float64
+
+func (p fixedPrice) LastPrice(string) (float64, bool) { return float64(p), true }
+
+// partiallyFilled is a buy of 10 at 100 that has filled 4.
+func partiallyFilled() Order {
+	return Order{
+		OrderID:         "1",
+		Status:          "open",
+		InstrumentToken: "NSE_EQ|X",
+		TransactionType: "BUY",
+		OrderType:       "LIMIT",
+		Validity:        "DAY",
+		Price:           100,
+		Quantity:        10,
+		FilledQuantity:  4,
+		PendingQuantity: 6,
+		AveragePrice:    100,
+	}
+}
+
+func testPartialFill(t *testing.T, s *orderServer, policy PartialFillPolicy) *FillResult {
+	t.Helper()
+	m := NewManager("id", "secret", "token", WithTransport(s.stub()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	policy.Timeout = 20 * time.Millisecond
+	policy.PollInterval = 5 * time.Millisecond
+	result, err := m.ManagePartialFill(ctx, "1", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestPartialFillToMarket(t *testing.T) {
+	s := &orderServer{order: partiallyFilled()}
+	s.onModify = func(o *Order, req ModifyOrderRequest) string {
+		o.OrderType = req.OrderType
+		o.Status = "complete"
+		o.FilledQuantity, o.PendingQuantity = 10, 0
+		o.AveragePrice = 100.3
+		return ""
+	}
+
+	result := testPartialFill(t, s, PartialFillPolicy{Action: PartialFillToMarket})
+	if !slices.Equal(result.Actions, []PartialFillAction{PartialFillToMarket}) {
+		t.Errorf("actions = %v", result.Actions)
+	}
+	if result.FilledQuantity != 10 || result.AveragePrice != 100.3 {
+		t.Errorf("result = %+v", result)
+	}
+	if mods := s.modified(); len(mods) != 1 || mods[0].OrderType != "MARKET" || mods[0].Price != 0 || mods[0].Quantity != 10 {
+		t.Errorf("modifies = %+v", mods)
+	}
+}
+
+func TestPartialFillRepriceThenCancel(t *testing.T) {
+	s := &orderServer{order: partiallyFilled()}
+	s.onModify = func(o *Order, req ModifyOrderRequest) string {
+		o.Price = req.Price
+		return ""
+	}
+
+	result := testPartialFill(t, s, PartialFillPolicy{Action: PartialFillReprice, Source: fixedPrice(101)})
+	if want := []PartialFillAction{PartialFillReprice, PartialFillCancel}; !slices.Equal(result.Actions, want) {
+		t.Errorf("actions = %v, want %v", result.Actions, want)
+	}
+	if result.Order.Status != "cancelled" || result.FilledQuantity != 4 {
+		t.Errorf("result = %+v", result)
+	}
+	if mods := s.modified(); len(mods) != 1 || mods[0].Price != 101 {
+		t.Errorf("modifies = %+v", mods)
+	}
+}
+
+func TestPartialFillCompleteUntouched(t *testing.T) {
+	order := partiallyFilled()
+	order.Status = "complete"
+	order.FilledQuantity, order.PendingQuantity = 10, 0
+	s := &orderServer{order: order}
+
+	result := testPartialFill(t, s, PartialFillPolicy{Action: PartialFillCancel})
+	if len(result.Actions) != 0 || result.FilledQuantity != 10 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPartialFillPolicyValidation(t *testing.T) {
+	m := NewManager("id", "secret", "token", WithTransport(&apiStub{}))
+	ctx := context.Background()
+	if _, err := m.ManagePartialFill(ctx, "1", PartialFillPolicy{Action: PartialFillCancel}); err == nil {
+		t.Error("accepted a policy without a timeout")
+	}
+	if _, err := m.ManagePartialFill(ctx, "1", PartialFillPolicy{Timeout: time.Second, Action: PartialFillReprice}); err == nil {
+		t.Error("accepted a reprice policy without a price source")
+	}
+}
@@ -0,0 +1,35 @@
+package upstox
+
+import "sync"
+
+// Ownership rules for pooled objects:
+//
+//   - Tick and Candle are small value types and are always passed by value,
+//     so there is nothing to return for individual updates.
+//   - Tick slices handed to a TickBatchCallback belong to the callback from
+//     the moment it is invoked. They may be kept, or passed to another
+//     goroutine, for as long as needed. Call ReleaseTicks once the batch is
+//     no longer referenced to let the next batch reuse its backing array;
+//     batches that are never released are simply garbage collected.
+//   - Never touch a slice after releasing it, and never release a slice that
+//     did not come from this package.
+var tickSlicePool = sync.Pool{
+	New: func() any {
+		s := make([]Tick, 0, 256)
+		return &s
+	},
+}
+
+func getTickSlice() []Tick {
+	return (*tickSlicePool.Get().(*[]Tick))[:0]
+}
+
+// ReleaseTicks returns a tick batch to the shared pool. See the ownership
+// rules above.
+func ReleaseTicks(ticks []Tick) {
+	if cap(ticks) == 0 {
+		return
+	}
+	ticks = ticks[:0]
+	tickSlicePool.Put(&ticks)
+}
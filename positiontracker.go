@@ -0,0 +1,321 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PositionPnL is one position as seen by a PositionTracker. Quantity is
+// signed, negative for shorts; AveragePrice is that of the open quantity.
+type PositionPnL struct {
+	InstrumentKey string
+	TradingSymbol string
+	Product       string
+	Quantity      int
+	AveragePrice  float64
+	LastPrice     float64
+	Multiplier    float64
+	Realised      float64
+	Unrealised    float64
+}
+
+func (p PositionPnL) Total() float64 {
+	return p.Realised + p.Unrealised
+}
+
+// PortfolioSnapshot is the P&L of every tracked position at one moment.
+// Peak is the highest Total seen since seeding and Drawdown how far Total
+// is below it.
+type PortfolioSnapshot struct {
+	Positions  []PositionPnL
+	Realised   float64
+	Unrealised float64
+	Total      float64
+	Peak       float64
+	Drawdown   float64
+	At         time.Time
+}
+
+// PositionTracker keeps day positions and their P&L current from the feed.
+// It is seeded from GetPositions and marks every position to the latest
+// traded price; fills made afterwards are applied with ApplyTrade, or picked
+// up by calling Seed again. Threshold callbacks run on the goroutine that
+// delivers ticks and must return quickly.
+type PositionTracker struct {
+	m *Manager
+
+	mu         sync.RWMutex
+	positions  []*PositionPnL
+	byKey      map[string][]*PositionPnL
+	peak       float64
+	thresholds []*pnlThreshold
+
+	// realised and unrealised are kept as running sums over positions so a
+	// tick costs only the positions it marks.
+	realised   float64
+	unrealised float64
+}
+
+type pnlThreshold struct {
+	cond      func(PortfolioSnapshot) bool
+	fn        func(PortfolioSnapshot)
+	triggered bool
+}
+
+func (m *Manager) NewPositionTracker() *PositionTracker {
+	return &PositionTracker{m: m, byKey: make(map[string][]*PositionPnL)}
+}
+
+// Seed replaces the tracked positions with the broker's current ones and
+// resets the peak.
+func (t *PositionTracker) Seed(ctx context.Context) error {
+	positions, err := t.m.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.positions = t.positions[:0]
+	t.byKey = make(map[string][]*PositionPnL)
+	for _, pos := range positions {
+		p := &PositionPnL{
+			InstrumentKey: pos.InstrumentToken,
+			TradingSymbol: pos.TradingSymbol,
+			Product:       pos.Product,
+			Quantity:      pos.Quantity,
+			AveragePrice:  pos.AveragePrice,
+			LastPrice:     pos.LastPrice,
+			Multiplier:    pos.Multiplier,
+			Realised:      pos.Realised,
+		}
+		if p.Multiplier == 0 {
+			p.Multiplier = 1
+		}
+		p.mark(p.LastPrice)
+		t.positions = append(t.positions, p)
+		t.byKey[p.InstrumentKey] = append(t.byKey[p.InstrumentKey], p)
+	}
+	t.realised, t.unrealised = 0, 0
+	for _, p := range t.positions {
+		t.realised += p.Realised
+		t.unrealised += p.Unrealised
+	}
+	t.peak = t.realised + t.unrealised
+	for _, th := range t.thresholds {
+		th.triggered = false
+	}
+	return nil
+}
+
+// Keys returns the instrument keys of the tracked positions.
+func (t *PositionTracker) Keys() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	keys := make([]string, 0, len(t.byKey))
+	for key := range t.byKey {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Attach subscribes the tracked instruments on wsm and marks positions to
+// its ticks. Call Seed first.
+func (t *PositionTracker) Attach(wsm *WebSocketManager) (detach func(), err error) {
+	if keys := t.Keys(); len(keys) > 0 {
+		if err := wsm.Subscribe(keys...); err != nil {
+			return nil, fmt.Errorf("failed to subscribe positions: %w", err)
+		}
+	}
+	return wsm.AddTickListener(t.OnTick), nil
+}
+
+func (t *PositionTracker) OnTick(tick Tick) {
+	t.mu.Lock()
+	positions, ok := t.byKey[tick.InstrumentKey]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	for _, p := range positions {
+		before := p.Unrealised
+		p.mark(tick.LTP)
+		t.unrealised += p.Unrealised - before
+	}
+	fire := t.checkLocked(tick.ReceivedAt)
+	t.mu.Unlock()
+	fire()
+}
+
+// ApplyTrade updates the position a fill belongs to, realising P&L on the
+// quantity it closes. A trade in an untracked instrument opens a position;
+// subscribe it on the feed to have it marked.
+func (t *PositionTracker) ApplyTrade(trade Trade) {
+	qty := trade.Quantity
+	if strings.EqualFold(trade.TransactionType, string(OrderSideSell)) {
+		qty = -qty
+	}
+
+	t.mu.Lock()
+	var p *PositionPnL
+	for _, cand := range t.byKey[trade.InstrumentToken] {
+		if cand.Product == trade.Product {
+			p = cand
+			break
+		}
+	}
+	if p == nil {
+		p = &PositionPnL{
+			InstrumentKey: trade.InstrumentToken,
+			TradingSymbol: trade.TradingSymbol,
+			Product:       trade.Product,
+			LastPrice:     trade.AveragePrice,
+			Multiplier:    1,
+		}
+		t.positions = append(t.positions, p)
+		t.byKey[p.InstrumentKey] = append(t.byKey[p.InstrumentKey], p)
+	}
+	realised, unrealised := p.Realised, p.Unrealised
+	p.fill(qty, trade.AveragePrice)
+	t.realised += p.Realised - realised
+	t.unrealised += p.Unrealised - unrealised
+	fire := t.checkLocked(time.Now())
+	t.mu.Unlock()
+	fire()
+}
+
+// mark revalues the open quantity at price.
+func (p *PositionPnL) mark(price float64) {
+	if price <= 0 {
+		return
+	}
+	p.LastPrice = price
+	p.Unrealised = (price - p.AveragePrice) * float64(p.Quantity) * p.Multiplier
+}
+
+// fill applies a signed quantity traded at price.
+func (p *PositionPnL) fill(qty int, price float64) {
+	switch {
+	case p.Quantity == 0 || (p.Quantity > 0) == (qty > 0):
+		open := math.Abs(float64(p.Quantity))
+		p.AveragePrice = (p.AveragePrice*open + price*math.Abs(float64(qty))) / (open + math.Abs(float64(qty)))
+	default:
+		closed := min(abs(qty), abs(p.Quantity))
+		sign := 1.0
+		if p.Quantity < 0 {
+			sign = -1
+		}
+		p.Realised += (price - p.AveragePrice) * float64(closed) * sign * p.Multiplier
+		if abs(qty) > abs(p.Quantity) {
+			p.AveragePrice = price
+		}
+	}
+	p.Quantity += qty
+	if p.Quantity == 0 {
+		p.AveragePrice = 0
+	}
+	p.mark(p.LastPrice)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// GetSnapshot returns the current P&L of every position, ordered by
+// instrument key and product.
+func (t *PositionTracker) GetSnapshot() PortfolioSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.snapshotLocked(time.Now())
+}
+
+func (t *PositionTracker) snapshotLocked(at time.Time) PortfolioSnapshot {
+	snap := PortfolioSnapshot{Positions: make([]PositionPnL, 0, len(t.positions)), At: at}
+	for _, p := range t.positions {
+		snap.Positions = append(snap.Positions, *p)
+		snap.Realised += p.Realised
+		snap.Unrealised += p.Unrealised
+	}
+	slices.SortFunc(snap.Positions, func(a, b PositionPnL) int {
+		if c := strings.Compare(a.InstrumentKey, b.InstrumentKey); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Product, b.Product)
+	})
+	snap.Total = snap.Realised + snap.Unrealised
+	snap.Peak = max(t.peak, snap.Total)
+	snap.Drawdown = snap.Peak - snap.Total
+	return snap
+}
+
+// OnThreshold registers fn to run when cond becomes true. It fires once per
+// crossing and re-arms when cond turns false again. cond runs on every tick
+// and sees only the portfolio totals, with Positions nil; fn gets the full
+// snapshot.
+func (t *PositionTracker) OnThreshold(cond func(PortfolioSnapshot) bool, fn func(PortfolioSnapshot)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.thresholds = append(t.thresholds, &pnlThreshold{cond: cond, fn: fn})
+}
+
+// OnDrawdown fires fn when portfolio P&L falls more than limit below its
+// peak since seeding.
+func (t *PositionTracker) OnDrawdown(limit float64, fn func(PortfolioSnapshot)) {
+	t.OnThreshold(func(s PortfolioSnapshot) bool { return s.Drawdown > limit }, fn)
+}
+
+// OnLoss fires fn when portfolio P&L, realised plus unrealised, falls below
+// -limit.
+func (t *PositionTracker) OnLoss(limit float64, fn func(PortfolioSnapshot)) {
+	t.OnThreshold(func(s PortfolioSnapshot) bool { return s.Total < -limit }, fn)
+}
+
+// checkLocked updates the peak and evaluates thresholds against the running
+// totals, returning a function that runs the callbacks due once the lock is
+// released. The full snapshot is only built when a callback is due.
+func (t *PositionTracker) checkLocked(at time.Time) func() {
+	total := t.realised + t.unrealised
+	t.peak = max(t.peak, total)
+	if len(t.thresholds) == 0 {
+		return func() {}
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	totals := PortfolioSnapshot{
+		Realised:   t.realised,
+		Unrealised: t.unrealised,
+		Total:      total,
+		Peak:       t.peak,
+		Drawdown:   t.peak - total,
+		At:         at,
+	}
+	var due []func(PortfolioSnapshot)
+	for _, th := range t.thresholds {
+		hit := th.cond(totals)
+		if hit && !th.triggered {
+			due = append(due, th.fn)
+		}
+		th.triggered = hit
+	}
+	if len(due) == 0 {
+		return func() {}
+	}
+
+	snap := t.snapshotLocked(at)
+	return func() {
+		for _, fn := range due {
+			fn(snap)
+		}
+	}
+}
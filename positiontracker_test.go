@@ -0,0 +1,34 @@
+package upstox
+
+import "testing"
+
+func TestPositionTrackerThresholds(t *testing.T) {
+	tr := NewManager("id", "secret", "token").NewPositionTracker()
+	key := "NSE_EQ|X"
+	tr.ApplyTrade(Trade{InstrumentToken: key, Product: "I", TransactionType: "BUY", Quantity: 10, AveragePrice: 100})
+	tr.ApplyTrade(Trade{InstrumentToken: "NSE_EQ|Y", Product: "I", TransactionType: "SELL", Quantity: 5, AveragePrice: 50})
+
+	var fired []PortfolioSnapshot
+	tr.OnLoss(50, func(s PortfolioSnapshot) { fired = append(fired, s) })
+
+	tr.OnTick(Tick{InstrumentKey: key, LTP: 98})
+	tr.OnTick(Tick{InstrumentKey: key, LTP: 94})
+	tr.OnTick(Tick{InstrumentKey: key, LTP: 93})
+	if len(fired) != 1 {
+		t.Fatalf("loss callback ran %d times, want once per crossing", len(fired))
+	}
+	if s := fired[0]; s.Total != -60 || len(s.Positions) != 2 || s.Positions[0].LastPrice != 94 {
+		t.Errorf("snapshot = %+v", s)
+	}
+
+	// Recover, then cross again.
+	tr.OnTick(Tick{InstrumentKey: key, LTP: 100})
+	tr.ApplyTrade(Trade{InstrumentToken: key, Product: "I", TransactionType: "SELL", Quantity: 10, AveragePrice: 90})
+	if len(fired) != 2 || fired[1].Realised != -100 || fired[1].Unrealised != 0 {
+		t.Fatalf("after realising the loss: %+v", fired)
+	}
+
+	if s := tr.GetSnapshot(); s.Total != -100 || s.Peak != 0 || s.Drawdown != 100 {
+		t.Errorf("GetSnapshot = %+v", s)
+	}
+}
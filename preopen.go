@@ -0,0 +1,108 @@
+package upstox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/adeludedperson/go-upstox/pb"
+)
+
+var (
+	// ErrPreOpenOrderType is returned for orders the exchange does not accept
+	// during the pre-open order collection window.
+	ErrPreOpenOrderType = errors.New("order not accepted during pre-open")
+	// ErrPreOpenMatching is returned between pre-open close and market open,
+	// when the exchange accepts no new orders at all.
+	ErrPreOpenMatching = errors.New("exchange is not accepting orders until market open")
+)
+
+// SegmentStatus returns the latest status the feed reported for a segment
+// such as "NSE_EQ". Market info frames are not tracked while OnFastPrice is set.
+func (wsm *WebSocketManager) SegmentStatus(segment string) (MarketStatus, bool) {
+	wsm.mu.RLock()
+	defer wsm.mu.RUnlock()
+	status, ok := wsm.segmentStatus[segment]
+	return status, ok
+}
+
+// OnMarketInfo registers fn for the market status frames the feed sends on
+// connect and at every session transition, e.g. PRE_OPEN_END or
+// NORMAL_CLOSE, with the status of every segment. fn runs on the read
+// goroutine after SegmentStatus has been updated.
+func (wsm *WebSocketManager) OnMarketInfo(fn MarketInfoCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.onMarketInfo = fn
+}
+
+func (wsm *WebSocketManager) updateMarketInfo(fr *pb.FeedResponse) {
+	info := fr.GetMarketInfo()
+	if info == nil {
+		return
+	}
+
+	msg := MarketInfoMessage{
+		Type:       fr.Type.String(),
+		CurrentTS:  fr.CurrentTs,
+		MarketInfo: &MarketInfo{SegmentStatus: make(map[string]MarketStatus, len(info.SegmentStatus))},
+	}
+
+	wsm.mu.Lock()
+	if wsm.segmentStatus == nil {
+		wsm.segmentStatus = make(map[string]MarketStatus)
+	}
+	for segment, status := range info.SegmentStatus {
+		wsm.segmentStatus[segment] = MarketStatus(status.String())
+		msg.MarketInfo.SegmentStatus[segment] = MarketStatus(status.String())
+	}
+	fn := wsm.onMarketInfo
+	wsm.mu.Unlock()
+
+	if fn != nil {
+		fn(msg)
+	}
+}
+
+// SegmentStatus returns a segment's status as reported by any live feed
+// created through this Manager.
+func (m *Manager) SegmentStatus(segment string) (MarketStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for wsm := range m.feeds {
+		if status, ok := wsm.SegmentStatus(segment); ok {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// checkPreOpen blocks orders the exchange would reject in the pre-open
+// session. During order collection only DAY limit and market orders are
+// accepted; between collection and the open nothing is. Without a feed there
+// is no status to go on and the order passes through.
+func (m *Manager) checkPreOpen(orderReq OrderRequest) error {
+	if orderReq.IsAMO {
+		return nil
+	}
+
+	segment, _, _ := strings.Cut(orderReq.InstrumentToken, "|")
+	status, ok := m.SegmentStatus(segment)
+	if !ok {
+		return nil
+	}
+
+	switch status {
+	case MarketStatusPreOpenStart:
+		if orderReq.OrderType != string(OrderTypeLimit) && orderReq.OrderType != string(OrderTypeMarket) {
+			return fmt.Errorf("%w: %s orders are not allowed in %s pre-open", ErrPreOpenOrderType, orderReq.OrderType, segment)
+		}
+		if orderReq.Validity != string(ValidityDay) {
+			return fmt.Errorf("%w: %s validity is not allowed in %s pre-open", ErrPreOpenOrderType, orderReq.Validity, segment)
+		}
+	case MarketStatusPreOpenEnd:
+		return fmt.Errorf("%w: %s pre-open has closed", ErrPreOpenMatching, segment)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package upstox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// PreparedOrder is an order whose static fields and headers are serialised
+// once, up front. Placing it only appends quantity and price to the cached
+// JSON prefix, so the hot path does no reflection-based marshalling.
+//
+// A PreparedOrder skips the post-placement confirmation lookup enabled by
+// WithConfirmation; the kill switch, the pre-send checks PlaceOrder runs,
+// dry-run and order hooks still apply. It is safe for concurrent use.
+type PreparedOrder struct {
+	m        *Manager
+	template OrderRequest
+	prefix   []byte
+	target   *url.URL
+
+	mu     sync.Mutex
+	token  string
+	header http.Header
+
+	bufs sync.Pool
+}
+
+// PrepareOrder validates template (with a placeholder quantity and price if
+// unset) and pre-serialises everything except quantity and price. An
+// endpoint version set on ctx with ContextWithEndpointVersion is honoured.
+func (m *Manager) PrepareOrder(ctx context.Context, template OrderRequest) (*PreparedOrder, error) {
+	check := template
+	if check.Quantity <= 0 {
+		check.Quantity = 1
+	}
+	if check.Price <= 0 && OrderType(check.OrderType) != OrderTypeMarket {
+		check.Price = 1
+	}
+	if err := validateOrderRequest(check); err != nil {
+		return nil, fmt.Errorf("invalid order template: %w", err)
+	}
+
+	// Marshal the static fields through a map with quantity and price removed
+	// so the field encoding stays identical to OrderRequest's JSON tags.
+	static, err := json.Marshal(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order template: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(static, &fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal order template: %w", err)
+	}
+	delete(fields, "quantity")
+	delete(fields, "price")
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order template: %w", err)
+	}
+
+	// body is {...}; keep it open so quantity and price can be appended.
+	prefix := append(body[:len(body)-1:len(body)-1], ',')
+
+	endpoint, _, err := m.endpointURL(ctx, EndpointPlaceOrder)
+	if err != nil {
+		return nil, err
+	}
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order URL: %w", err)
+	}
+
+	p := &PreparedOrder{m: m, template: template, prefix: prefix, target: target}
+	p.bufs.New = func() any {
+		b := make([]byte, 0, len(prefix)+64)
+		return &b
+	}
+	return p, nil
+}
+
+// headers returns the cached header set for token, rebuilding it only when
+// the token has been rotated. Callers clone it per request because the
+// client may add headers (e.g. cookies) in flight.
+func (p *PreparedOrder) headers(token string) http.Header {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.header == nil || p.token != token {
+		p.header = http.Header{
+			"Authorization": {"Bearer " + token},
+			"Content-Type":  {"application/json"},
+			"Accept":        {"application/json"},
+		}
+		p.token = token
+	}
+	return p.header
+}
+
+// Place sends the prepared order with the given quantity and price (use 0
+// for market orders).
+func (p *PreparedOrder) Place(ctx context.Context, quantity int, price float64) (*OrderResponse, error) {
+	orderReq := p.template
+	orderReq.Quantity = quantity
+	orderReq.Price = price
+
+	if quantity <= 0 {
+		return nil, fmt.Errorf("invalid order: quantity must be positive, got %d", quantity)
+	}
+	if err := p.m.checkHalt(); err != nil {
+		return nil, err
+	}
+	if err := p.m.preflight(ctx, &orderReq); err != nil {
+		return nil, err
+	}
+
+	if p.m.paper != nil {
+		return p.m.paper.place(orderReq), nil
+	}
+	if p.m.dryRun {
+		resp, err := p.m.dryRunOrder(ctx, orderReq)
+		if err == nil {
+			p.m.firePlaced(orderReq, resp)
+		}
+		return resp, err
+	}
+
+	// A clamped trigger price no longer matches the cached prefix.
+	if orderReq.TriggerPrice != p.template.TriggerPrice {
+		req, err := p.m.newRequest(ctx, "POST", p.target.String(), orderReq)
+		if err != nil {
+			return nil, err
+		}
+		return p.m.sendOrder(req, orderReq)
+	}
+
+	bufp := p.bufs.Get().(*[]byte)
+	defer p.bufs.Put(bufp)
+
+	buf := append((*bufp)[:0], p.prefix...)
+	buf = append(buf, `"quantity":`...)
+	buf = strconv.AppendInt(buf, int64(orderReq.Quantity), 10)
+	buf = append(buf, `,"price":`...)
+	buf = strconv.AppendFloat(buf, orderReq.Price, 'f', -1, 64)
+	buf = append(buf, '}')
+	*bufp = buf
+
+	// A bytes.Reader body gives the request a GetBody, which the 401 retry
+	// and the audit log need to replay it.
+	req, err := http.NewRequestWithContext(ctx, "POST", p.target.String(), bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	token, err := p.m.requestToken(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	req.Header = p.headers(token).Clone()
+
+	return p.m.sendOrder(req, orderReq)
+}
@@ -0,0 +1,69 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownPreset = errors.New("unknown order preset")
+
+// RegisterPreset stores template under name so strategies can place orders by
+// preset instead of rebuilding an OrderRequest each time, e.g.
+//
+//	m.RegisterPreset("scalp", upstox.OrderRequest{Product: "I", Validity: "IOC", OrderType: "LIMIT"})
+//	m.PlacePreset("scalp", key, "BUY", 50, 101.5)
+//
+// Instrument, side, quantity and price in template are ignored. Registering
+// an existing name replaces it.
+func (m *Manager) RegisterPreset(name string, template OrderRequest) error {
+	if name == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	if template.Product == "" || template.Validity == "" || template.OrderType == "" {
+		return fmt.Errorf("preset %q must set product, validity and order type", name)
+	}
+
+	template.InstrumentToken = ""
+	template.TransactionType = ""
+	template.Quantity = 0
+	template.Price = 0
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.presets == nil {
+		m.presets = make(map[string]OrderRequest)
+	}
+	m.presets[name] = template
+	return nil
+}
+
+// OrderFromPreset builds a request from a registered preset, for callers that
+// want to adjust it before placing. price is dropped for market presets.
+func (m *Manager) OrderFromPreset(name, instrumentToken, side string, quantity int, price float64) (OrderRequest, error) {
+	m.mu.RLock()
+	orderReq, ok := m.presets[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		return OrderRequest{}, fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+	}
+
+	orderReq.InstrumentToken = instrumentToken
+	orderReq.TransactionType = side
+	orderReq.Quantity = quantity
+	if orderReq.OrderType != string(OrderTypeMarket) {
+		orderReq.Price = price
+	}
+	return orderReq, nil
+}
+
+// PlacePreset places an order built from the named preset. price is ignored
+// for market presets; SL presets need OrderFromPreset to set a trigger price.
+func (m *Manager) PlacePreset(ctx context.Context, name, instrumentToken, side string, quantity int, price float64) (*OrderResponse, error) {
+	orderReq, err := m.OrderFromPreset(name, instrumentToken, side, quantity, price)
+	if err != nil {
+		return nil, err
+	}
+	return m.placeOrder(ctx, orderReq)
+}
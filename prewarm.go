@@ -0,0 +1,116 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const orderHost = "api-hft.upstox.com"
+
+// newOrderClient builds the client used only for order placement. It keeps a
+// small pool of idle TLS connections alive for a long time and prefers HTTP/2,
+// so a placement at signal time reuses an established connection instead of
+// paying for DNS, TCP and TLS on the hot path.
+func newOrderClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 15 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        16,
+		MaxIdleConnsPerHost: 8,
+		IdleConnTimeout:     10 * time.Minute,
+		TLSHandshakeTimeout: 5 * time.Second,
+		DisableCompression:  true,
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// PrewarmOrderPath resolves the order host and sends conns concurrent requests
+// to it, leaving the connections idle in the order client's pool. Over HTTP/2,
+// which the order host negotiates, the requests share one connection, so
+// conns above 1 only opens extra connections when the host falls back to
+// HTTP/1.1. Call it shortly before market open; pair it with
+// KeepOrderPathWarm so the connection is not reaped. The error joins every
+// failed request's error.
+func (m *Manager) PrewarmOrderPath(ctx context.Context, conns int) error {
+	if conns <= 0 {
+		conns = 1
+	}
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, orderHost); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", orderHost, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, conns)
+	for i := 0; i < conns; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.pingOrderHost(ctx); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var all []error
+	for err := range errs {
+		all = append(all, err)
+	}
+	return errors.Join(all...)
+}
+
+// KeepOrderPathWarm sends a no-op request to the order host every interval so
+// idle connections stay open and the TLS session stays resumable. Call the
+// returned function to stop.
+func (m *Manager) KeepOrderPathWarm(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = m.pingOrderHost(context.Background())
+			}
+		}
+	}()
+
+	return func() { once.Do(func() { close(done) }) }
+}
+
+func (m *Manager) pingOrderHost(ctx context.Context) error {
+	u := url.URL{Scheme: "https", Host: orderHost, Path: "/"}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := m.orderClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", orderHost, err)
+	}
+	// Drain so the connection goes back to the pool rather than being closed.
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
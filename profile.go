@@ -0,0 +1,57 @@
+package upstox
+
+import (
+	"context"
+	"slices"
+)
+
+const profileURL = "https://api.upstox.com/v2/user/profile"
+
+// Profile is the logged-in user's account. Exchanges lists the segments
+// activated for trading (NSE, NFO, BSE, BFO, MCX, CDS, ...).
+type Profile struct {
+	Email      string   `json:"email"`
+	Exchanges  []string `json:"exchanges"`
+	Products   []string `json:"products"`
+	Broker     string   `json:"broker"`
+	UserID     string   `json:"user_id"`
+	UserName   string   `json:"user_name"`
+	OrderTypes []string `json:"order_types"`
+	UserType   string   `json:"user_type"`
+	POA        bool     `json:"poa"`
+	DDPI       bool     `json:"ddpi"`
+	IsActive   bool     `json:"is_active"`
+}
+
+type ProfileResponse struct {
+	Status string       `json:"status"`
+	Data   Profile      `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *ProfileResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+func (m *Manager) GetProfile(ctx context.Context) (*Profile, error) {
+	req, err := m.newRequest(ctx, "GET", profileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var profileResp ProfileResponse
+	if err := m.do(req, &profileResp); err != nil {
+		return nil, err
+	}
+
+	return &profileResp.Data, nil
+}
+
+// SegmentActive reports whether the account can trade on exchange, e.g.
+// "NFO" for NSE derivatives.
+func (p *Profile) SegmentActive(exchange string) bool {
+	return slices.Contains(p.Exchanges, exchange)
+}
+
+// ProductEnabled reports whether product, e.g. "MTF", is enabled.
+func (p *Profile) ProductEnabled(product ProductType) bool {
+	return slices.Contains(p.Products, string(product))
+}
@@ -0,0 +1,82 @@
+package upstox
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quota is what the API last reported about the caller's rate limit.
+// Fields the response did not carry are zero.
+type Quota struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	At        time.Time
+}
+
+// Quota returns the rate-limit headers of the most recent response that had
+// any, and false if none has yet.
+func (m *Manager) Quota() (Quota, bool) {
+	q := m.quota.Load()
+	if q == nil {
+		return Quota{}, false
+	}
+	return *q, true
+}
+
+func (m *Manager) noteQuota(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	if q, ok := parseQuota(resp.Header, time.Now()); ok {
+		m.quota.Store(&q)
+	}
+}
+
+// parseQuota reads the X-RateLimit-* headers, or their unprefixed
+// RateLimit-* equivalents. Reset may be a Unix time or seconds from now.
+func parseQuota(h http.Header, now time.Time) (Quota, bool) {
+	get := func(name string) (int64, bool) {
+		v := h.Get("X-RateLimit-" + name)
+		if v == "" {
+			v = h.Get("RateLimit-" + name)
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n, err == nil
+	}
+
+	q := Quota{At: now}
+	limit, hasLimit := get("Limit")
+	remaining, hasRemaining := get("Remaining")
+	reset, hasReset := get("Reset")
+	if !hasLimit && !hasRemaining && !hasReset {
+		return Quota{}, false
+	}
+	q.Limit, q.Remaining = int(limit), int(remaining)
+	if hasReset {
+		if reset > 1e9 {
+			q.Reset = time.Unix(reset, 0)
+		} else {
+			q.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return q, true
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns zero if the header is absent or malformed.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type DepthLevel struct {
+	Quantity int64   `json:"quantity"`
+	Price    float64 `json:"price"`
+	Orders   int     `json:"orders"`
+}
+
+type QuoteDepth struct {
+	Buy  []DepthLevel `json:"buy"`
+	Sell []DepthLevel `json:"sell"`
+}
+
+type QuoteOHLC struct {
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+}
+
+type FullQuote struct {
+	OHLC              QuoteOHLC  `json:"ohlc"`
+	Depth             QuoteDepth `json:"depth"`
+	Timestamp         string     `json:"timestamp"`
+	InstrumentToken   string     `json:"instrument_token"`
+	Symbol            string     `json:"symbol"`
+	LastPrice         float64    `json:"last_price"`
+	Volume            int64      `json:"volume"`
+	AveragePrice      float64    `json:"average_price"`
+	OI                float64    `json:"oi"`
+	NetChange         float64    `json:"net_change"`
+	TotalBuyQuantity  float64    `json:"total_buy_quantity"`
+	TotalSellQuantity float64    `json:"total_sell_quantity"`
+	LowerCircuitLimit float64    `json:"lower_circuit_limit"`
+	UpperCircuitLimit float64    `json:"upper_circuit_limit"`
+	LastTradeTime     string     `json:"last_trade_time"`
+	OIDayHigh         float64    `json:"oi_day_high"`
+	OIDayLow          float64    `json:"oi_day_low"`
+}
+
+type FullQuoteResponse struct {
+	Status string               `json:"status"`
+	Data   map[string]FullQuote `json:"data"`
+	Errors []OrderError         `json:"errors,omitempty"`
+}
+
+func (r *FullQuoteResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetFullQuotes returns full market quotes keyed by instrument key (the API
+// itself keys them by "EXCHANGE:SYMBOL"), batched like GetLTP.
+func (m *Manager) GetFullQuotes(ctx context.Context, instrumentKeys ...string) (map[string]FullQuote, error) {
+	quotes := make(map[string]FullQuote, len(instrumentKeys))
+	for batch := range slices.Chunk(instrumentKeys, maxQuoteInstruments) {
+		endpoint := "https://api.upstox.com/v2/market-quote/quotes?instrument_key=" + url.QueryEscape(strings.Join(batch, ","))
+
+		req, err := m.newRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var quoteResp FullQuoteResponse
+		if err := m.do(req, &quoteResp); err != nil {
+			return nil, err
+		}
+		for _, quote := range quoteResp.Data {
+			quotes[quote.InstrumentToken] = quote
+		}
+	}
+	return quotes, nil
+}
+
+// maxQuoteInstruments is the most instrument keys a market quote request
+// accepts.
+const maxQuoteInstruments = 500
+
+// GetLTP returns last traded prices keyed by instrument key, splitting the
+// keys into as many requests as the API's per-request limit needs. Keys the
+// API has no price for are missing from the result.
+func (m *Manager) GetLTP(ctx context.Context, instrumentKeys ...string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(instrumentKeys))
+	for batch := range slices.Chunk(instrumentKeys, maxQuoteInstruments) {
+		batchPrices, err := m.getLTPQuotes(ctx, batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get LTP: %w", err)
+		}
+		maps.Copy(prices, batchPrices)
+	}
+	return prices, nil
+}
+
+// OHLCInterval is the candle an OHLC quote describes.
+type OHLCInterval string
+
+const (
+	OHLCDay      OHLCInterval = "1d"
+	OHLCMinute   OHLCInterval = "I1"
+	OHLC30Minute OHLCInterval = "I30"
+)
+
+// OHLCQuote is the current candle of an instrument for the requested
+// interval; with OHLCDay, Open is the day's open, as used by gap scanners.
+type OHLCQuote struct {
+	OHLC            QuoteOHLC `json:"ohlc"`
+	LastPrice       float64   `json:"last_price"`
+	InstrumentToken string    `json:"instrument_token"`
+}
+
+type OHLCQuoteResponse struct {
+	Status string               `json:"status"`
+	Data   map[string]OHLCQuote `json:"data"`
+	Errors []OrderError         `json:"errors,omitempty"`
+}
+
+func (r *OHLCQuoteResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// GetOHLCQuotes returns OHLC quotes keyed by instrument key, batched like
+// GetLTP.
+func (m *Manager) GetOHLCQuotes(ctx context.Context, interval OHLCInterval, instrumentKeys ...string) (map[string]OHLCQuote, error) {
+	switch interval {
+	case OHLCDay, OHLCMinute, OHLC30Minute:
+	default:
+		return nil, fmt.Errorf("unsupported OHLC interval %q", interval)
+	}
+
+	quotes := make(map[string]OHLCQuote, len(instrumentKeys))
+	for batch := range slices.Chunk(instrumentKeys, maxQuoteInstruments) {
+		q := url.Values{}
+		q.Set("instrument_key", strings.Join(batch, ","))
+		q.Set("interval", string(interval))
+
+		req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/market-quote/ohlc?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var ohlcResp OHLCQuoteResponse
+		if err := m.do(req, &ohlcResp); err != nil {
+			return nil, fmt.Errorf("failed to get OHLC quotes: %w", err)
+		}
+		for _, quote := range ohlcResp.Data {
+			quotes[quote.InstrumentToken] = quote
+		}
+	}
+	return quotes, nil
+}
@@ -0,0 +1,35 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetFullQuotesBatches(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, _ int) (*http.Response, error) {
+		var data []string
+		for _, key := range strings.Split(req.URL.Query().Get("instrument_key"), ",") {
+			data = append(data, fmt.Sprintf(`"%s":{"instrument_token":"%s","last_price":1}`, key, key))
+		}
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{`+strings.Join(data, ",")+`}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+
+	keys := make([]string, 2*maxQuoteInstruments+1)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("NSE_EQ|%d", i)
+	}
+	quotes, err := m.GetFullQuotes(context.Background(), keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != len(keys) {
+		t.Errorf("%d quotes, want %d", len(quotes), len(keys))
+	}
+	if n := stub.count(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
@@ -0,0 +1,118 @@
+package upstox
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateFamily groups endpoints that share a client-side rate limit.
+type RateFamily string
+
+const (
+	// RateOrders covers placing, modifying and cancelling orders.
+	RateOrders RateFamily = "orders"
+	// RateData covers every other call: quotes, history, portfolio, account.
+	RateData RateFamily = "data"
+)
+
+// RateLimit allows Requests per Per, with bursts of up to Burst requests
+// (Requests if zero).
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// UpstoxRateLimits are the published per-user limits for standard APIs.
+var UpstoxRateLimits = []RateLimit{
+	{Requests: 50, Per: time.Second},
+	{Requests: 500, Per: time.Minute},
+	{Requests: 2000, Per: 30 * time.Minute},
+}
+
+// WithRateLimit makes calls in family wait until every one of limits allows
+// them, so concurrent goroutines sharing the Manager stay under the broker's
+// quotas instead of collecting 429s. Limits are per Manager.
+func WithRateLimit(family RateFamily, limits ...RateLimit) ManagerOption {
+	return func(m *Manager) {
+		if m.limiters == nil {
+			m.limiters = make(map[RateFamily][]*tokenBucket)
+		}
+		buckets := make([]*tokenBucket, 0, len(limits))
+		for _, l := range limits {
+			if l.Requests > 0 && l.Per > 0 {
+				buckets = append(buckets, newTokenBucket(l))
+			}
+		}
+		m.limiters[family] = buckets
+	}
+}
+
+// WithDefaultRateLimits applies UpstoxRateLimits to both families.
+func WithDefaultRateLimits() ManagerOption {
+	return func(m *Manager) {
+		WithRateLimit(RateOrders, UpstoxRateLimits...)(m)
+		WithRateLimit(RateData, UpstoxRateLimits...)(m)
+	}
+}
+
+func rateFamily(req *http.Request) RateFamily {
+	if req.Method != http.MethodGet && strings.Contains(req.URL.Path, "/order") {
+		return RateOrders
+	}
+	return RateData
+}
+
+// waitRateLimit blocks until req's family has capacity or ctx is done.
+func (m *Manager) waitRateLimit(req *http.Request) error {
+	buckets := m.limiters[rateFamily(req)]
+	var wait time.Duration
+	for _, b := range buckets {
+		wait = max(wait, b.reserve())
+	}
+	if wait <= 0 {
+		return nil
+	}
+	return sleepCtx(req.Context(), wait)
+}
+
+type tokenBucket struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(l RateLimit) *tokenBucket {
+	burst := l.Burst
+	if burst <= 0 {
+		burst = l.Requests
+	}
+	return &tokenBucket{
+		rate:   float64(l.Requests) / l.Per.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it. The
+// balance may go negative, which queues concurrent callers in order.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,88 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurst(t *testing.T) {
+	b := newTokenBucket(RateLimit{Requests: 10, Per: time.Second, Burst: 3})
+	for i := 0; i < 3; i++ {
+		if wait := b.reserve(); wait != 0 {
+			t.Fatalf("request %d waits %v inside the burst", i+1, wait)
+		}
+	}
+	// The fourth and fifth queue behind each other at 100ms per token.
+	if wait := b.reserve(); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Errorf("fourth request waits %v, want ~100ms", wait)
+	}
+	if wait := b.reserve(); wait < 190*time.Millisecond || wait > 200*time.Millisecond {
+		t.Errorf("fifth request waits %v, want ~200ms", wait)
+	}
+}
+
+func TestRateFamily(t *testing.T) {
+	tests := []struct {
+		method, url string
+		want        RateFamily
+	}{
+		{"POST", "https://api-hft.upstox.com/v3/order/place", RateOrders},
+		{"DELETE", "https://api-hft.upstox.com/v2/order/cancel", RateOrders},
+		{"GET", "https://api.upstox.com/v2/order/retrieve-all", RateData},
+		{"GET", "https://api.upstox.com/v2/portfolio/short-term-positions", RateData},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		if got := rateFamily(req); got != tt.want {
+			t.Errorf("rateFamily(%s %s) = %s, want %s", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":[]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub),
+		WithRateLimit(RateData, RateLimit{Requests: 20, Per: time.Second, Burst: 1}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := m.GetPositions(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %v, want at least 100ms", elapsed)
+	}
+
+	// Orders have their own family and are not held up.
+	start = time.Now()
+	m.PlaceMarketOrder(context.Background(), "NSE_EQ|X", 1, "BUY")
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("order waited %v on the data limit", elapsed)
+	}
+}
+
+func TestRateLimitHonoursContext(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":[]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub),
+		WithRateLimit(RateData, RateLimit{Requests: 1, Per: time.Minute}))
+
+	if _, err := m.GetPositions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.GetPositions(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := stub.count(); got != 1 {
+		t.Errorf("%d requests sent, want 1", got)
+	}
+}
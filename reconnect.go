@@ -0,0 +1,111 @@
+package upstox
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// UnlimitedReconnects as ReconnectPolicy.MaxAttempts keeps reconnecting until
+// Stop is called.
+const UnlimitedReconnects = -1
+
+// ReconnectPolicy controls how a dropped feed is reconnected. The delay
+// before attempt n is BaseDelay * Multiplier^n, capped at MaxDelay and then
+// spread by ±Jitter (a fraction, e.g. 0.2 for ±20%) so that many clients
+// dropped together do not reconnect in lockstep. Zero fields take the
+// defaults: 3 attempts, 1s base, doubling, 1 minute cap, no jitter.
+type ReconnectPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+	Jitter      float64
+}
+
+const (
+	defaultReconnectAttempts = 3
+	defaultReconnectBase     = time.Second
+	defaultReconnectMax      = time.Minute
+	defaultReconnectFactor   = 2
+)
+
+// GiveUpCallback receives the number of attempts made and the error of the
+// last one once the policy's attempts are exhausted.
+type GiveUpCallback func(attempts int, lastErr error)
+
+// OnGiveUp registers fn to run when the feed stops reconnecting. The manager
+// is stopped right after fn returns.
+func (wsm *WebSocketManager) OnGiveUp(fn GiveUpCallback) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.hooks.giveUp = fn
+}
+
+func (p ReconnectPolicy) maxAttempts() int {
+	if p.MaxAttempts == 0 {
+		return defaultReconnectAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	base, maxDelay, factor := p.BaseDelay, p.MaxDelay, p.Multiplier
+	if base <= 0 {
+		base = defaultReconnectBase
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultReconnectMax
+	}
+	if factor < 1 {
+		factor = defaultReconnectFactor
+	}
+
+	d := min(float64(base)*math.Pow(factor, float64(attempt)), float64(maxDelay))
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// handleDisconnect schedules the next reconnection attempt, or gives up and
+// stops the manager once the policy is exhausted. lastErr is what ended the
+// connection or the previous attempt.
+func (wsm *WebSocketManager) handleDisconnect(lastErr error) {
+	if !wsm.shouldReconnect {
+		return
+	}
+
+	wsm.mu.Lock()
+	policy := wsm.config.Reconnect
+	attempt := wsm.reconnectAttempts + 1
+	if limit := policy.maxAttempts(); limit != UnlimitedReconnects && attempt > limit {
+		giveUp := wsm.hooks.giveUp
+		wsm.mu.Unlock()
+
+		wsm.log().Error("giving up reconnecting", "attempts", attempt-1, "error", lastErr)
+		if giveUp != nil {
+			giveUp(attempt-1, lastErr)
+		}
+		wsm.Stop()
+		return
+	}
+	wsm.reconnectAttempts = attempt
+	delay := policy.delay(attempt)
+	wsm.mu.Unlock()
+
+	wsm.log().Info("reconnecting", "attempt", attempt, "delay", delay)
+	wsm.fireReconnect(attempt, delay)
+
+	time.AfterFunc(delay, func() {
+		if !wsm.shouldReconnect {
+			return
+		}
+		if err := wsm.connect(); err != nil {
+			wsm.log().Warn("reconnection failed", "attempt", attempt, "error", err)
+			wsm.fireError(fmt.Errorf("reconnection attempt failed: %w", err))
+			wsm.handleDisconnect(err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package upstox
+
+import "strings"
+
+type RejectionReason string
+
+const (
+	RejectionUnknown            RejectionReason = "UNKNOWN"
+	RejectionInsufficientMargin RejectionReason = "INSUFFICIENT_MARGIN"
+	RejectionCircuitLimit       RejectionReason = "CIRCUIT_LIMIT"
+	RejectionBannedScrip        RejectionReason = "BANNED_SCRIP"
+	RejectionMarketClosed       RejectionReason = "MARKET_CLOSED"
+	RejectionFreezeQuantity     RejectionReason = "FREEZE_QUANTITY"
+	RejectionInvalidQuantity    RejectionReason = "INVALID_QUANTITY"
+	RejectionInvalidPrice       RejectionReason = "INVALID_PRICE"
+	RejectionSegmentInactive    RejectionReason = "SEGMENT_INACTIVE"
+	RejectionOrderTypeBlocked   RejectionReason = "ORDER_TYPE_BLOCKED"
+)
+
+// rejectionPatterns is checked in order, so more specific phrases must come
+// before generic ones (e.g. "freeze quantity" before "quantity"). Quantity
+// and price errors are both worded "should be a multiple of", so match the
+// subject of the phrase rather than the phrase itself.
+var rejectionPatterns = []struct {
+	reason   RejectionReason
+	keywords []string
+}{
+	{RejectionInsufficientMargin, []string{"insufficient", "margin exceeds", "shortfall", "funds required"}},
+	{RejectionCircuitLimit, []string{"circuit", "price range", "dpr", "outside the price band"}},
+	{RejectionBannedScrip, []string{"ban period", "banned", "fresh positions not allowed", "surveillance"}},
+	{RejectionMarketClosed, []string{"market is closed", "market closed", "outside market hours", "exchange is closed"}},
+	{RejectionFreezeQuantity, []string{"freeze", "quantity limit exceeded"}},
+	{RejectionSegmentInactive, []string{"segment not active", "segment is not enabled", "not activated"}},
+	{RejectionOrderTypeBlocked, []string{"order type not allowed", "market orders are not allowed", "not allowed for this"}},
+	{RejectionInvalidQuantity, []string{"lot size", "quantity should be a multiple", "quantity must be a multiple", "invalid quantity"}},
+	{RejectionInvalidPrice, []string{"tick size", "price should be a multiple", "price must be a multiple", "invalid price", "trigger price"}},
+}
+
+// ClassifyRejection maps an RMS or exchange status message to a RejectionReason.
+// Messages that match none of the known phrases return RejectionUnknown.
+func ClassifyRejection(message string) RejectionReason {
+	msg := strings.ToLower(message)
+	if msg == "" {
+		return RejectionUnknown
+	}
+
+	for _, p := range rejectionPatterns {
+		for _, kw := range p.keywords {
+			if strings.Contains(msg, kw) {
+				return p.reason
+			}
+		}
+	}
+
+	return RejectionUnknown
+}
+
+func (o *Order) RejectionReason() RejectionReason {
+	if o.Status != "rejected" {
+		return ""
+	}
+	if o.StatusMessageRaw != "" {
+		if reason := ClassifyRejection(o.StatusMessageRaw); reason != RejectionUnknown {
+			return reason
+		}
+	}
+	return ClassifyRejection(o.StatusMessage)
+}
+
+func (r *OrderResponse) RejectionReason() RejectionReason {
+	for _, e := range r.Errors {
+		if reason := ClassifyRejection(e.Message); reason != RejectionUnknown {
+			return reason
+		}
+	}
+	if len(r.Errors) > 0 {
+		return RejectionUnknown
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package upstox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyRejection(t *testing.T) {
+	tests := []struct {
+		message string
+		want    RejectionReason
+	}{
+		{"", RejectionUnknown},
+		{"something unexpected happened", RejectionUnknown},
+		{"RMS:Margin Exceeds,Required:10500.00, Available:2300.00", RejectionInsufficientMargin},
+		{"Insufficient funds", RejectionInsufficientMargin},
+		{"Order price is outside the price band", RejectionCircuitLimit},
+		{"Security is in ban period", RejectionBannedScrip},
+		{"Market is closed", RejectionMarketClosed},
+		{"Quantity is greater than freeze quantity", RejectionFreezeQuantity},
+		{"Segment not active for this account", RejectionSegmentInactive},
+		{"Market orders are not allowed for this instrument", RejectionOrderTypeBlocked},
+		{"Quantity should be a multiple of lot size", RejectionInvalidQuantity},
+		{"Order quantity should be a multiple of 75", RejectionInvalidQuantity},
+		{"Invalid quantity", RejectionInvalidQuantity},
+		{"Price should be a multiple of 0.05", RejectionInvalidPrice},
+		{"Price should be a multiple of tick size", RejectionInvalidPrice},
+		{"Trigger price should be a multiple of 0.05", RejectionInvalidPrice},
+		{"Invalid price", RejectionInvalidPrice},
+	}
+	for _, tt := range tests {
+		if got := ClassifyRejection(tt.message); got != tt.want {
+			t.Errorf("ClassifyRejection(%q) = %s, want %s", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestOrderRejectionReason(t *testing.T) {
+	tests := []struct {
+		order Order
+		want  RejectionReason
+	}{
+		{Order{Status: "open", StatusMessage: "Insufficient funds"}, ""},
+		{Order{Status: "rejected", StatusMessage: "Insufficient funds"}, RejectionInsufficientMargin},
+		{Order{Status: "rejected", StatusMessage: "Rejected by exchange", StatusMessageRaw: "RMS: price should be a multiple of 0.05"}, RejectionInvalidPrice},
+		{Order{Status: "rejected", StatusMessage: "Market is closed", StatusMessageRaw: "16388: unrecognised"}, RejectionMarketClosed},
+		{Order{Status: "rejected"}, RejectionUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.order.RejectionReason(); got != tt.want {
+			t.Errorf("RejectionReason(%q, %q) = %q, want %q", tt.order.StatusMessage, tt.order.StatusMessageRaw, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorIsFollowsClassification(t *testing.T) {
+	tests := []struct {
+		message string
+		want    error
+	}{
+		{"RMS:Margin Exceeds,Required:10500.00, Available:2300.00", ErrInsufficientFunds},
+		{"Insufficient funds", ErrInsufficientFunds},
+		{"Order placed outside market hours", ErrMarketClosed},
+		{"Order not found", ErrOrderNotFound},
+		{"Order is already completed", ErrNotModifiable},
+		{"Order is not in a modifiable state", ErrNotModifiable},
+	}
+	sentinels := []error{ErrInsufficientFunds, ErrMarketClosed, ErrOrderNotFound, ErrNotModifiable}
+	for _, tt := range tests {
+		err := &APIError{StatusCode: 400, Errors: []OrderError{{Message: tt.message}}}
+		for _, s := range sentinels {
+			if got := errors.Is(err, s); got != (s == tt.want) {
+				t.Errorf("errors.Is(%q, %v) = %v", tt.message, s, got)
+			}
+		}
+	}
+}
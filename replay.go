@@ -0,0 +1,313 @@
+package upstox
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReplayConfig struct {
+	// Speed scales playback: 1 is real time, 60 plays an hour in a minute.
+	// Zero or negative replays as fast as records can be delivered.
+	Speed float64
+
+	// From and To bound the records replayed by time; zero means unbounded.
+	From, To time.Time
+
+	// CandlesAsTicks also delivers each replayed candle's close as a tick at
+	// the candle's end, for strategies that only listen to prices.
+	CandlesAsTicks bool
+}
+
+// Replayer plays recorded ticks and candles back through a
+// WebSocketManager, so a strategy wired to a feed runs unchanged against
+// history. Ticks reach OnLiveFeed as single-instrument LTPC frames and then
+// every tick consumer, onPriceUpdate included; candles go to OnCandle
+// callbacks. Records from several files are merged in time order.
+//
+// The WebSocketManager should not be connected: create one with
+// NewWebSocketManager and an empty URL, and wire it up as the live one.
+type Replayer struct {
+	wsm     *WebSocketManager
+	cfg     ReplayConfig
+	paths   []string
+	candles []CandleCloseCallback
+}
+
+func NewReplayer(wsm *WebSocketManager, cfg ReplayConfig) *Replayer {
+	return &Replayer{wsm: wsm, cfg: cfg}
+}
+
+// AddFile queues a file written by JSONLSink (.jsonl) or CSVSink (.csv).
+// CSV files need their header row, which CSVSink writes, and a time column.
+func (r *Replayer) AddFile(paths ...string) *Replayer {
+	r.paths = append(r.paths, paths...)
+	return r
+}
+
+// OnCandle registers fn for every replayed candle.
+func (r *Replayer) OnCandle(fn CandleCloseCallback) {
+	r.candles = append(r.candles, fn)
+}
+
+// Run replays every record and returns once they are all delivered, a file
+// cannot be read, or ctx is done.
+func (r *Replayer) Run(ctx context.Context) error {
+	sources := make([]*replaySource, 0, len(r.paths))
+	defer func() {
+		for _, src := range sources {
+			src.close()
+		}
+	}()
+	for _, path := range r.paths {
+		src, err := openReplaySource(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+		if err := src.advance(); err != nil {
+			return err
+		}
+	}
+
+	var start, first time.Time
+	for {
+		src := earliestSource(sources)
+		if src == nil {
+			return nil
+		}
+		rec := src.rec
+		if err := src.advance(); err != nil {
+			return err
+		}
+
+		at := rec.at()
+		if (!r.cfg.From.IsZero() && at.Before(r.cfg.From)) || (!r.cfg.To.IsZero() && at.After(r.cfg.To)) {
+			continue
+		}
+		if r.cfg.Speed > 0 {
+			if start.IsZero() {
+				start, first = time.Now(), at
+			}
+			due := start.Add(time.Duration(float64(at.Sub(first)) / r.cfg.Speed))
+			if wait := time.Until(due); wait > 0 {
+				if err := sleepCtx(ctx, wait); err != nil {
+					return err
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.deliver(rec)
+	}
+}
+
+func earliestSource(sources []*replaySource) *replaySource {
+	var next *replaySource
+	for _, src := range sources {
+		if src.rec != nil && (next == nil || src.rec.at().Before(next.rec.at())) {
+			next = src
+		}
+	}
+	return next
+}
+
+func (r *Replayer) deliver(rec *feedRecord) {
+	if rec.Interval == "" {
+		r.deliverTick(rec.tick())
+		return
+	}
+
+	c := Candle{
+		Timestamp:    rec.Time,
+		Open:         rec.Open,
+		High:         rec.High,
+		Low:          rec.Low,
+		Close:        rec.Close,
+		Volume:       rec.Volume,
+		OpenInterest: rec.OpenInterest,
+	}
+	for _, fn := range r.candles {
+		fn(rec.InstrumentKey, rec.Interval, c)
+	}
+	if r.cfg.CandlesAsTicks && c.Close > 0 {
+		at := rec.at()
+		r.deliverTick(Tick{InstrumentKey: rec.InstrumentKey, LTP: c.Close, LTT: at.UnixMilli(), ReceivedAt: at})
+	}
+}
+
+func (r *Replayer) deliverTick(tick Tick) {
+	r.wsm.mu.RLock()
+	onLiveFeed := r.wsm.onLiveFeed
+	r.wsm.mu.RUnlock()
+	if onLiveFeed != nil {
+		onLiveFeed(LiveFeedMessage{
+			Type:      "live_feed",
+			CurrentTS: tick.ReceivedAt.UnixMilli(),
+			Feeds: map[string]*FeedData{
+				tick.InstrumentKey: {
+					LTPC:        &LTPCData{LTP: tick.LTP, LTT: tick.LTT, LTQ: tick.LTQ, CP: tick.CP},
+					RequestMode: ModeLTPC,
+				},
+			},
+		})
+	}
+	r.wsm.InjectTick(tick)
+}
+
+// at is when the record became known: a tick's time, or a candle's end.
+func (rec *feedRecord) at() time.Time {
+	if rec.Interval != "" {
+		if d, ok := rec.Interval.Duration(); ok {
+			return bucketEnd(rec.InstrumentKey, rec.Time, d)
+		}
+	}
+	return rec.Time
+}
+
+func (rec *feedRecord) tick() Tick {
+	tick := Tick{
+		InstrumentKey: rec.InstrumentKey,
+		LTP:           rec.LTP,
+		LTQ:           rec.LTQ,
+		LTT:           rec.LTT,
+		CP:            rec.CP,
+		ReceivedAt:    rec.Time,
+	}
+	if rec.ReceivedAt != nil {
+		tick.ReceivedAt = *rec.ReceivedAt
+	}
+	return tick
+}
+
+// replaySource reads one recorded file a record at a time; rec is the next
+// record, nil once the file is exhausted.
+type replaySource struct {
+	path string
+	f    *os.File
+	next func() (*feedRecord, error)
+	rec  *feedRecord
+}
+
+func openReplaySource(path string) (*replaySource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	src := &replaySource{path: path, f: f}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl":
+		dec := json.NewDecoder(bufio.NewReader(f))
+		src.next = func() (*feedRecord, error) {
+			var rec feedRecord
+			if err := dec.Decode(&rec); err != nil {
+				return nil, err
+			}
+			return &rec, nil
+		}
+	case ".csv":
+		r := csv.NewReader(bufio.NewReader(f))
+		r.ReuseRecord = true
+		header, err := r.Read()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+		}
+		cols := make([]CSVColumn, len(header))
+		for i, h := range header {
+			cols[i] = CSVColumn(h)
+		}
+		src.next = func() (*feedRecord, error) {
+			row, err := r.Read()
+			if err != nil {
+				return nil, err
+			}
+			return parseCSVRecord(cols, row)
+		}
+	default:
+		f.Close()
+		return nil, fmt.Errorf("cannot replay %s: expected a .jsonl or .csv file", path)
+	}
+	return src, nil
+}
+
+func (src *replaySource) advance() error {
+	rec, err := src.next()
+	if errors.Is(err, io.EOF) {
+		src.rec = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src.path, err)
+	}
+	src.rec = rec
+	return nil
+}
+
+func (src *replaySource) close() {
+	src.f.Close()
+}
+
+func parseCSVRecord(cols []CSVColumn, row []string) (*feedRecord, error) {
+	var rec feedRecord
+	var err error
+	for i, col := range cols {
+		if i >= len(row) || row[i] == "" {
+			continue
+		}
+		v := row[i]
+		switch col {
+		case ColTime:
+			rec.Time, err = time.ParseInLocation(csvTimeLayout, v, IST)
+		case ColReceivedAt:
+			var t time.Time
+			if t, err = time.ParseInLocation(csvTimeLayout, v, IST); err == nil {
+				rec.ReceivedAt = &t
+			}
+		case ColInstrument:
+			rec.InstrumentKey = v
+		case ColLTP:
+			rec.LTP, err = strconv.ParseFloat(v, 64)
+		case ColLTQ:
+			rec.LTQ, err = strconv.ParseInt(v, 10, 64)
+		case ColClosePrice:
+			rec.CP, err = strconv.ParseFloat(v, 64)
+		case ColInterval:
+			rec.Interval = Interval(v)
+		case ColOpen:
+			rec.Open, err = strconv.ParseFloat(v, 64)
+		case ColHigh:
+			rec.High, err = strconv.ParseFloat(v, 64)
+		case ColLow:
+			rec.Low, err = strconv.ParseFloat(v, 64)
+		case ColClose:
+			rec.Close, err = strconv.ParseFloat(v, 64)
+		case ColVolume:
+			rec.Volume, err = strconv.ParseInt(v, 10, 64)
+		case ColOI:
+			rec.OpenInterest, err = strconv.ParseInt(v, 10, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", col, v, err)
+		}
+	}
+	if rec.Time.IsZero() {
+		return nil, errors.New("row has no time")
+	}
+	// CSV ticks keep exchange time in the time column but not LTT itself.
+	if rec.Interval == "" && rec.LTT == 0 {
+		rec.LTT = rec.Time.UnixMilli()
+	}
+	return &rec, nil
+}
@@ -0,0 +1,208 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	reportPageSize     = 5000
+	reportPageInterval = 500 * time.Millisecond
+)
+
+// TradePnL is one realised trade from the profit and loss report. Dates are
+// reported as dd-mm-yyyy.
+type TradePnL struct {
+	Quantity    float64 `json:"quantity"`
+	ISIN        string  `json:"isin"`
+	ScripName   string  `json:"scrip_name"`
+	TradeType   string  `json:"trade_type"`
+	BuyDate     string  `json:"buy_date"`
+	BuyAverage  float64 `json:"buy_average"`
+	SellDate    string  `json:"sell_date"`
+	SellAverage float64 `json:"sell_average"`
+	BuyAmount   float64 `json:"buy_amount"`
+	SellAmount  float64 `json:"sell_amount"`
+}
+
+type TradePnLResponse struct {
+	Status   string     `json:"status"`
+	Data     []TradePnL `json:"data"`
+	Metadata struct {
+		Page struct {
+			PageNumber int `json:"page_number"`
+			PageSize   int `json:"page_size"`
+		} `json:"page"`
+	} `json:"metadata"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+type TradeCharges struct {
+	Total     float64 `json:"total"`
+	Brokerage float64 `json:"brokerage"`
+	Taxes     struct {
+		GST       float64 `json:"gst"`
+		STT       float64 `json:"stt"`
+		StampDuty float64 `json:"stamp_duty"`
+	} `json:"taxes"`
+	Charges struct {
+		Transaction      float64 `json:"transaction"`
+		Clearing         float64 `json:"clearing"`
+		IPFT             float64 `json:"ipft"`
+		Others           float64 `json:"others"`
+		SEBITurnover     float64 `json:"sebi_turnover"`
+		DematTransaction float64 `json:"demat_transaction"`
+	} `json:"charges"`
+}
+
+type TradeChargesResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ChargesBreakdown TradeCharges `json:"charges_breakdown"`
+	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *TradePnLResponse) envelope() (string, []OrderError)     { return r.Status, r.Errors }
+func (r *TradeChargesResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+
+// TradePnLIterator pages through realised trades for a segment ("EQ", "FO",
+// "COM", "CD") and financial year (e.g. "2425" for April 2024 to March 2025).
+func (m *Manager) TradePnLIterator(segment, financialYear string, opts IteratorOptions) *Iterator[TradePnL] {
+	if opts.PageInterval == 0 {
+		opts.PageInterval = reportPageInterval
+	}
+	return NewIterator(func(ctx context.Context, page int) ([]TradePnL, bool, error) {
+		q := url.Values{}
+		q.Set("segment", segment)
+		q.Set("financial_year", financialYear)
+		q.Set("page_number", strconv.Itoa(page))
+		q.Set("page_size", strconv.Itoa(reportPageSize))
+
+		req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/trade/profit-loss/data?"+q.Encode(), nil)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var pnlResp TradePnLResponse
+		if err := m.do(req.WithContext(ctx), &pnlResp); err != nil {
+			return nil, false, fmt.Errorf("failed to get trade P&L page %d: %w", page, err)
+		}
+		return pnlResp.Data, len(pnlResp.Data) == reportPageSize, nil
+	}, opts)
+}
+
+// GetTradePnL returns every realised trade for a segment and financial year.
+func (m *Manager) GetTradePnL(ctx context.Context, segment, financialYear string) ([]TradePnL, error) {
+	return m.TradePnLIterator(segment, financialYear, IteratorOptions{}).All(ctx)
+}
+
+func (m *Manager) GetTradeCharges(ctx context.Context, segment, financialYear string) (*TradeCharges, error) {
+	q := url.Values{}
+	q.Set("segment", segment)
+	q.Set("financial_year", financialYear)
+
+	req, err := m.newRequest(ctx, "GET", "https://api.upstox.com/v2/trade/profit-loss/charges?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var chargesResp TradeChargesResponse
+	if err := m.do(req, &chargesResp); err != nil {
+		return nil, err
+	}
+
+	return &chargesResp.Data.ChargesBreakdown, nil
+}
+
+type LedgerKind string
+
+const (
+	LedgerCredit LedgerKind = "credit"
+	LedgerDebit  LedgerKind = "debit"
+	LedgerCharge LedgerKind = "charge"
+)
+
+// LedgerEntry is one normalised statement line. Amount is always positive;
+// Kind gives its direction. Charges are reported for the whole year and carry
+// no date.
+type LedgerEntry struct {
+	Date        time.Time
+	Kind        LedgerKind
+	Amount      float64
+	Description string
+	ISIN        string
+}
+
+// GetStatement builds a ledger for a segment and financial year from the
+// reports endpoints: each realised trade becomes a debit for its buy leg and
+// a credit for its sell leg, followed by one charge entry per charge head.
+// Dated entries are sorted by date.
+func (m *Manager) GetStatement(ctx context.Context, segment, financialYear string) ([]LedgerEntry, error) {
+	trades, err := m.GetTradePnL(ctx, segment, financialYear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade P&L: %w", err)
+	}
+	charges, err := m.GetTradeCharges(ctx, segment, financialYear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade charges: %w", err)
+	}
+
+	var entries []LedgerEntry
+	for _, t := range trades {
+		if t.BuyAmount != 0 {
+			entries = append(entries, LedgerEntry{
+				Date:        parseReportDate(t.BuyDate),
+				Kind:        LedgerDebit,
+				Amount:      t.BuyAmount,
+				Description: fmt.Sprintf("Buy %g %s @ %.2f", t.Quantity, t.ScripName, t.BuyAverage),
+				ISIN:        t.ISIN,
+			})
+		}
+		if t.SellAmount != 0 {
+			entries = append(entries, LedgerEntry{
+				Date:        parseReportDate(t.SellDate),
+				Kind:        LedgerCredit,
+				Amount:      t.SellAmount,
+				Description: fmt.Sprintf("Sell %g %s @ %.2f", t.Quantity, t.ScripName, t.SellAverage),
+				ISIN:        t.ISIN,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+
+	heads := []struct {
+		name   string
+		amount float64
+	}{
+		{"Brokerage", charges.Brokerage},
+		{"GST", charges.Taxes.GST},
+		{"STT", charges.Taxes.STT},
+		{"Stamp duty", charges.Taxes.StampDuty},
+		{"Transaction charges", charges.Charges.Transaction},
+		{"Clearing charges", charges.Charges.Clearing},
+		{"IPFT charges", charges.Charges.IPFT},
+		{"SEBI turnover fee", charges.Charges.SEBITurnover},
+		{"Demat transaction charges", charges.Charges.DematTransaction},
+		{"Other charges", charges.Charges.Others},
+	}
+	for _, h := range heads {
+		if h.amount != 0 {
+			entries = append(entries, LedgerEntry{Kind: LedgerCharge, Amount: h.amount, Description: h.name})
+		}
+	}
+
+	return entries, nil
+}
+
+func parseReportDate(s string) time.Time {
+	t, err := time.ParseInLocation("02-01-2006", s, IST)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,131 @@
+package upstox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// apiResponse is implemented by every response envelope so that status and
+// error decoding happen in one place instead of in each endpoint method.
+type apiResponse interface {
+	envelope() (status string, errors []OrderError)
+}
+
+type errorEnvelope struct {
+	Status string       `json:"status"`
+	Errors []OrderError `json:"errors"`
+}
+
+func (m *Manager) newRequest(ctx context.Context, method, url string, payload any) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		reqBody, err := m.codec.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	token, err := m.requestToken(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// do executes req and decodes the JSON envelope into out. Non-2xx responses and
+// envelopes whose status is not "success" are both reported as *APIError.
+func (m *Manager) do(req *http.Request, out apiResponse) error {
+	return m.doWith(m.httpClient, req, out)
+}
+
+func (m *Manager) doWith(client *http.Client, req *http.Request, out apiResponse) error {
+	resp, err := m.send(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		// One retry with a refreshed (or extended) token.
+		if token, ok := m.retryToken(req); ok {
+			if retry, cerr := cloneRequest(req); cerr == nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				retry.Header.Set("Authorization", "Bearer "+token)
+				if err = m.waitRateLimit(retry); err != nil {
+					return fmt.Errorf("failed to make request: %w", err)
+				}
+				if resp, err = m.roundTrip(client, retry); err != nil {
+					return fmt.Errorf("failed to make request: %w", err)
+				}
+				m.noteQuota(resp)
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retryAfter(resp.Header, time.Now()),
+		}
+		if q, ok := parseQuota(resp.Header, time.Now()); ok {
+			apiErr.Quota = &q
+		}
+		var env errorEnvelope
+		if m.codec.Unmarshal(body, &env) == nil {
+			apiErr.Status = env.Status
+			apiErr.Errors = env.Errors
+		}
+		return apiErr
+	}
+
+	if err := m.codec.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if status, errs := out.envelope(); status != "success" {
+		return &APIError{StatusCode: resp.StatusCode, Status: status, Errors: errs}
+	}
+
+	return nil
+}
+
+func (m *Manager) roundTrip(client *http.Client, req *http.Request) (*http.Response, error) {
+	if m.audit != nil && audited(req) {
+		return m.roundTripAudited(client, req)
+	}
+	return client.Do(req)
+}
+
+// cloneRequest copies req with a fresh body so it can be sent again.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body cannot be replayed")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
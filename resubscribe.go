@@ -0,0 +1,111 @@
+package upstox
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscriptionModeOrder fixes the order in which per-mode subscriptions are
+// replayed, so reconnects send the same messages every time.
+var subscriptionModeOrder = []SubscriptionMode{ModeLTPC, ModeFull, ModeOptionGreeks, ModeFullD30}
+
+// SubscribeWithMode subscribes instruments, given as keys or trading symbols,
+// in mode instead of the feed's configured mode. Instruments already
+// subscribed are switched to mode. The mode is remembered and restored on
+// every reconnect.
+func (wsm *WebSocketManager) SubscribeWithMode(mode SubscriptionMode, symbols ...string) error {
+	if !slices.Contains(subscriptionModeOrder, mode) {
+		return fmt.Errorf("unknown subscription mode %q", mode)
+	}
+	keys, err := wsm.resolve(symbols)
+	if err != nil {
+		return err
+	}
+
+	wsm.mu.Lock()
+	var added, changed []string
+	for _, key := range keys {
+		if slices.Contains(added, key) || slices.Contains(changed, key) {
+			continue
+		}
+		if !slices.Contains(wsm.config.InstrumentKeys, key) {
+			added = append(added, key)
+		} else if wsm.modeLocked(key) != mode {
+			changed = append(changed, key)
+		}
+		wsm.setModeLocked(key, mode)
+	}
+	wsm.config.InstrumentKeys = append(slices.Clip(wsm.config.InstrumentKeys), added...)
+	conn := wsm.ws
+	wsm.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	if len(added) > 0 {
+		if err := wsm.sendSubscription(conn, "sub", mode, added); err != nil {
+			return err
+		}
+	}
+	if len(changed) > 0 {
+		return wsm.sendSubscription(conn, "change_mode", mode, changed)
+	}
+	return nil
+}
+
+// modeLocked returns the mode key is subscribed in. Callers hold wsm.mu.
+func (wsm *WebSocketManager) modeLocked(key string) SubscriptionMode {
+	if mode, ok := wsm.modes[key]; ok {
+		return mode
+	}
+	if wsm.config.Mode == "" {
+		return ModeLTPC
+	}
+	return wsm.config.Mode
+}
+
+// setModeLocked records a per-instrument mode; only modes that differ from
+// the configured one are stored. Callers hold wsm.mu.
+func (wsm *WebSocketManager) setModeLocked(key string, mode SubscriptionMode) {
+	if mode == "" {
+		mode = ModeLTPC
+	}
+	delete(wsm.modes, key)
+	if mode == wsm.modeLocked(key) {
+		return
+	}
+	if wsm.modes == nil {
+		wsm.modes = make(map[string]SubscriptionMode)
+	}
+	wsm.modes[key] = mode
+}
+
+// subscriptionGroupsLocked splits the configured instruments by mode.
+// Callers hold wsm.mu.
+func (wsm *WebSocketManager) subscriptionGroupsLocked() map[SubscriptionMode][]string {
+	groups := make(map[SubscriptionMode][]string)
+	for _, key := range wsm.config.InstrumentKeys {
+		mode := wsm.modeLocked(key)
+		groups[mode] = append(groups[mode], key)
+	}
+	return groups
+}
+
+// resubscribe sends every configured instrument on conn in the mode it was
+// last subscribed in, one message per mode.
+func (wsm *WebSocketManager) resubscribe(conn *websocket.Conn) error {
+	wsm.mu.RLock()
+	groups := wsm.subscriptionGroupsLocked()
+	wsm.mu.RUnlock()
+
+	for _, mode := range subscriptionModeOrder {
+		if keys := groups[mode]; len(keys) > 0 {
+			if err := wsm.sendSubscription(conn, "sub", mode, keys); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
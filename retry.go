@@ -0,0 +1,184 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy retries GET requests that failed transiently: timeouts,
+// connection resets and 500/502/503/504 responses. Order placement and other
+// writes are never retried, since a lost response does not mean the broker
+// did not act on them. A 429, on the other hand, means the request was not
+// processed, so RateLimitRetries applies to every method. Retries are
+// rate limited like first attempts.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries per call after the first attempt.
+	MaxRetries int
+	// BaseDelay is the wait before the first retry, doubling for each one
+	// after it up to MaxDelay, with up to 20% jitter. Zero means 200ms and
+	// 5s.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Budget caps retries across all calls per BudgetWindow (one minute if
+	// zero), so an outage does not multiply the load on the API. Zero means
+	// no cap.
+	Budget       int
+	BudgetWindow time.Duration
+	// RateLimitRetries is the number of times a 429 response is retried
+	// after waiting out its Retry-After header, or the backoff delay if it
+	// has none. A response asking for longer than MaxRateLimitWait (MaxDelay
+	// if zero) is returned to the caller instead.
+	RateLimitRetries int
+	MaxRateLimitWait time.Duration
+}
+
+// WithRetryPolicy enables automatic retries of idempotent calls.
+func WithRetryPolicy(p RetryPolicy) ManagerOption {
+	return func(m *Manager) {
+		if p.BaseDelay <= 0 {
+			p.BaseDelay = 200 * time.Millisecond
+		}
+		if p.MaxDelay <= 0 {
+			p.MaxDelay = 5 * time.Second
+		}
+		if p.BudgetWindow <= 0 {
+			p.BudgetWindow = time.Minute
+		}
+		if p.MaxRateLimitWait <= 0 {
+			p.MaxRateLimitWait = p.MaxDelay
+		}
+		m.retry = &retrier{policy: p}
+	}
+}
+
+type retrier struct {
+	policy RetryPolicy
+
+	mu          sync.Mutex
+	windowStart time.Time
+	spent       int
+}
+
+// take reports whether the budget allows another retry, and spends it.
+func (r *retrier) take() bool {
+	if r.policy.Budget <= 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.Sub(r.windowStart) >= r.policy.BudgetWindow {
+		r.windowStart, r.spent = now, 0
+	}
+	if r.spent >= r.policy.Budget {
+		return false
+	}
+	r.spent++
+	return true
+}
+
+func (r *retrier) delay(retry int) time.Duration {
+	d := r.policy.BaseDelay << retry
+	if d <= 0 || d > r.policy.MaxDelay {
+		d = r.policy.MaxDelay
+	}
+	return time.Duration(float64(d) * (1 + 0.2*rand.Float64()))
+}
+
+// next decides whether the outcome of attempt retry+1 should be retried, and
+// after how long.
+func (r *retrier) next(req *http.Request, resp *http.Response, err error, retry int) (time.Duration, bool) {
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		if retry >= r.policy.RateLimitRetries {
+			return 0, false
+		}
+		wait := retryAfter(resp.Header, time.Now())
+		if wait <= 0 {
+			wait = r.delay(retry)
+		}
+		return wait, wait <= r.policy.MaxRateLimitWait
+	}
+	if req.Method != http.MethodGet || retry >= r.policy.MaxRetries || !retryable(req, resp, err) {
+		return 0, false
+	}
+	return r.delay(retry), true
+}
+
+// send performs req, retrying transient failures of idempotent requests per
+// the Manager's RetryPolicy. Every attempt, retries included, first waits for
+// the rate limiter, so retries count against the same per-endpoint limits.
+func (m *Manager) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := m.waitRateLimit(req); err != nil {
+		return nil, err
+	}
+	resp, err := m.roundTrip(client, req)
+	m.noteQuota(resp)
+	if m.retry == nil {
+		return resp, err
+	}
+
+	for retry := 0; ; retry++ {
+		wait, ok := m.retry.next(req, resp, err, retry)
+		if !ok || !m.retry.take() {
+			break
+		}
+		next, cerr := cloneRequest(req)
+		if cerr != nil {
+			break
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if werr := sleepCtx(req.Context(), wait); werr != nil {
+			return nil, werr
+		}
+		if werr := m.waitRateLimit(next); werr != nil {
+			return nil, werr
+		}
+		m.logger.Debug("retrying request", "url", req.URL.String(), "retry", retry+1, "wait", wait)
+		resp, err = m.roundTrip(client, next)
+		m.noteQuota(resp)
+	}
+	return resp, err
+}
+
+// retryable reports whether a failed attempt is worth repeating. Timeouts
+// count, including http.Client.Timeout, unless the caller's context is the
+// one that ran out.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout() ||
+			errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,119 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func fastRetries(p RetryPolicy) ManagerOption {
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return WithRetryPolicy(p)
+}
+
+func TestRetryTransientGET(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		switch n {
+		case 1:
+			return jsonResponse(req, http.StatusServiceUnavailable, `{"status":"error"}`), nil
+		case 2:
+			return nil, syscall.ECONNRESET
+		}
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":[]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), fastRetries(RetryPolicy{MaxRetries: 3}))
+
+	if _, err := m.GetPositions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := stub.count(); got != 3 {
+		t.Errorf("%d attempts, want 3", got)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusBadGateway, `{"status":"error"}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), fastRetries(RetryPolicy{MaxRetries: 2}))
+
+	_, err := m.GetPositions(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("error = %v, want a 502 APIError", err)
+	}
+	if got := stub.count(); got != 3 {
+		t.Errorf("%d attempts, want 3", got)
+	}
+}
+
+func TestRetrySkipsOrders(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusServiceUnavailable, `{"status":"error"}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), fastRetries(RetryPolicy{MaxRetries: 3}))
+
+	if _, err := m.PlaceMarketOrder(context.Background(), "NSE_EQ|X", 1, "BUY"); err == nil {
+		t.Fatal("order succeeded")
+	}
+	if got := stub.count(); got != 1 {
+		t.Errorf("order sent %d times, want once", got)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusServiceUnavailable, `{"status":"error"}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), fastRetries(RetryPolicy{MaxRetries: 5, Budget: 2}))
+
+	m.GetPositions(context.Background())
+	m.GetPositions(context.Background())
+	// Two calls and two retries between them.
+	if got := stub.count(); got != 4 {
+		t.Errorf("%d attempts, want 4", got)
+	}
+}
+
+func TestRetryRateLimitedOrder(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		if n == 1 {
+			resp := jsonResponse(req, http.StatusTooManyRequests, `{"status":"error"}`)
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_ids":["1"]}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), fastRetries(RetryPolicy{RateLimitRetries: 1}))
+
+	if _, err := m.PlaceMarketOrder(context.Background(), "NSE_EQ|X", 1, "BUY"); err != nil {
+		t.Fatal(err)
+	}
+	if got := stub.countMethod("POST"); got != 2 {
+		t.Errorf("%d attempts, want 2", got)
+	}
+}
+
+func TestRetryableTimeouts(t *testing.T) {
+	timeout := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, context.DeadlineExceeded
+	})
+	client := &http.Client{Transport: timeout}
+
+	req, _ := http.NewRequest("GET", "https://api.upstox.com/v2/x", nil)
+	_, err := client.Do(req)
+	if !retryable(req, nil, err) {
+		t.Errorf("client timeout %v not retryable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ = http.NewRequestWithContext(ctx, "GET", "https://api.upstox.com/v2/x", nil)
+	if retryable(req, nil, context.Canceled) {
+		t.Error("retrying after the caller's context ended")
+	}
+}
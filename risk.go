@@ -0,0 +1,236 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrRiskLimit = errors.New("risk limit exceeded")
+
+// RiskLimits are the checks a RiskManager applies before placing an order.
+// A zero field disables its check.
+type RiskLimits struct {
+	// MaxOrderValue caps price × quantity for one order. Market orders are
+	// valued at the last traded price, stop-market orders at the trigger.
+	MaxOrderValue float64
+	// MaxQuantity caps the net position, long or short, an order may leave
+	// in any one instrument once it and the working orders on its side fill.
+	MaxQuantity int
+	// MaxOpenPositions caps how many instruments may have an open position
+	// or a working order.
+	MaxOpenPositions int
+	// DailyLossLimit is the loss, as a positive amount, at which the day's
+	// realised plus unrealised P&L pulls the kill switch.
+	DailyLossLimit float64
+	// FlattenOnLoss closes every position when DailyLossLimit trips.
+	FlattenOnLoss bool
+}
+
+// RiskManager places orders only if they stay within its limits. Every check
+// is made against the broker's current positions and working orders, two
+// requests per order, and orders placed through one RiskManager are checked
+// and placed one at a time so that concurrent orders cannot each pass on the
+// same exposure. Orders that only reduce an existing position are always let
+// through, even after the kill switch, so positions can be exited after a
+// limit is hit.
+//
+// Orders placed through the Manager directly bypass the limits, but not the
+// kill switch: KillSwitch halts the Manager itself.
+type RiskManager struct {
+	m *Manager
+
+	mu     sync.Mutex
+	limits RiskLimits
+
+	// placeMu serialises check and placement.
+	placeMu sync.Mutex
+}
+
+func (m *Manager) NewRiskManager(limits RiskLimits) *RiskManager {
+	return &RiskManager{m: m, limits: limits}
+}
+
+func (r *RiskManager) Limits() RiskLimits {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limits
+}
+
+func (r *RiskManager) SetLimits(limits RiskLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+}
+
+// PlaceOrder checks orderReq against the limits and places it.
+func (r *RiskManager) PlaceOrder(ctx context.Context, orderReq OrderRequest) (*OrderResponse, error) {
+	r.placeMu.Lock()
+	defer r.placeMu.Unlock()
+
+	reduceOnly, err := r.check(ctx, orderReq)
+	if err != nil {
+		return nil, err
+	}
+	if reduceOnly {
+		return r.m.submitOrder(ctx, orderReq)
+	}
+	return r.m.placeOrder(ctx, orderReq)
+}
+
+func (r *RiskManager) PlaceLimitOrder(ctx context.Context, instrumentToken string, quantity int, side string, price float64) (*OrderResponse, error) {
+	orderReq := marketOrderRequest(instrumentToken, quantity, side)
+	orderReq.OrderType = string(OrderTypeLimit)
+	orderReq.Price = price
+	return r.PlaceOrder(ctx, orderReq)
+}
+
+func (r *RiskManager) PlaceMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string) (*OrderResponse, error) {
+	return r.PlaceOrder(ctx, marketOrderRequest(instrumentToken, quantity, side))
+}
+
+// Watch also trips DailyLossLimit from t's streaming P&L, without waiting
+// for the next order. Positions are flattened with ctx.
+func (r *RiskManager) Watch(ctx context.Context, t *PositionTracker) {
+	t.OnThreshold(func(s PortfolioSnapshot) bool {
+		limit := r.Limits().DailyLossLimit
+		return limit > 0 && s.Total <= -limit
+	}, func(s PortfolioSnapshot) {
+		if _, _, halted := r.m.Halted(); !halted {
+			go r.trip(ctx, s.Total, r.Limits())
+		}
+	})
+}
+
+// Check reports whether orderReq would be placed. A breached limit is
+// returned as an error wrapping ErrRiskLimit; a tripped daily loss limit, or
+// an earlier KillSwitch, as one wrapping ErrTradingHalted.
+func (r *RiskManager) Check(ctx context.Context, orderReq OrderRequest) error {
+	_, err := r.check(ctx, orderReq)
+	return err
+}
+
+// check applies the limits and reports whether orderReq only reduces a
+// position, counting orders still working as if they had filled.
+func (r *RiskManager) check(ctx context.Context, orderReq OrderRequest) (reduceOnly bool, err error) {
+	limits := r.Limits()
+
+	positions, err := r.m.GetPositions(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get positions: %w", err)
+	}
+	orders, err := r.m.GetOrderBook(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	var current int
+	var pnl float64
+	open := make(map[string]bool)
+	for _, pos := range positions {
+		pnl += pos.Realised + pos.Unrealised
+		if pos.Quantity == 0 {
+			continue
+		}
+		if pos.InstrumentToken == orderReq.InstrumentToken {
+			current += pos.Quantity
+		}
+		open[pos.InstrumentToken] = true
+	}
+	if limits.DailyLossLimit > 0 && pnl <= -limits.DailyLossLimit {
+		if _, _, halted := r.m.Halted(); !halted {
+			r.trip(ctx, pnl, limits)
+		}
+	}
+
+	buy := isBuy(orderReq.TransactionType)
+	var working int
+	for i := range orders {
+		o := &orders[i]
+		if !isWorking(o) {
+			continue
+		}
+		open[o.InstrumentToken] = true
+		if o.InstrumentToken == orderReq.InstrumentToken && isBuy(o.TransactionType) == buy {
+			working += workingQuantity(o)
+		}
+	}
+
+	delta := orderReq.Quantity + working
+	if !buy {
+		delta = -delta
+	}
+	next := current + delta
+	if current != 0 && abs(next) <= abs(current) && (next == 0 || (next > 0) == (current > 0)) {
+		return true, nil
+	}
+
+	if err := r.m.checkHalt(); err != nil {
+		return false, err
+	}
+	if limits.MaxQuantity > 0 && abs(next) > limits.MaxQuantity {
+		return false, fmt.Errorf("%w: position in %s could reach %d, limit %d", ErrRiskLimit, orderReq.InstrumentToken, next, limits.MaxQuantity)
+	}
+	if limits.MaxOpenPositions > 0 && !open[orderReq.InstrumentToken] && len(open) >= limits.MaxOpenPositions {
+		return false, fmt.Errorf("%w: %d positions open or pending, limit %d", ErrRiskLimit, len(open), limits.MaxOpenPositions)
+	}
+	if limits.MaxOrderValue > 0 {
+		value, err := r.orderValue(ctx, orderReq)
+		if err != nil {
+			return false, err
+		}
+		if value > limits.MaxOrderValue {
+			return false, fmt.Errorf("%w: order value %.2f, limit %.2f", ErrRiskLimit, value, limits.MaxOrderValue)
+		}
+	}
+	return false, nil
+}
+
+// workingQuantity is the part of a working order still to fill.
+func workingQuantity(o *Order) int {
+	if o.PendingQuantity > 0 {
+		return o.PendingQuantity
+	}
+	return max(o.Quantity-o.FilledQuantity, 0)
+}
+
+func (r *RiskManager) orderValue(ctx context.Context, orderReq OrderRequest) (float64, error) {
+	price := orderReq.Price
+	if price == 0 {
+		price = orderReq.TriggerPrice
+	}
+	if price == 0 {
+		prices, err := r.m.GetLTP(ctx, orderReq.InstrumentToken)
+		if err != nil {
+			return 0, fmt.Errorf("failed to price order: %w", err)
+		}
+		price = prices[orderReq.InstrumentToken]
+		if price == 0 {
+			return 0, fmt.Errorf("no last price for %s", orderReq.InstrumentToken)
+		}
+	}
+	return price * float64(orderReq.Quantity), nil
+}
+
+func (r *RiskManager) trip(ctx context.Context, pnl float64, limits RiskLimits) {
+	r.m.logger.Error("daily loss limit hit", "pnl", pnl, "limit", limits.DailyLossLimit)
+	if _, err := r.KillSwitch(ctx, fmt.Sprintf("daily loss limit: P&L %.2f", pnl), limits.FlattenOnLoss); err != nil {
+		r.m.logger.Error("failed to flatten positions", "error", err)
+	}
+}
+
+// KillSwitch halts the Manager, blocking every new order however it is
+// placed other than reduce-only orders through a RiskManager, and if flatten
+// is set closes all positions. Call Manager.Resume to trade again.
+func (r *RiskManager) KillSwitch(ctx context.Context, reason string, flatten bool) ([]OrderResponse, error) {
+	r.m.Halt(reason)
+	if !flatten {
+		return nil, nil
+	}
+	responses, err := r.m.CloseAllPositions(ctx)
+	if err != nil {
+		return responses, fmt.Errorf("failed to close positions: %w", err)
+	}
+	return responses, nil
+}
@@ -0,0 +1,201 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrScheduleNotFound = errors.New("scheduled order not found")
+
+type ScheduleState string
+
+const (
+	SchedulePending   ScheduleState = "pending"
+	SchedulePlaced    ScheduleState = "placed"
+	ScheduleFailed    ScheduleState = "failed"
+	ScheduleCancelled ScheduleState = "cancelled"
+)
+
+// ScheduledOrder is a snapshot of an order registered with ScheduleOrder.
+type ScheduledOrder struct {
+	ID       string
+	At       time.Time
+	Request  OrderRequest
+	State    ScheduleState
+	FiredAt  time.Time
+	Response *OrderResponse
+	Err      error
+}
+
+// schedulePrewarmLead is how long before At connections are prewarmed; a
+// second timer then waits out the rest, so placement starts within timer
+// granularity of At.
+const schedulePrewarmLead = 3 * time.Second
+
+type orderScheduler struct {
+	mu     sync.Mutex
+	orders map[string]*scheduledEntry
+}
+
+type scheduledEntry struct {
+	ScheduledOrder
+	ctx   context.Context
+	timer *time.Timer
+}
+
+// ScheduleOrder places orderReq at the given time and returns an ID for
+// CancelScheduledOrder and ScheduledOrder. The request is validated now, and
+// at must fall inside the instrument's session, or inside the AMO window for
+// an order with IsAMO set. Exchange holidays are not checked. The order is
+// placed with ctx, so cancelling ctx first makes it fail.
+func (m *Manager) ScheduleOrder(ctx context.Context, at time.Time, orderReq OrderRequest) (string, error) {
+	if err := validateOrderRequest(orderReq); err != nil {
+		return "", fmt.Errorf("invalid order: %w", err)
+	}
+	if !at.After(time.Now()) {
+		return "", fmt.Errorf("scheduled time %s is in the past", at.Format(time.RFC3339Nano))
+	}
+	if err := checkScheduleWindow(at, orderReq); err != nil {
+		return "", err
+	}
+
+	id, err := generateGUID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate schedule ID: %w", err)
+	}
+
+	entry := &scheduledEntry{ScheduledOrder: ScheduledOrder{
+		ID:      id,
+		At:      at,
+		Request: orderReq,
+		State:   SchedulePending,
+	}, ctx: ctx}
+
+	s := &m.scheduler
+	s.mu.Lock()
+	if s.orders == nil {
+		s.orders = make(map[string]*scheduledEntry)
+	}
+	s.orders[id] = entry
+	entry.timer = time.AfterFunc(time.Until(at)-schedulePrewarmLead, func() { m.fireScheduled(entry) })
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+func checkScheduleWindow(at time.Time, orderReq OrderRequest) error {
+	open := inSession(orderReq.InstrumentToken, at)
+	switch {
+	case orderReq.IsAMO && open:
+		return fmt.Errorf("AMO order scheduled for %s falls inside market hours", at.In(IST).Format("15:04:05.000"))
+	case orderReq.IsAMO && !inAMOWindow(at):
+		return fmt.Errorf("AMO order scheduled for %s falls outside the AMO window", at.In(IST).Format("15:04:05.000"))
+	case !orderReq.IsAMO && !open:
+		return fmt.Errorf("order scheduled for %s falls outside market hours; set IsAMO to queue it", at.In(IST).Format("Mon 15:04:05.000"))
+	}
+	return nil
+}
+
+func (m *Manager) fireScheduled(entry *scheduledEntry) {
+	if !m.schedulePending(entry) {
+		return
+	}
+	ctx := entry.ctx
+
+	if err := m.PrewarmOrderPath(ctx, 1); err != nil {
+		m.logger.Warn("scheduled order: prewarm failed", "schedule_id", entry.ID, "error", err)
+	}
+
+	if d := time.Until(entry.At); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			s := &m.scheduler
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if entry.State == SchedulePending {
+				entry.State = ScheduleFailed
+				entry.Err = ctx.Err()
+			}
+			return
+		}
+	}
+
+	s := &m.scheduler
+	s.mu.Lock()
+	if entry.State != SchedulePending {
+		s.mu.Unlock()
+		return
+	}
+	entry.FiredAt = time.Now()
+	s.mu.Unlock()
+
+	resp, err := m.placeOrder(ctx, entry.Request)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.Response = resp
+	entry.Err = err
+	entry.State = SchedulePlaced
+	if err != nil {
+		entry.State = ScheduleFailed
+	}
+}
+
+func (m *Manager) schedulePending(entry *scheduledEntry) bool {
+	m.scheduler.mu.Lock()
+	defer m.scheduler.mu.Unlock()
+	return entry.State == SchedulePending
+}
+
+// CancelScheduledOrder stops a pending scheduled order. Once placement has
+// started it can no longer be cancelled this way.
+func (m *Manager) CancelScheduledOrder(id string) error {
+	s := &m.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.orders[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+	if entry.State != SchedulePending || !entry.FiredAt.IsZero() {
+		return fmt.Errorf("scheduled order %s is already %s", id, entry.State)
+	}
+	entry.timer.Stop()
+	entry.State = ScheduleCancelled
+	return nil
+}
+
+func (m *Manager) ScheduledOrder(id string) (ScheduledOrder, bool) {
+	s := &m.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.orders[id]
+	if !ok {
+		return ScheduledOrder{}, false
+	}
+	return entry.ScheduledOrder, true
+}
+
+// ScheduledOrders returns every scheduled order, including finished ones,
+// sorted by scheduled time.
+func (m *Manager) ScheduledOrders() []ScheduledOrder {
+	s := &m.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]ScheduledOrder, 0, len(s.orders))
+	for _, entry := range s.orders {
+		out = append(out, entry.ScheduledOrder)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
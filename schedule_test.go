@@ -0,0 +1,107 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleWindow(t *testing.T) {
+	monday := func(hour, min int) time.Time { return time.Date(2026, 10, 12, hour, min, 0, 0, IST) }
+	req := marketOrderRequest("NSE_EQ|X", 1, "BUY")
+	amo := req
+	amo.IsAMO = true
+
+	tests := []struct {
+		at    time.Time
+		req   OrderRequest
+		valid bool
+	}{
+		{monday(9, 15), req, true},
+		{monday(9, 14), req, false},
+		{monday(15, 30), req, false},
+		{monday(10, 0), amo, false},
+		{monday(17, 0), amo, true},
+		{monday(8, 30), amo, true},
+		{monday(16, 0).AddDate(0, 0, 5), req, false},
+	}
+	for _, tt := range tests {
+		if err := checkScheduleWindow(tt.at, tt.req); (err == nil) != tt.valid {
+			t.Errorf("%s (AMO %v): err = %v, want valid %v", tt.at.Format(time.DateTime), tt.req.IsAMO, err, tt.valid)
+		}
+	}
+}
+
+// scheduleEntry registers a pending entry the way ScheduleOrder does, minus
+// the session check and the timer, for the test to fire.
+func scheduleEntry(ctx context.Context, m *Manager, at time.Time) *scheduledEntry {
+	entry := &scheduledEntry{ScheduledOrder: ScheduledOrder{
+		ID:      "S1",
+		At:      at,
+		Request: marketOrderRequest("NSE_EQ|X", 1, "BUY"),
+		State:   SchedulePending,
+	}, ctx: ctx, timer: time.NewTimer(time.Hour)}
+	m.scheduler.orders = map[string]*scheduledEntry{entry.ID: entry}
+	return entry
+}
+
+func TestScheduledOrderFiresAtTime(t *testing.T) {
+	stub := orderAPI(nil)
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	at := time.Now().Add(50 * time.Millisecond)
+	m.fireScheduled(scheduleEntry(ctx, m, at))
+
+	got, ok := m.ScheduledOrder("S1")
+	if !ok || got.State != SchedulePlaced || got.Err != nil {
+		t.Fatalf("scheduled order = %+v", got)
+	}
+	if got.FiredAt.Before(at) {
+		t.Errorf("fired %v early", at.Sub(got.FiredAt))
+	}
+	if got.Response == nil || got.Response.Data.OrderIDs[0] != "1" {
+		t.Errorf("response = %+v", got.Response)
+	}
+}
+
+func TestScheduledOrderCancelled(t *testing.T) {
+	stub := orderAPI(nil)
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+	entry := scheduleEntry(context.Background(), m, time.Now().Add(time.Hour))
+
+	if err := m.CancelScheduledOrder("S1"); err != nil {
+		t.Fatal(err)
+	}
+	m.fireScheduled(entry)
+	if got, _ := m.ScheduledOrder("S1"); got.State != ScheduleCancelled {
+		t.Errorf("state = %s", got.State)
+	}
+	if err := m.CancelScheduledOrder("S1"); err == nil {
+		t.Error("cancelled a scheduled order twice")
+	}
+	if err := m.CancelScheduledOrder("S2"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("err = %v, want ErrScheduleNotFound", err)
+	}
+	if n := stub.count(); n != 0 {
+		t.Errorf("%d requests sent", n)
+	}
+}
+
+func TestScheduledOrderContextDone(t *testing.T) {
+	stub := orderAPI(nil)
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The wait for At ends with ctx rather than an hour later.
+	m.fireScheduled(scheduleEntry(ctx, m, time.Now().Add(time.Hour)))
+	if got, _ := m.ScheduledOrder("S1"); got.State != ScheduleFailed || !errors.Is(got.Err, context.Canceled) {
+		t.Errorf("scheduled order = %+v", got)
+	}
+	if n := stub.count(); n != 0 {
+		t.Errorf("%d requests sent", n)
+	}
+}
@@ -0,0 +1,70 @@
+package upstox
+
+import (
+	"strings"
+	"time"
+)
+
+// IST is the exchanges' time zone; all session times are expressed in it.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+type sessionHours struct {
+	preOpen time.Duration
+	open    time.Duration
+	close   time.Duration
+}
+
+var (
+	equityHours    = sessionHours{preOpen: 9 * time.Hour, open: 9*time.Hour + 15*time.Minute, close: 15*time.Hour + 30*time.Minute}
+	commodityHours = sessionHours{open: 9 * time.Hour, close: 23*time.Hour + 30*time.Minute}
+	currencyHours  = sessionHours{open: 9 * time.Hour, close: 17 * time.Hour}
+)
+
+// Upstox accepts after-market orders from amoOpen until amoCutoff the next
+// trading morning.
+const (
+	amoOpen   = 16 * time.Hour
+	amoCutoff = 8*time.Hour + 59*time.Minute
+)
+
+// hoursFor picks the regular session for an instrument from the segment
+// prefix of its key, e.g. "NSE_EQ|INE..." or "MCX_FO|...".
+func hoursFor(instrumentKey string) sessionHours {
+	segment, _, _ := strings.Cut(instrumentKey, "|")
+	switch segment {
+	case "MCX_FO", "NSE_COM":
+		return commodityHours
+	case "NCD_FO", "BCD_FO":
+		return currencyHours
+	}
+	return equityHours
+}
+
+// sinceMidnight returns t's IST wall-clock offset from midnight.
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.In(IST)
+	y, mo, d := t.Date()
+	return t.Sub(time.Date(y, mo, d, 0, 0, 0, 0, IST))
+}
+
+func isWeekend(t time.Time) bool {
+	wd := t.In(IST).Weekday()
+	return wd == time.Saturday || wd == time.Sunday
+}
+
+// inSession reports whether t falls inside the regular session for
+// instrumentKey. Exchange holidays are not considered.
+func inSession(instrumentKey string, t time.Time) bool {
+	if isWeekend(t) {
+		return false
+	}
+	h := hoursFor(instrumentKey)
+	off := sinceMidnight(t)
+	return off >= h.open && off < h.close
+}
+
+// inAMOWindow reports whether an after-market order may be placed at t.
+func inAMOWindow(t time.Time) bool {
+	off := sinceMidnight(t)
+	return off >= amoOpen || off < amoCutoff
+}
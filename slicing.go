@@ -0,0 +1,143 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SliceReport describes how PlaceSliced split an order. Children holds one
+// entry per child order placed, in order; a failed child ends the list.
+type SliceReport struct {
+	Request OrderRequest
+
+	// MaxChild is the largest child quantity used, or zero when the order
+	// went out whole or was sliced by the API.
+	MaxChild int
+	// ServerSliced is set when the API did the slicing.
+	ServerSliced bool
+
+	Children []LegResult
+}
+
+// Placed is the total quantity of the children that were accepted.
+func (r *SliceReport) Placed() int {
+	total := 0
+	for _, c := range r.Children {
+		if c.Err == nil {
+			total += c.Request.Quantity
+		}
+	}
+	return total
+}
+
+// PlaceSliced places an order too large for one exchange order as several
+// children, each a lot multiple below the instrument's freeze quantity from
+// WithInstrumentLookup. With Slice set and the v3 order API in use, the API
+// slices the order itself and PlaceSliced only reports the children it
+// created. Orders under the freeze quantity, and instruments without one,
+// go out as a single child; an order at or above a freeze quantity that no
+// lot multiple fits below is refused.
+//
+// Children are placed one at a time and each is looked up for its status.
+// Placement stops at the first child that fails or is rejected; the children
+// already placed are left working, and the returned error wraps
+// ErrLegFailed.
+func (m *Manager) PlaceSliced(ctx context.Context, orderReq OrderRequest) (*SliceReport, error) {
+	if err := validateOrderRequest(orderReq); err != nil {
+		return nil, fmt.Errorf("invalid order: %w", err)
+	}
+
+	report := &SliceReport{Request: orderReq}
+	if orderReq.Slice && !m.dryRun && m.paper == nil {
+		if _, v, err := m.endpointURL(ctx, EndpointPlaceOrder); err == nil && v == V3 {
+			report.ServerSliced = true
+			return report, m.placeServerSliced(ctx, report)
+		}
+	}
+
+	if m.instruments == nil {
+		return nil, errors.New("no instrument lookup configured; use WithInstrumentLookup")
+	}
+	inst, ok := m.instruments.ByKey(orderReq.InstrumentToken)
+	if !ok {
+		return nil, fmt.Errorf("unknown instrument %s", orderReq.InstrumentToken)
+	}
+
+	maxChild := maxSliceQuantity(inst)
+	if maxChild == 0 && inst.FreezeQuantity > 0 && float64(orderReq.Quantity) >= inst.FreezeQuantity {
+		return nil, fmt.Errorf("cannot slice %s: no multiple of lot size %d is below its freeze quantity %v",
+			orderReq.InstrumentToken, inst.LotSize, inst.FreezeQuantity)
+	}
+
+	quantities := []int{orderReq.Quantity}
+	if maxChild > 0 && orderReq.Quantity > maxChild {
+		report.MaxChild = maxChild
+		quantities = sliceQuantities(orderReq.Quantity, maxChild)
+	}
+
+	for i, qty := range quantities {
+		child := orderReq
+		child.Quantity = qty
+		child.Slice = false
+		if child.DisclosedQuantity > qty {
+			child.DisclosedQuantity = qty
+		}
+		// Equal children would otherwise be suppressed as duplicates.
+		child.Force = true
+
+		result := m.placeLeg(ctx, child)
+		report.Children = append(report.Children, result)
+		if result.Err != nil {
+			return report, fmt.Errorf("%w: child %d of %d (%d of %d): %v", ErrLegFailed, i+1, len(quantities), qty, orderReq.Quantity, result.Err)
+		}
+	}
+	return report, nil
+}
+
+// placeServerSliced places the order once and reports every order the API
+// split it into.
+func (m *Manager) placeServerSliced(ctx context.Context, report *SliceReport) error {
+	resp, err := m.placeOrder(ctx, report.Request)
+	if err != nil {
+		return err
+	}
+
+	for _, orderID := range resp.Data.OrderIDs {
+		result := LegResult{Request: report.Request, OrderID: orderID}
+		if order := m.confirmLeg(ctx, &result); order != nil {
+			result.Request.Quantity = order.Quantity
+		}
+		report.Children = append(report.Children, result)
+	}
+	for i, c := range report.Children {
+		if c.Err != nil {
+			return fmt.Errorf("%w: child %d of %d: %v", ErrLegFailed, i+1, len(report.Children), c.Err)
+		}
+	}
+	return nil
+}
+
+// maxSliceQuantity is the largest lot multiple the exchange accepts in one
+// order: the freeze quantity itself is frozen, so strictly below it. It is
+// zero when the instrument has no freeze quantity or its lot size is not
+// below it.
+func maxSliceQuantity(inst Instrument) int {
+	freeze := int(inst.FreezeQuantity)
+	if freeze <= 0 {
+		return 0
+	}
+	lot := max(inst.LotSize, 1)
+	return (freeze - 1) / lot * lot
+}
+
+// sliceQuantities splits total into children of at most maxChild, the last
+// one taking the remainder.
+func sliceQuantities(total, maxChild int) []int {
+	var out []int
+	for total > maxChild {
+		out = append(out, maxChild)
+		total -= maxChild
+	}
+	return append(out, total)
+}
@@ -0,0 +1,46 @@
+package upstox
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type instrumentMap map[string]Instrument
+
+func (m instrumentMap) ByKey(key string) (Instrument, bool) {
+	inst, ok := m[key]
+	return inst, ok
+}
+
+func (m instrumentMap) BySymbol(symbol string) []Instrument { return nil }
+
+func TestPlaceSliced(t *testing.T) {
+	ctx := context.Background()
+	lookup := instrumentMap{
+		"NSE_FO|A": {InstrumentKey: "NSE_FO|A", LotSize: 75, FreezeQuantity: 1800, TickSize: 0.05},
+		"NSE_FO|B": {InstrumentKey: "NSE_FO|B", LotSize: 900, FreezeQuantity: 900, TickSize: 0.05},
+	}
+	m := NewPaperManager("id", "secret", "token", WithInstrumentLookup(lookup))
+	m.Paper().OnTick(paperTick("NSE_FO|A", 100))
+	m.Paper().OnTick(paperTick("NSE_FO|B", 100))
+
+	report, err := m.PlaceSliced(ctx, marketOrderRequest("NSE_FO|A", 3750, "BUY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, c := range report.Children {
+		got = append(got, c.Request.Quantity)
+	}
+	if want := []int{1725, 1725, 300}; !slices.Equal(got, want) || report.MaxChild != 1725 || report.Placed() != 3750 {
+		t.Errorf("children = %v, MaxChild = %d, placed = %d", got, report.MaxChild, report.Placed())
+	}
+
+	if _, err := m.PlaceSliced(ctx, marketOrderRequest("NSE_FO|B", 900, "BUY")); err == nil {
+		t.Error("placed an order at a freeze quantity no lot fits below")
+	}
+	if orders, _ := m.GetOrderBook(ctx); len(orders) != 3 {
+		t.Errorf("order book has %d orders, want 3", len(orders))
+	}
+}
@@ -0,0 +1,90 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+)
+
+var ErrPriceMoved = errors.New("price moved beyond slippage limit")
+
+// PriceSource supplies the most recent traded price for an instrument, e.g.
+// from a live feed cache. ok is false when no price is known yet.
+type PriceSource interface {
+	LastPrice(instrumentKey string) (price float64, ok bool)
+}
+
+// SlippageGuard aborts a market order when the current LTP has moved more than
+// MaxDeviationPct percent away from ReferencePrice. When Source is nil, or has
+// no price for the instrument, the LTP is fetched over REST.
+type SlippageGuard struct {
+	ReferencePrice  float64
+	MaxDeviationPct float64
+	Source          PriceSource
+}
+
+func (m *Manager) PlaceGuardedMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string, guard SlippageGuard) (*OrderResponse, error) {
+	if err := m.checkSlippage(ctx, instrumentToken, guard); err != nil {
+		return nil, err
+	}
+	return m.PlaceMarketOrder(ctx, instrumentToken, quantity, side)
+}
+
+func (m *Manager) checkSlippage(ctx context.Context, instrumentToken string, guard SlippageGuard) error {
+	if guard.ReferencePrice <= 0 {
+		return fmt.Errorf("slippage guard requires a positive reference price")
+	}
+	if guard.MaxDeviationPct <= 0 {
+		return fmt.Errorf("slippage guard requires a positive max deviation")
+	}
+
+	var ltp float64
+	var ok bool
+	if guard.Source != nil {
+		ltp, ok = guard.Source.LastPrice(instrumentToken)
+	}
+	if !ok {
+		prices, err := m.getLTPQuotes(ctx, []string{instrumentToken})
+		if err != nil {
+			return fmt.Errorf("failed to get LTP for slippage check: %w", err)
+		}
+		if ltp, ok = prices[instrumentToken]; !ok {
+			return fmt.Errorf("no LTP returned for %s", instrumentToken)
+		}
+	}
+
+	deviation := math.Abs(ltp-guard.ReferencePrice) / guard.ReferencePrice * 100
+	if deviation > guard.MaxDeviationPct {
+		return fmt.Errorf("%w: %s LTP %.2f is %.2f%% from reference %.2f (limit %.2f%%)",
+			ErrPriceMoved, instrumentToken, ltp, deviation, guard.ReferencePrice, guard.MaxDeviationPct)
+	}
+
+	return nil
+}
+
+// getLTPQuotes returns last traded prices keyed by instrument key. The API keys
+// its response by "EXCHANGE:SYMBOL", so results are re-keyed via instrument_token.
+func (m *Manager) getLTPQuotes(ctx context.Context, instrumentKeys []string) (map[string]float64, error) {
+	endpoint, _, err := m.endpointURL(ctx, EndpointLTP)
+	if err != nil {
+		return nil, err
+	}
+	req, err := m.newRequest(ctx, "GET", endpoint+"?instrument_key="+url.QueryEscape(strings.Join(instrumentKeys, ",")), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ltpResp LTPQuoteResponse
+	if err := m.do(req, &ltpResp); err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(ltpResp.Data))
+	for _, quote := range ltpResp.Data {
+		prices[quote.InstrumentToken] = quote.LastPrice
+	}
+	return prices, nil
+}
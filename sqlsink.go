@@ -0,0 +1,224 @@
+package upstox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SQLSinkConfig struct {
+	// DB is an open database. The sink issues SQLite-flavoured SQL with ?
+	// placeholders, so register a SQLite driver such as modernc.org/sqlite
+	// or github.com/mattn/go-sqlite3 and open it with sql.Open.
+	DB *sql.DB
+	// Table receives the ticks; "ticks" if empty. It is created if missing.
+	Table string
+	// FlushInterval bounds how long ticks sit in memory; 0 means one second.
+	FlushInterval time.Duration
+	// MaxPending is how many ticks may wait for a flush, 65536 if zero.
+	// While the database is failing, ticks that do not fit are dropped and
+	// counted rather than held in memory without bound.
+	MaxPending int
+	// Logger receives write failures from OnTick and the background flush;
+	// nil discards them.
+	Logger Logger
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLSink stores ticks, LTPC fields only, in one SQL table indexed by
+// instrument and time, in place of the file sinks' per-day and per-instrument
+// files. Times are Unix milliseconds. Ticks are buffered and inserted in one
+// transaction per flush; call Close on shutdown so nothing buffered is lost.
+// Close does not close DB.
+type SQLSink struct {
+	cfg     SQLSinkConfig
+	insert  string
+	flushMu sync.Mutex
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+
+	mu      sync.Mutex
+	pending []Tick
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewSQLSink(ctx context.Context, cfg SQLSinkConfig) (*SQLSink, error) {
+	if cfg.DB == nil {
+		return nil, errors.New("SQL sink needs a database")
+	}
+	if cfg.Table == "" {
+		cfg.Table = "ticks"
+	}
+	if !sqlIdentifier.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("invalid table name %q", cfg.Table)
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = 65536
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+
+	schema := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time INTEGER NOT NULL,
+	received_at INTEGER NOT NULL,
+	instrument_key TEXT NOT NULL,
+	ltp REAL NOT NULL,
+	ltq INTEGER NOT NULL,
+	ltt INTEGER NOT NULL,
+	cp REAL NOT NULL
+)`, cfg.Table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %[1]s_instrument_time ON %[1]s (instrument_key, time)`, cfg.Table),
+	}
+	for _, stmt := range schema {
+		if _, err := cfg.DB.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create tick table: %w", err)
+		}
+	}
+
+	s := &SQLSink{
+		cfg:    cfg,
+		insert: fmt.Sprintf(`INSERT INTO %s (time, received_at, instrument_key, ltp, ltq, ltt, cp) VALUES (?, ?, ?, ?, ?, ?, ?)`, cfg.Table),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+func (s *SQLSink) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(s.OnTick)
+}
+
+// OnTick buffers a tick, logging failures so it can be used as a listener.
+func (s *SQLSink) OnTick(tick Tick) {
+	if err := s.WriteTick(tick); err != nil {
+		s.cfg.Logger.Error("sql sink: write failed", "error", err)
+	}
+}
+
+func (s *SQLSink) WriteTick(tick Tick) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("SQL sink is closed")
+	}
+	if len(s.pending) >= s.cfg.MaxPending {
+		s.dropLocked(1)
+		return nil
+	}
+	s.pending = append(s.pending, tick)
+	return nil
+}
+
+func (s *SQLSink) dropLocked(n int) {
+	if s.dropped.Add(uint64(n)) == uint64(n) {
+		s.cfg.Logger.Warn("sql sink: buffer full, dropping ticks")
+	}
+}
+
+// Flush inserts buffered ticks. On failure they are kept for the next flush,
+// up to MaxPending.
+func (s *SQLSink) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := s.insertBatch(batch); err != nil {
+		s.mu.Lock()
+		s.pending = append(batch, s.pending...)
+		if over := len(s.pending) - s.cfg.MaxPending; over > 0 {
+			s.pending = s.pending[:s.cfg.MaxPending]
+			s.dropLocked(over)
+		}
+		s.mu.Unlock()
+		return err
+	}
+	s.written.Add(uint64(len(batch)))
+	return nil
+}
+
+// Stats returns how many ticks were inserted and how many were dropped
+// because the buffer was full.
+func (s *SQLSink) Stats() (written, dropped uint64) {
+	return s.written.Load(), s.dropped.Load()
+}
+
+func (s *SQLSink) insertBatch(batch []Tick) error {
+	tx, err := s.cfg.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin tick insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(s.insert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare tick insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, tick := range batch {
+		t := tick.ReceivedAt
+		if tick.LTT > 0 {
+			t = time.UnixMilli(tick.LTT)
+		}
+		if _, err := stmt.Exec(t.UnixMilli(), tick.ReceivedAt.UnixMilli(), tick.InstrumentKey, tick.LTP, tick.LTQ, tick.LTT, tick.CP); err != nil {
+			return fmt.Errorf("failed to insert tick: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ticks: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLSink) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				s.cfg.Logger.Error("sql sink: flush failed", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close flushes what is buffered. Writes after Close fail.
+func (s *SQLSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return s.Flush()
+}
@@ -0,0 +1,248 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// GreeksSource supplies the latest option greeks for an instrument; a
+// WebSocketManager subscribed in option_greeks or full mode is one.
+type GreeksSource interface {
+	Greeks(instrumentKey string) (OptionGreeks, bool)
+}
+
+// StrategyLeg is one instrument within a StrategyPosition. Quantity is signed:
+// positive for long, negative for short. OptionType is "CE", "PE" or empty for
+// futures and equity, which are treated as linear in the underlying.
+type StrategyLeg struct {
+	InstrumentKey string
+	Quantity      int
+	EntryPrice    float64
+	OptionType    string
+	Strike        float64
+}
+
+// StrategyPosition tracks a group of legs on the same underlying and expiry
+// (an iron condor, a calendar, a hedged future) as a single position.
+// Breakevens assume every leg is held to a common expiry.
+type StrategyPosition struct {
+	Tag      string
+	Legs     []StrategyLeg
+	Realised float64
+
+	mu     sync.RWMutex
+	prices map[string]float64
+	greeks GreeksSource
+}
+
+// StrategySnapshot is a point-in-time view of a StrategyPosition.
+type StrategySnapshot struct {
+	// Premium is the net premium paid (negative) or received (positive)
+	// to open the open legs.
+	Premium  float64
+	MTM      float64
+	Realised float64
+
+	// Greeks are quantity-weighted sums over the legs that have greeks.
+	Greeks     OptionGreeks
+	Breakevens []float64
+
+	// Priced is false while any leg is still waiting for its first price.
+	Priced bool
+}
+
+func NewStrategyPosition(tag string, legs ...StrategyLeg) *StrategyPosition {
+	return &StrategyPosition{Tag: tag, Legs: legs, prices: make(map[string]float64)}
+}
+
+// LoadStrategyPosition builds a StrategyPosition from today's filled orders
+// carrying tag. Legs are netted per instrument; a leg's entry price is the
+// average of the fills on its opening side, and any closed quantity is booked
+// into Realised. lookup supplies option type and strike, and may be nil.
+func (m *Manager) LoadStrategyPosition(ctx context.Context, tag string, lookup InstrumentLookup) (*StrategyPosition, error) {
+	orders, err := m.GetOrderBook(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	type fills struct {
+		buyQty, sellQty     int
+		buyValue, sellValue float64
+	}
+	byKey := make(map[string]*fills)
+	var keys []string
+	for _, o := range orders {
+		if o.Tag != tag || o.FilledQuantity == 0 {
+			continue
+		}
+		f, ok := byKey[o.InstrumentToken]
+		if !ok {
+			f = &fills{}
+			byKey[o.InstrumentToken] = f
+			keys = append(keys, o.InstrumentToken)
+		}
+		if o.TransactionType == string(OrderSideBuy) {
+			f.buyQty += o.FilledQuantity
+			f.buyValue += float64(o.FilledQuantity) * o.AveragePrice
+		} else {
+			f.sellQty += o.FilledQuantity
+			f.sellValue += float64(o.FilledQuantity) * o.AveragePrice
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no filled orders tagged %q", tag)
+	}
+
+	s := NewStrategyPosition(tag)
+	for _, key := range keys {
+		f := byKey[key]
+		var buyAvg, sellAvg float64
+		if f.buyQty > 0 {
+			buyAvg = f.buyValue / float64(f.buyQty)
+		}
+		if f.sellQty > 0 {
+			sellAvg = f.sellValue / float64(f.sellQty)
+		}
+		s.Realised += float64(min(f.buyQty, f.sellQty)) * (sellAvg - buyAvg)
+
+		leg := StrategyLeg{InstrumentKey: key, Quantity: f.buyQty - f.sellQty}
+		if leg.Quantity == 0 {
+			continue
+		}
+		leg.EntryPrice = sellAvg
+		if leg.Quantity > 0 {
+			leg.EntryPrice = buyAvg
+		}
+		if lookup != nil {
+			if inst, ok := lookup.ByKey(key); ok && (inst.InstrumentType == "CE" || inst.InstrumentType == "PE") {
+				leg.OptionType = inst.InstrumentType
+				leg.Strike = inst.StrikePrice
+			}
+		}
+		s.Legs = append(s.Legs, leg)
+	}
+	return s, nil
+}
+
+// Attach updates the position from wsm's ticks and reads greeks from it.
+// Call the returned function to detach.
+func (s *StrategyPosition) Attach(wsm *WebSocketManager) (detach func()) {
+	s.mu.Lock()
+	s.greeks = wsm
+	s.mu.Unlock()
+
+	return wsm.AddTickListener(func(tick Tick) {
+		s.OnPrice(tick.InstrumentKey, tick.LTP)
+	})
+}
+
+// SetGreeksSource sets where greeks are read from when not attached to a feed.
+func (s *StrategyPosition) SetGreeksSource(src GreeksSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.greeks = src
+}
+
+func (s *StrategyPosition) OnPrice(instrumentKey string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, leg := range s.Legs {
+		if leg.InstrumentKey == instrumentKey {
+			s.prices[instrumentKey] = price
+			return
+		}
+	}
+}
+
+func (s *StrategyPosition) Snapshot() StrategySnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := StrategySnapshot{Realised: s.Realised, Priced: true, Breakevens: s.breakevens()}
+	for _, leg := range s.Legs {
+		qty := float64(leg.Quantity)
+		snap.Premium -= qty * leg.EntryPrice
+
+		if price, ok := s.prices[leg.InstrumentKey]; ok {
+			snap.MTM += qty * (price - leg.EntryPrice)
+		} else {
+			snap.Priced = false
+		}
+
+		if s.greeks != nil {
+			if g, ok := s.greeks.Greeks(leg.InstrumentKey); ok {
+				snap.Greeks.Delta += qty * g.Delta
+				snap.Greeks.Theta += qty * g.Theta
+				snap.Greeks.Gamma += qty * g.Gamma
+				snap.Greeks.Vega += qty * g.Vega
+				snap.Greeks.Rho += qty * g.Rho
+			}
+		}
+	}
+	return snap
+}
+
+// PayoffAt returns the strategy's P&L at expiry for an underlying price,
+// excluding Realised.
+func (s *StrategyPosition) PayoffAt(underlying float64) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.payoffAt(underlying)
+}
+
+func (s *StrategyPosition) payoffAt(u float64) float64 {
+	var total float64
+	for _, leg := range s.Legs {
+		var value float64
+		switch leg.OptionType {
+		case "CE":
+			value = math.Max(u-leg.Strike, 0)
+		case "PE":
+			value = math.Max(leg.Strike-u, 0)
+		default:
+			value = u
+		}
+		total += float64(leg.Quantity) * (value - leg.EntryPrice)
+	}
+	return total
+}
+
+// breakevens finds the underlying prices where the expiry payoff crosses zero.
+// The payoff is piecewise linear with kinks only at strikes, so each segment
+// between kinks is solved exactly.
+func (s *StrategyPosition) breakevens() []float64 {
+	var kinks []float64
+	upper := 0.0
+	for _, leg := range s.Legs {
+		if leg.OptionType != "" {
+			kinks = append(kinks, leg.Strike)
+		}
+		upper = math.Max(upper, math.Max(leg.Strike, leg.EntryPrice))
+	}
+	sort.Float64s(kinks)
+
+	points := append([]float64{0}, kinks...)
+	points = append(points, math.Max(upper, 1)*4)
+
+	var out []float64
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		if a == b {
+			continue
+		}
+		pa, pb := s.payoffAt(a), s.payoffAt(b)
+		if pa == 0 {
+			if len(out) == 0 || out[len(out)-1] != a {
+				out = append(out, a)
+			}
+			continue
+		}
+		if (pa < 0) != (pb < 0) && pb != 0 {
+			out = append(out, a+(b-a)*pa/(pa-pb))
+		}
+	}
+	return out
+}
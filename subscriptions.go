@@ -0,0 +1,155 @@
+package upstox
+
+import (
+	"sync"
+	"time"
+)
+
+const subscriptionShardCount = 32
+
+// subscriptionTable holds per-instrument subscription state and the latest
+// tick for each instrument, split across shards keyed by an FNV-1a hash of the
+// instrument key.
+//
+// Concurrency: every method is safe for concurrent use. Operations on a single
+// instrument are linearizable, since they always take that instrument's shard
+// lock. Operations spanning many instruments (snapshot) lock one shard at
+// a time, so they see a consistent view of each shard but not an atomic view
+// of the whole table. The read loop updating last ticks therefore never blocks
+// behind a caller iterating thousands of subscriptions.
+type subscriptionTable struct {
+	shards [subscriptionShardCount]subscriptionShard
+}
+
+type subscriptionShard struct {
+	mu        sync.RWMutex
+	subs      map[string]InstrumentSubscription
+	lastTicks map[string]Tick
+	greeks    map[string]OptionGreeks
+}
+
+func newSubscriptionTable() *subscriptionTable {
+	t := &subscriptionTable{}
+	for i := range t.shards {
+		t.shards[i].subs = make(map[string]InstrumentSubscription)
+		t.shards[i].lastTicks = make(map[string]Tick)
+		t.shards[i].greeks = make(map[string]OptionGreeks)
+	}
+	return t
+}
+
+func (t *subscriptionTable) shard(key string) *subscriptionShard {
+	return &t.shards[hashKey(key)%subscriptionShardCount]
+}
+
+// hashKey is FNV-1a over the instrument key, inlined to avoid allocating a
+// hash.Hash per lookup.
+func hashKey(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// set records key as subscribed in mode. Resubscribing in the same mode, as
+// on reconnect, keeps the original subscription time.
+func (t *subscriptionTable) set(key string, mode SubscriptionMode, at time.Time) {
+	s := t.shard(key)
+	s.mu.Lock()
+	if sub, ok := s.subs[key]; !ok || sub.Mode != mode {
+		s.subs[key] = InstrumentSubscription{Mode: mode, Time: at}
+	}
+	s.mu.Unlock()
+}
+
+func (t *subscriptionTable) remove(key string) {
+	s := t.shard(key)
+	s.mu.Lock()
+	delete(s.subs, key)
+	delete(s.lastTicks, key)
+	delete(s.greeks, key)
+	s.mu.Unlock()
+}
+
+func (t *subscriptionTable) get(key string) (InstrumentSubscription, bool) {
+	s := t.shard(key)
+	s.mu.RLock()
+	sub, ok := s.subs[key]
+	s.mu.RUnlock()
+	return sub, ok
+}
+
+func (t *subscriptionTable) snapshot() map[string]InstrumentSubscription {
+	out := make(map[string]InstrumentSubscription)
+	for i := range t.shards {
+		s := &t.shards[i]
+		s.mu.RLock()
+		for k, v := range s.subs {
+			out[k] = v
+		}
+		s.mu.RUnlock()
+	}
+	return out
+}
+
+func (t *subscriptionTable) setLastTick(tick Tick) {
+	s := t.shard(tick.InstrumentKey)
+	s.mu.Lock()
+	s.lastTicks[tick.InstrumentKey] = tick
+	s.mu.Unlock()
+}
+
+func (t *subscriptionTable) lastTick(key string) (Tick, bool) {
+	s := t.shard(key)
+	s.mu.RLock()
+	tick, ok := s.lastTicks[key]
+	s.mu.RUnlock()
+	return tick, ok
+}
+
+func (t *subscriptionTable) setGreeks(key string, g OptionGreeks) {
+	s := t.shard(key)
+	s.mu.Lock()
+	s.greeks[key] = g
+	s.mu.Unlock()
+}
+
+func (t *subscriptionTable) lastGreeks(key string) (OptionGreeks, bool) {
+	s := t.shard(key)
+	s.mu.RLock()
+	g, ok := s.greeks[key]
+	s.mu.RUnlock()
+	return g, ok
+}
+
+// LastTick returns the most recent tick received for instrumentKey.
+func (wsm *WebSocketManager) LastTick(instrumentKey string) (Tick, bool) {
+	return wsm.subs.lastTick(instrumentKey)
+}
+
+// LastPrice implements PriceSource from the live feed, so a WebSocketManager
+// can back a SlippageGuard without an extra REST round trip.
+func (wsm *WebSocketManager) LastPrice(instrumentKey string) (float64, bool) {
+	tick, ok := wsm.subs.lastTick(instrumentKey)
+	return tick.LTP, ok
+}
+
+// Greeks returns the latest option greeks received for instrumentKey. They are
+// only sent in option_greeks and full modes.
+func (wsm *WebSocketManager) Greeks(instrumentKey string) (OptionGreeks, bool) {
+	return wsm.subs.lastGreeks(instrumentKey)
+}
+
+// GetSubscriptions returns the mode and subscription time of every instrument
+// subscribed on the socket. Instruments added while disconnected show up once
+// they have been sent on reconnect. The map is a copy.
+func (wsm *WebSocketManager) GetSubscriptions() map[string]InstrumentSubscription {
+	return wsm.subs.snapshot()
+}
+
+func (wsm *WebSocketManager) IsSubscribed(instrumentKey string) bool {
+	_, ok := wsm.subs.get(instrumentKey)
+	return ok
+}
@@ -0,0 +1,159 @@
+package upstox
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// symbolSegmentPreference breaks ties when a trading symbol is listed on
+// several segments: NSE cash first, then indices, then BSE.
+var symbolSegmentPreference = []string{"NSE_EQ", "NSE_INDEX", "BSE_EQ", "BSE_INDEX"}
+
+// ResolveSymbols maps human symbols such as "RELIANCE" or "NIFTY 50" to
+// instrument keys. Entries that already look like keys ("NSE_EQ|INE...") are
+// passed through. A symbol listed on several segments resolves to the first
+// of NSE_EQ, NSE_INDEX, BSE_EQ, BSE_INDEX; index names are also tried as
+// NSE_INDEX/BSE_INDEX keys. Every unresolved symbol is reported in one error
+// wrapping ErrUnknownSymbol.
+func ResolveSymbols(lookup InstrumentLookup, symbols ...string) ([]string, error) {
+	keys := make([]string, 0, len(symbols))
+	var unknown []string
+	for _, sym := range symbols {
+		if strings.Contains(sym, "|") {
+			keys = append(keys, sym)
+			continue
+		}
+		key, ok := resolveSymbol(lookup, sym)
+		if !ok {
+			unknown = append(unknown, sym)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	if len(unknown) > 0 {
+		return keys, fmt.Errorf("%w: %s", ErrUnknownSymbol, strings.Join(unknown, ", "))
+	}
+	return keys, nil
+}
+
+func resolveSymbol(lookup InstrumentLookup, symbol string) (string, bool) {
+	matches := lookup.BySymbol(symbol)
+	if len(matches) == 1 {
+		return matches[0].InstrumentKey, true
+	}
+	for _, segment := range symbolSegmentPreference {
+		for _, inst := range matches {
+			if inst.Segment == segment {
+				return inst.InstrumentKey, true
+			}
+		}
+	}
+
+	// Index keys use the index name, e.g. "NSE_INDEX|Nifty 50", rather than
+	// the trading symbol.
+	for _, name := range []string{symbol, titleCase(symbol), strings.ToUpper(symbol)} {
+		for _, segment := range []string{"NSE_INDEX", "BSE_INDEX"} {
+			if inst, ok := lookup.ByKey(segment + "|" + name); ok {
+				return inst.InstrumentKey, true
+			}
+		}
+	}
+	return "", false
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(strings.ToLower(s))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// SetInstrumentLookup lets Subscribe and Unsubscribe accept trading symbols.
+func (wsm *WebSocketManager) SetInstrumentLookup(lookup InstrumentLookup) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.lookup = lookup
+}
+
+func (wsm *WebSocketManager) resolve(symbols []string) ([]string, error) {
+	wsm.mu.RLock()
+	lookup := wsm.lookup
+	wsm.mu.RUnlock()
+
+	if lookup == nil {
+		for _, s := range symbols {
+			if !strings.Contains(s, "|") {
+				return nil, fmt.Errorf("cannot resolve %q without an instrument lookup; call SetInstrumentLookup", s)
+			}
+		}
+		return symbols, nil
+	}
+	return ResolveSymbols(lookup, symbols...)
+}
+
+// Subscribe adds instruments, given as keys or as trading symbols resolved
+// through SetInstrumentLookup, in the feed's configured mode. Nothing is
+// subscribed if any symbol fails to resolve. Instruments are sent to the
+// socket now if connected, and on every later (re)connect.
+func (wsm *WebSocketManager) Subscribe(symbols ...string) error {
+	keys, err := wsm.resolve(symbols)
+	if err != nil {
+		return err
+	}
+
+	wsm.mu.Lock()
+	var added []string
+	for _, key := range keys {
+		if !slices.Contains(wsm.config.InstrumentKeys, key) && !slices.Contains(added, key) {
+			added = append(added, key)
+		}
+	}
+	wsm.config.InstrumentKeys = append(slices.Clip(wsm.config.InstrumentKeys), added...)
+	conn, mode := wsm.ws, wsm.config.Mode
+	wsm.mu.Unlock()
+
+	if conn == nil || len(added) == 0 {
+		return nil
+	}
+	return wsm.sendSubscription(conn, "sub", mode, added)
+}
+
+// Unsubscribe removes instruments given as keys or trading symbols.
+func (wsm *WebSocketManager) Unsubscribe(symbols ...string) error {
+	keys, err := wsm.resolve(symbols)
+	if err != nil {
+		return err
+	}
+
+	wsm.mu.Lock()
+	var removed []string
+	wsm.config.InstrumentKeys = slices.DeleteFunc(slices.Clone(wsm.config.InstrumentKeys), func(key string) bool {
+		if slices.Contains(keys, key) {
+			removed = append(removed, key)
+			return true
+		}
+		return false
+	})
+	for _, key := range removed {
+		delete(wsm.modes, key)
+	}
+	conn, mode := wsm.ws, wsm.config.Mode
+	wsm.mu.Unlock()
+
+	if len(removed) == 0 {
+		return nil
+	}
+	if conn == nil {
+		for _, key := range removed {
+			wsm.subs.remove(key)
+		}
+		return nil
+	}
+	return wsm.sendSubscription(conn, "unsub", mode, removed)
+}
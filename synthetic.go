@@ -0,0 +1,130 @@
+package upstox
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SyntheticPrefix is the conventional instrument key prefix for synthetics,
+// keeping them apart from exchange keys in shared tick consumers.
+const SyntheticPrefix = "SYNTH|"
+
+type SyntheticLeg struct {
+	InstrumentKey string
+	Weight        float64
+}
+
+// SyntheticInstrument prices Key as Constant plus the weighted sum of its legs'
+// last prices. Once registered with AddSynthetic, a tick for Key is emitted
+// through every tick consumer (listeners, batches, conflation, dispatchers,
+// and anything built on them) whenever a leg ticks and all legs have a price.
+type SyntheticInstrument struct {
+	Key      string
+	Legs     []SyntheticLeg
+	Constant float64
+}
+
+// CalendarSpread is near minus far.
+func CalendarSpread(key, near, far string) SyntheticInstrument {
+	return SyntheticInstrument{
+		Key:  key,
+		Legs: []SyntheticLeg{{InstrumentKey: near, Weight: 1}, {InstrumentKey: far, Weight: -1}},
+	}
+}
+
+// SyntheticFuture is call minus put plus strike, by put-call parity.
+func SyntheticFuture(key, call, put string, strike float64) SyntheticInstrument {
+	return SyntheticInstrument{
+		Key:      key,
+		Legs:     []SyntheticLeg{{InstrumentKey: call, Weight: 1}, {InstrumentKey: put, Weight: -1}},
+		Constant: strike,
+	}
+}
+
+// AddSynthetic registers def. Its legs must be exchange instruments, not other
+// synthetics, and are not subscribed automatically. Re-adding a key replaces
+// the previous definition.
+func (wsm *WebSocketManager) AddSynthetic(def SyntheticInstrument) error {
+	if def.Key == "" || len(def.Legs) == 0 {
+		return fmt.Errorf("synthetic instrument needs a key and at least one leg")
+	}
+
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+
+	for _, leg := range def.Legs {
+		if _, ok := wsm.synthetics[leg.InstrumentKey]; ok || leg.InstrumentKey == def.Key {
+			return fmt.Errorf("synthetic %s: leg %s is itself synthetic", def.Key, leg.InstrumentKey)
+		}
+	}
+	if _, ok := wsm.syntheticsByLeg[def.Key]; ok {
+		return fmt.Errorf("synthetic %s is already a leg of another synthetic", def.Key)
+	}
+
+	def.Legs = slices.Clone(def.Legs)
+	wsm.removeSyntheticLocked(def.Key)
+
+	if wsm.synthetics == nil {
+		wsm.synthetics = make(map[string]*SyntheticInstrument)
+	}
+	// syntheticsByLeg is replaced wholesale rather than mutated, so dispatch can
+	// use the slice it read under the lock after releasing it.
+	byLeg := make(map[string][]*SyntheticInstrument, len(wsm.syntheticsByLeg)+len(def.Legs))
+	for k, v := range wsm.syntheticsByLeg {
+		byLeg[k] = v
+	}
+	syn := &def
+	for _, leg := range def.Legs {
+		if !slices.Contains(byLeg[leg.InstrumentKey], syn) {
+			byLeg[leg.InstrumentKey] = append(slices.Clip(byLeg[leg.InstrumentKey]), syn)
+		}
+	}
+	wsm.synthetics[def.Key] = syn
+	wsm.syntheticsByLeg = byLeg
+	return nil
+}
+
+func (wsm *WebSocketManager) RemoveSynthetic(key string) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.removeSyntheticLocked(key)
+}
+
+func (wsm *WebSocketManager) removeSyntheticLocked(key string) {
+	syn, ok := wsm.synthetics[key]
+	if !ok {
+		return
+	}
+	delete(wsm.synthetics, key)
+
+	byLeg := make(map[string][]*SyntheticInstrument, len(wsm.syntheticsByLeg))
+	for leg, syns := range wsm.syntheticsByLeg {
+		kept := slices.DeleteFunc(slices.Clone(syns), func(s *SyntheticInstrument) bool { return s == syn })
+		if len(kept) > 0 {
+			byLeg[leg] = kept
+		}
+	}
+	wsm.syntheticsByLeg = byLeg
+	wsm.subs.remove(key)
+}
+
+// syntheticTick prices syn after trigger has been recorded as its leg's last
+// tick. ok is false until every leg has ticked at least once.
+func (wsm *WebSocketManager) syntheticTick(syn *SyntheticInstrument, trigger Tick) (Tick, bool) {
+	tick := Tick{
+		InstrumentKey: syn.Key,
+		LTP:           syn.Constant,
+		CP:            syn.Constant,
+		ReceivedAt:    trigger.ReceivedAt,
+	}
+	for _, leg := range syn.Legs {
+		last, ok := wsm.subs.lastTick(leg.InstrumentKey)
+		if !ok {
+			return Tick{}, false
+		}
+		tick.LTP += leg.Weight * last.LTP
+		tick.CP += leg.Weight * last.CP
+		tick.LTT = max(tick.LTT, last.LTT)
+	}
+	return tick, true
+}
@@ -0,0 +1,168 @@
+package upstox
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adeludedperson/go-upstox/indicators"
+)
+
+// TickIndicator is an incremental indicator fed every tick of one
+// instrument. Update returns the new value and whether it is warmed up.
+type TickIndicator interface {
+	Update(t Tick) (value float64, ready bool)
+}
+
+// TickIndicatorFactory creates a fresh indicator for each instrument.
+type TickIndicatorFactory func() TickIndicator
+
+type tickIndicatorFunc func(t Tick) (float64, bool)
+
+func (f tickIndicatorFunc) Update(t Tick) (float64, bool) { return f(t) }
+
+// TickSMA averages the last period traded prices.
+func TickSMA(period int) TickIndicatorFactory {
+	return func() TickIndicator {
+		s := indicators.NewSMA(period)
+		return tickIndicatorFunc(func(t Tick) (float64, bool) { return s.Update(t.LTP), s.Ready() })
+	}
+}
+
+func TickEMA(period int) TickIndicatorFactory {
+	return func() TickIndicator {
+		e := indicators.NewEMA(period)
+		return tickIndicatorFunc(func(t Tick) (float64, bool) { return e.Update(t.LTP), e.Ready() })
+	}
+}
+
+func TickRSI(period int) TickIndicatorFactory {
+	return func() TickIndicator {
+		r := indicators.NewRSI(period)
+		return tickIndicatorFunc(func(t Tick) (float64, bool) { return r.Update(t.LTP), r.Ready() })
+	}
+}
+
+// TickVWAP weights each traded price by its last traded quantity and
+// restarts every IST trading day. A tick repeating the previous trade time is
+// taken as the same trade and not counted again.
+func TickVWAP() TickIndicatorFactory {
+	return func() TickIndicator {
+		v := indicators.NewVWAP()
+		var day time.Time
+		var lastTrade int64
+		return tickIndicatorFunc(func(t Tick) (float64, bool) {
+			at := t.ReceivedAt
+			if t.LTT > 0 {
+				if t.LTT == lastTrade {
+					return v.Value(), v.Ready()
+				}
+				lastTrade = t.LTT
+				at = time.UnixMilli(t.LTT)
+			}
+			at = at.In(IST)
+			d := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, IST)
+			if !d.Equal(day) {
+				v.Reset()
+				day = d
+			}
+			return v.Update(t.LTP, float64(t.LTQ)), v.Ready()
+		})
+	}
+}
+
+// TickIndicators keeps named indicators per instrument, updated from every
+// tick rather than from closed candles. Range indicators such as ATR need
+// candles; use an IndicatorPipeline behind a CandleAggregator for those,
+// which can be sub-minute.
+type TickIndicators struct {
+	mu        sync.Mutex
+	names     []string
+	factories map[string]TickIndicatorFactory
+	series    map[string]*tickSeries
+}
+
+type tickSeries struct {
+	indicators map[string]TickIndicator
+	values     map[string]float64
+}
+
+func NewTickIndicators() *TickIndicators {
+	return &TickIndicators{
+		factories: make(map[string]TickIndicatorFactory),
+		series:    make(map[string]*tickSeries),
+	}
+}
+
+// Add registers an indicator under name, e.g. ti.Add("vwap", TickVWAP()). A
+// factory that cannot build its indicator, such as TickSMA(0), is rejected.
+func (ti *TickIndicators) Add(name string, factory TickIndicatorFactory) error {
+	if err := checkFactory(name, factory == nil, func() { factory() }); err != nil {
+		return err
+	}
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if _, ok := ti.factories[name]; ok {
+		return fmt.Errorf("indicator %q already registered", name)
+	}
+	ti.names = append(ti.names, name)
+	ti.factories[name] = factory
+	return nil
+}
+
+func (ti *TickIndicators) Attach(wsm *WebSocketManager) (detach func()) {
+	return wsm.AddTickListener(ti.OnTick)
+}
+
+// OnTick updates every indicator for the tick's instrument. Ticks without a
+// price are ignored.
+func (ti *TickIndicators) OnTick(tick Tick) {
+	if tick.LTP <= 0 {
+		return
+	}
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	s := ti.series[tick.InstrumentKey]
+	if s == nil {
+		s = &tickSeries{indicators: make(map[string]TickIndicator), values: make(map[string]float64)}
+		ti.series[tick.InstrumentKey] = s
+	}
+	for _, name := range ti.names {
+		ind, ok := s.indicators[name]
+		if !ok {
+			ind = ti.factories[name]()
+			s.indicators[name] = ind
+		}
+		if v, ready := ind.Update(tick); ready {
+			s.values[name] = v
+		} else {
+			delete(s.values, name)
+		}
+	}
+}
+
+// Value returns the latest warmed-up value of an indicator.
+func (ti *TickIndicators) Value(instrumentKey, name string) (float64, bool) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	s, ok := ti.series[instrumentKey]
+	if !ok {
+		return 0, false
+	}
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Values returns every warmed-up indicator for an instrument.
+func (ti *TickIndicators) Values(instrumentKey string) map[string]float64 {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	out := make(map[string]float64)
+	if s, ok := ti.series[instrumentKey]; ok {
+		for name, v := range s.values {
+			out[name] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package upstox
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// TickSink persists ticks, the LTPC view of feed updates. CSVSink, JSONLSink
+// and SQLSink implement it.
+type TickSink interface {
+	WriteTick(tick Tick) error
+	Close() error
+}
+
+type TickRecorderConfig struct {
+	// Buffer is how many ticks may wait for the sink, 4096 if zero. Ticks
+	// that do not fit are dropped and counted rather than stalling the feed.
+	Buffer int
+	// Logger receives sink failures; nil discards them.
+	Logger Logger
+}
+
+// TickRecorder persists every tick from one or more feeds to a TickSink. It
+// is an LTPC recorder: only the fields of Tick are kept, so depth, OHLC,
+// greeks and open interest are not. To archive complete feed messages,
+// record frames from OnRawMessage and replay them through HandleFrame.
+//
+// Ticks are handed to the sink on the recorder's own goroutine, so slow disk
+// or database writes never hold up the feed's read loop.
+type TickRecorder struct {
+	sink   TickSink
+	logger Logger
+	ticks  chan Tick
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func NewTickRecorder(sink TickSink, cfg TickRecorderConfig) *TickRecorder {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 4096
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+	r := &TickRecorder{
+		sink:   sink,
+		logger: cfg.Logger,
+		ticks:  make(chan Tick, cfg.Buffer),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record subscribes instrumentKeys on wsm, if any are given, and records
+// every tick wsm delivers from then on.
+func (r *TickRecorder) Record(wsm *WebSocketManager, instrumentKeys ...string) (detach func(), err error) {
+	if len(instrumentKeys) > 0 {
+		if err := wsm.Subscribe(instrumentKeys...); err != nil {
+			return nil, fmt.Errorf("failed to subscribe recorded instruments: %w", err)
+		}
+	}
+	return wsm.AddTickListener(r.OnTick), nil
+}
+
+// OnTick queues a tick for the sink without blocking. Ticks after Close are
+// ignored.
+func (r *TickRecorder) OnTick(tick Tick) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.ticks <- tick:
+	default:
+		if r.dropped.Add(1) == 1 {
+			r.logger.Warn("tick recorder: buffer full, dropping ticks")
+		}
+	}
+}
+
+func (r *TickRecorder) run() {
+	defer close(r.done)
+	for tick := range r.ticks {
+		if err := r.sink.WriteTick(tick); err != nil {
+			r.failed.Add(1)
+			r.logger.Error("tick recorder: write failed", "instrument_key", tick.InstrumentKey, "error", err)
+			continue
+		}
+		r.written.Add(1)
+	}
+}
+
+// Stats returns how many ticks were written, dropped because the buffer was
+// full, and rejected by the sink.
+func (r *TickRecorder) Stats() (written, dropped, failed uint64) {
+	return r.written.Load(), r.dropped.Load(), r.failed.Load()
+}
+
+// Close writes out the queued ticks and closes the sink. OnTick is not held
+// up meanwhile; it drops ticks as soon as Close starts.
+func (r *TickRecorder) Close() error {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.ticks)
+		r.mu.Unlock()
+
+		<-r.done
+		if err := r.sink.Close(); err != nil {
+			r.err = fmt.Errorf("failed to close tick sink: %w", err)
+		}
+	})
+	return r.err
+}
@@ -0,0 +1,62 @@
+package upstox
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowSink takes until release is closed to write each tick.
+type slowSink struct {
+	release chan struct{}
+
+	mu    sync.Mutex
+	ticks []Tick
+}
+
+func (s *slowSink) WriteTick(tick Tick) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ticks = append(s.ticks, tick)
+	return nil
+}
+
+func (s *slowSink) Close() error { return nil }
+
+func TestTickRecorderCloseDoesNotBlockTicks(t *testing.T) {
+	sink := &slowSink{release: make(chan struct{})}
+	r := NewTickRecorder(sink, TickRecorderConfig{Buffer: 2})
+	r.OnTick(Tick{InstrumentKey: "NSE_EQ|X", LTP: 100})
+
+	closed := make(chan error)
+	go func() { closed <- r.Close() }()
+	for closing := false; !closing; {
+		r.mu.RLock()
+		closing = r.closed
+		r.mu.RUnlock()
+	}
+
+	// Close is draining into the stalled sink; ticks must still return.
+	ticked := make(chan struct{})
+	go func() {
+		r.OnTick(Tick{InstrumentKey: "NSE_EQ|X", LTP: 101})
+		close(ticked)
+	}()
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("OnTick blocked while Close drained the sink")
+	}
+
+	close(sink.release)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if written, _, _ := r.Stats(); written != 1 || len(sink.ticks) != 1 {
+		t.Errorf("written %d, sink has %d, want the tick queued before Close", written, len(sink.ticks))
+	}
+}
@@ -0,0 +1,244 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetAccessToken rotates the token used for all subsequent REST calls and
+// propagates it to every live WebSocketManager created by this Manager.
+// Open feed connections are left running; each one re-authorizes its feed URL
+// with the new token the next time it has to reconnect.
+func (m *Manager) SetAccessToken(token string) {
+	m.mu.Lock()
+	m.accessToken = token
+	feeds := make([]*WebSocketManager, 0, len(m.feeds))
+	for wsm := range m.feeds {
+		feeds = append(feeds, wsm)
+	}
+	m.mu.Unlock()
+
+	for _, wsm := range feeds {
+		wsm.setToken(token)
+	}
+}
+
+func (m *Manager) registerFeed(wsm *WebSocketManager) {
+	m.mu.Lock()
+	if m.feeds == nil {
+		m.feeds = make(map[*WebSocketManager]struct{})
+	}
+	m.feeds[wsm] = struct{}{}
+	m.mu.Unlock()
+
+	if m.paper != nil {
+		m.paper.Attach(wsm)
+	}
+
+	wsm.authorize = func() (string, error) {
+		return m.getAuthorizedWebSocketURL(context.Background())
+	}
+	wsm.release = func() {
+		m.mu.Lock()
+		delete(m.feeds, wsm)
+		m.mu.Unlock()
+	}
+}
+
+func (wsm *WebSocketManager) setToken(token string) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.config.Token = token
+	wsm.urlStale = true
+}
+
+func (wsm *WebSocketManager) refreshURL() error {
+	wsm.mu.RLock()
+	stale := wsm.urlStale && wsm.authorize != nil
+	wsm.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	url, err := wsm.authorize()
+	if err != nil {
+		return fmt.Errorf("failed to re-authorize WebSocket URL: %w", err)
+	}
+
+	wsm.mu.Lock()
+	wsm.url = url
+	wsm.urlStale = false
+	wsm.mu.Unlock()
+	return nil
+}
+
+// TokenProvider supplies access tokens to a Manager, e.g. from a secret
+// store that a separate login job keeps current.
+type TokenProvider interface {
+	// Token returns the current access token, fetching a new one if the
+	// provider knows the old one has expired.
+	Token(ctx context.Context) (string, error)
+	// Refresh is called when the API rejected a token with a 401; it must
+	// return a token other than rejected, or an error. Concurrent calls may
+	// all report the same rejected token, so a provider that has already
+	// moved on should return its current token rather than fetch again.
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// WithTokenProvider makes the Manager ask p for the token before each call
+// and after a 401, retrying the rejected call once with the new token. New
+// tokens are propagated to live feeds as with SetAccessToken.
+func WithTokenProvider(p TokenProvider) ManagerOption {
+	return func(m *Manager) {
+		m.tokenProvider = p
+	}
+}
+
+// WithExtendedToken sets the long-lived, read-only extended token issued
+// alongside the access token. Read-only calls (order book, trades, positions
+// and holdings) fall back to it when no access token is set or the access
+// token is rejected, so monitoring keeps working after the daily expiry.
+func WithExtendedToken(token string) ManagerOption {
+	return func(m *Manager) {
+		m.extendedToken = token
+	}
+}
+
+func (m *Manager) SetExtendedToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extendedToken = token
+}
+
+func (m *Manager) getExtendedToken() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.extendedToken
+}
+
+// extendedTokenPaths are the endpoints Upstox serves with an extended token.
+var extendedTokenPaths = []string{
+	"/v2/order/retrieve-all",
+	"/v2/order/details",
+	"/v2/order/history",
+	"/v2/order/trades",
+	"/v2/order/trades/get-trades-for-day",
+	"/v2/portfolio/short-term-positions",
+	"/v2/portfolio/long-term-holdings",
+	"/v3/portfolio/mtf-positions",
+}
+
+func extendedTokenAllowed(req *http.Request) bool {
+	return req.Method == http.MethodGet && slices.Contains(extendedTokenPaths, req.URL.Path)
+}
+
+// AccessTokenExpiry is when a token issued at issued stops working: Upstox
+// access tokens expire at 03:30 IST the following morning.
+func AccessTokenExpiry(issued time.Time) time.Time {
+	t := issued.In(IST)
+	expiry := time.Date(t.Year(), t.Month(), t.Day(), 3, 30, 0, 0, IST)
+	if !expiry.After(t) {
+		expiry = expiry.AddDate(0, 0, 1)
+	}
+	return expiry
+}
+
+// TokenFunc adapts a fetch function, such as a read from a secret store or
+// an automated login, into a TokenProvider. The token is cached until its
+// daily expiry or until the API rejects it.
+func TokenFunc(fetch func(ctx context.Context) (string, error)) TokenProvider {
+	return &cachedToken{fetch: fetch}
+}
+
+type cachedToken struct {
+	fetch func(ctx context.Context) (string, error)
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (c *cachedToken) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	return c.load(ctx)
+}
+
+func (c *cachedToken) Refresh(ctx context.Context, rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another request already replaced the rejected token.
+	if c.token != "" && c.token != rejected && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	token, err := c.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == rejected {
+		return "", errors.New("token source returned the rejected token again")
+	}
+	return token, nil
+}
+
+func (c *cachedToken) load(ctx context.Context) (string, error) {
+	token, err := c.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch access token: %w", err)
+	}
+	if token == "" {
+		return "", errors.New("token source returned an empty token")
+	}
+	c.token, c.expires = token, AccessTokenExpiry(time.Now())
+	return token, nil
+}
+
+// requestToken picks the token for a new request: the provider's if one is
+// configured, else the Manager's, else the extended token where allowed.
+func (m *Manager) requestToken(req *http.Request) (string, error) {
+	if m.tokenProvider != nil {
+		token, err := m.tokenProvider.Token(req.Context())
+		if err != nil {
+			return "", err
+		}
+		if token != m.GetAccessToken() {
+			m.SetAccessToken(token)
+		}
+		return token, nil
+	}
+	if token := m.GetAccessToken(); token != "" || !extendedTokenAllowed(req) {
+		return token, nil
+	}
+	return m.getExtendedToken(), nil
+}
+
+// retryToken returns a token to retry req with after a 401, or false if
+// there is nothing better to try.
+func (m *Manager) retryToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	if auth == "" {
+		return "", false
+	}
+	used := strings.TrimPrefix(auth, "Bearer ")
+	if m.tokenProvider != nil {
+		token, err := m.tokenProvider.Refresh(req.Context(), used)
+		if err == nil && token != used {
+			m.SetAccessToken(token)
+			return token, true
+		}
+	}
+	if ext := m.getExtendedToken(); ext != "" && ext != used && extendedTokenAllowed(req) {
+		return ext, true
+	}
+	return "", false
+}
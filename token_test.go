@@ -0,0 +1,62 @@
+package upstox
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestTokenFuncRefresh(t *testing.T) {
+	ctx := context.Background()
+	fetches := 0
+	p := TokenFunc(func(context.Context) (string, error) {
+		fetches++
+		return "token-" + strconv.Itoa(fetches), nil
+	})
+
+	first, err := p.Token(ctx)
+	if err != nil || first != "token-1" {
+		t.Fatalf("Token = %q, %v", first, err)
+	}
+
+	// Two requests rejected with the same token: only the first refetches.
+	second, err := p.Refresh(ctx, first)
+	if err != nil || second != "token-2" {
+		t.Fatalf("Refresh = %q, %v", second, err)
+	}
+	again, err := p.Refresh(ctx, first)
+	if err != nil || again != "token-2" {
+		t.Fatalf("second Refresh = %q, %v", again, err)
+	}
+	if fetches != 2 {
+		t.Errorf("%d fetches, want 2", fetches)
+	}
+
+	stuck := TokenFunc(func(context.Context) (string, error) { return "same", nil })
+	if _, err := stuck.Refresh(ctx, "same"); err == nil {
+		t.Error("Refresh returned the rejected token")
+	}
+}
+
+func TestFeedAuthorizeRefreshesToken(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		if req.Header.Get("Authorization") != "Bearer token-2" {
+			return jsonResponse(req, http.StatusUnauthorized, `{"status":"error","errors":[{"error_code":"UDAPI100050","message":"Invalid token used to access API"}]}`), nil
+		}
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"authorized_redirect_uri":"wss://feed.example/ws"}}`), nil
+	}}
+	fetches := 0
+	m := NewManager("id", "secret", "", WithTransport(stub), WithTokenProvider(TokenFunc(func(context.Context) (string, error) {
+		fetches++
+		return "token-" + strconv.Itoa(fetches), nil
+	})))
+
+	url, err := m.getAuthorizedWebSocketURL(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "wss://feed.example/ws" || stub.count() != 2 {
+		t.Errorf("url = %q after %d requests", url, stub.count())
+	}
+}
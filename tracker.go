@@ -0,0 +1,323 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type OrderEventType int
+
+const (
+	// OrderAccepted is sent once, when the order is first seen open at the
+	// exchange.
+	OrderAccepted OrderEventType = iota
+	// OrderPartiallyFilled is sent whenever the filled quantity grows short
+	// of the order quantity.
+	OrderPartiallyFilled
+	OrderFilled
+	OrderRejected
+	OrderCancelled
+)
+
+func (t OrderEventType) String() string {
+	switch t {
+	case OrderAccepted:
+		return "accepted"
+	case OrderPartiallyFilled:
+		return "partially_filled"
+	case OrderFilled:
+		return "filled"
+	case OrderRejected:
+		return "rejected"
+	case OrderCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("OrderEventType(%d)", int(t))
+}
+
+// Final reports whether no further events follow for the order.
+func (t OrderEventType) Final() bool {
+	return t == OrderFilled || t == OrderRejected || t == OrderCancelled
+}
+
+// OrderEvent is a change in a tracked order. Order is its state when the
+// change was observed.
+type OrderEvent struct {
+	Type    OrderEventType
+	OrderID string
+	Order   *Order
+	At      time.Time
+}
+
+type OrderTrackerConfig struct {
+	// PollInterval is how often the order book is fetched while orders are
+	// being watched. It defaults to one second.
+	PollInterval time.Duration
+	// Buffer is the capacity of the Events channel, 256 if zero. Events that
+	// do not fit are dropped and logged; callbacks always see every event.
+	Buffer int
+}
+
+// OrderTracker watches a set of orders and turns their status changes into
+// OrderEvents. It polls the order book, one request per interval however
+// many orders are watched, and also accepts updates pushed from elsewhere
+// through Update. An order stops being watched after its final event.
+type OrderTracker struct {
+	m      *Manager
+	cfg    OrderTrackerConfig
+	events chan OrderEvent
+
+	mu        sync.Mutex
+	watched   map[string]*trackedOrder
+	callbacks []*orderListener
+	waiters   map[string][]chan *Order
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+type orderListener struct{ fn func(OrderEvent) }
+
+type trackedOrder struct {
+	accepted bool
+	filled   int
+
+	// forWait is set while the order is watched only because Wait asked.
+	forWait bool
+}
+
+func (m *Manager) NewOrderTracker(cfg OrderTrackerConfig) *OrderTracker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 256
+	}
+	return &OrderTracker{
+		m:       m,
+		cfg:     cfg,
+		events:  make(chan OrderEvent, cfg.Buffer),
+		watched: make(map[string]*trackedOrder),
+		waiters: make(map[string][]chan *Order),
+	}
+}
+
+// Watch starts tracking orderIDs. Orders already watched are left as they are.
+func (t *OrderTracker) Watch(orderIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range orderIDs {
+		if s, ok := t.watched[id]; ok {
+			s.forWait = false
+		} else if id != "" {
+			t.watched[id] = &trackedOrder{filled: -1}
+		}
+	}
+}
+
+// WatchResponse watches every order in resp, e.g. straight after PlaceOrder.
+func (t *OrderTracker) WatchResponse(resp *OrderResponse) {
+	if resp != nil && resp.Data != nil && !resp.DryRun {
+		t.Watch(resp.Data.OrderIDs...)
+	}
+}
+
+func (t *OrderTracker) Unwatch(orderIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range orderIDs {
+		delete(t.watched, id)
+	}
+}
+
+// Watching returns the number of orders being tracked.
+func (t *OrderTracker) Watching() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.watched)
+}
+
+// OnEvent registers fn to run, on the tracker's goroutine, for every event.
+// Call the returned function to remove it.
+func (t *OrderTracker) OnEvent(fn func(OrderEvent)) (remove func()) {
+	l := &orderListener{fn: fn}
+	t.mu.Lock()
+	t.callbacks = append(slices.Clip(t.callbacks), l)
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.callbacks = slices.DeleteFunc(slices.Clone(t.callbacks), func(c *orderListener) bool { return c == l })
+	}
+}
+
+// Events returns the channel events are delivered on. It is never closed.
+func (t *OrderTracker) Events() <-chan OrderEvent {
+	return t.events
+}
+
+// Start polls the order book until ctx is done or Stop is called.
+func (t *OrderTracker) Start(ctx context.Context) {
+	t.mu.Lock()
+	if t.cancel != nil {
+		t.mu.Unlock()
+		return
+	}
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.done = make(chan struct{})
+	done := t.done
+	t.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(t.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.poll(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the current poll to finish.
+func (t *OrderTracker) Stop() {
+	t.mu.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (t *OrderTracker) poll(ctx context.Context) {
+	if t.Watching() == 0 {
+		return
+	}
+	orders, err := t.m.GetOrderBook(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			t.m.logger.Warn("order tracker poll failed", "error", err)
+		}
+		return
+	}
+	for i := range orders {
+		t.Update(&orders[i])
+	}
+}
+
+// Update applies an order state obtained elsewhere, such as an order update
+// stream. Updates for orders that are not watched are ignored.
+func (t *OrderTracker) Update(order *Order) {
+	now := time.Now()
+
+	t.mu.Lock()
+	state, ok := t.watched[order.OrderID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	events := state.advance(order)
+	final := len(events) > 0 && events[len(events)-1].Final()
+	var waiters []chan *Order
+	if final {
+		delete(t.watched, order.OrderID)
+		waiters = t.waiters[order.OrderID]
+		delete(t.waiters, order.OrderID)
+	}
+	callbacks := t.callbacks
+	t.mu.Unlock()
+
+	for _, typ := range events {
+		ev := OrderEvent{Type: typ, OrderID: order.OrderID, Order: order, At: now}
+		for _, l := range callbacks {
+			l.fn(ev)
+		}
+		select {
+		case t.events <- ev:
+		default:
+			t.m.logger.Warn("order event dropped", "order_id", order.OrderID, "event", typ)
+		}
+	}
+	for _, w := range waiters {
+		w <- order
+	}
+}
+
+// advance returns the events implied by moving from the last seen state to
+// order.
+func (s *trackedOrder) advance(order *Order) []OrderEventType {
+	var events []OrderEventType
+	switch order.Status {
+	case "complete":
+		events = append(events, OrderFilled)
+	case "rejected":
+		events = append(events, OrderRejected)
+	case "cancelled":
+		if order.FilledQuantity > s.filled && s.filled >= 0 {
+			events = append(events, OrderPartiallyFilled)
+		}
+		events = append(events, OrderCancelled)
+	default:
+		if !s.accepted && !pendingSubmission(order.Status) {
+			s.accepted = true
+			events = append(events, OrderAccepted)
+		}
+		if order.FilledQuantity > max(s.filled, 0) {
+			events = append(events, OrderPartiallyFilled)
+		}
+	}
+	s.filled = order.FilledQuantity
+	return events
+}
+
+// pendingSubmission reports whether status is one of the broker-side states
+// an order passes through before the exchange has it.
+func pendingSubmission(status string) bool {
+	switch status {
+	case "", "put order req received", "validation pending", "open pending":
+		return true
+	}
+	return false
+}
+
+// Wait watches orderID if it is not already, and blocks until it reaches a
+// final state or ctx is done. The tracker must be started, or fed through
+// Update, for Wait to return. An order watched only for Wait is unwatched
+// again when the last waiter gives up.
+func (t *OrderTracker) Wait(ctx context.Context, orderID string) (*Order, error) {
+	ch := make(chan *Order, 1)
+	t.mu.Lock()
+	if _, ok := t.watched[orderID]; !ok {
+		t.watched[orderID] = &trackedOrder{filled: -1, forWait: true}
+	}
+	t.waiters[orderID] = append(t.waiters[orderID], ch)
+	t.mu.Unlock()
+
+	select {
+	case order := <-ch:
+		return order, nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		waiters := slices.DeleteFunc(t.waiters[orderID], func(w chan *Order) bool { return w == ch })
+		if len(waiters) > 0 {
+			t.waiters[orderID] = waiters
+		} else {
+			delete(t.waiters, orderID)
+			if s, ok := t.watched[orderID]; ok && s.forWait {
+				delete(t.watched, orderID)
+			}
+		}
+		t.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
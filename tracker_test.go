@@ -0,0 +1,155 @@
+package upstox
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOrderTrackerEvents(t *testing.T) {
+	tr := NewManager("id", "secret", "token").NewOrderTracker(OrderTrackerConfig{})
+	tr.Watch("1")
+
+	var got []OrderEventType
+	remove := tr.OnEvent(func(ev OrderEvent) { got = append(got, ev.Type) })
+
+	updates := []Order{
+		{OrderID: "1", Status: "put order req received", Quantity: 10},
+		{OrderID: "1", Status: "open", Quantity: 10},
+		{OrderID: "1", Status: "open", Quantity: 10, FilledQuantity: 4},
+		{OrderID: "1", Status: "open", Quantity: 10, FilledQuantity: 4},
+		{OrderID: "1", Status: "complete", Quantity: 10, FilledQuantity: 10},
+		// Ignored: the order is no longer watched.
+		{OrderID: "1", Status: "complete", Quantity: 10, FilledQuantity: 10},
+		{OrderID: "2", Status: "open", Quantity: 1},
+	}
+	for i := range updates {
+		tr.Update(&updates[i])
+	}
+
+	want := []OrderEventType{OrderAccepted, OrderPartiallyFilled, OrderFilled}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if tr.Watching() != 0 {
+		t.Errorf("still watching %d orders", tr.Watching())
+	}
+
+	remove()
+	tr.Watch("3")
+	tr.Update(&Order{OrderID: "3", Status: "rejected"})
+	if len(got) != len(want) {
+		t.Error("removed listener still called")
+	}
+
+	var channel []OrderEventType
+	for len(tr.Events()) > 0 {
+		channel = append(channel, (<-tr.Events()).Type)
+	}
+	if want := append(want, OrderRejected); !slices.Equal(channel, want) {
+		t.Errorf("Events() = %v, want %v", channel, want)
+	}
+}
+
+func TestOrderTrackerCancelAfterPartialFill(t *testing.T) {
+	tr := NewManager("id", "secret", "token").NewOrderTracker(OrderTrackerConfig{})
+	tr.Watch("1")
+
+	var got []OrderEventType
+	tr.OnEvent(func(ev OrderEvent) { got = append(got, ev.Type) })
+	tr.Update(&Order{OrderID: "1", Status: "open", Quantity: 10})
+	tr.Update(&Order{OrderID: "1", Status: "cancelled", Quantity: 10, FilledQuantity: 3})
+
+	want := []OrderEventType{OrderAccepted, OrderPartiallyFilled, OrderCancelled}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestOrderTrackerWaitPolls(t *testing.T) {
+	ctx := context.Background()
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		order := `{"order_id":"1","status":"open","quantity":1}`
+		if n >= 3 {
+			order = `{"order_id":"1","status":"complete","quantity":1,"filled_quantity":1,"average_price":94}`
+		}
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":[`+order+`]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+
+	tr := m.NewOrderTracker(OrderTrackerConfig{PollInterval: 5 * time.Millisecond})
+	tr.Start(ctx)
+	defer tr.Stop()
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	order, err := tr.Wait(waitCtx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "complete" || order.AveragePrice != 94 {
+		t.Errorf("order = %+v", order)
+	}
+	if n := stub.count(); n < 3 {
+		t.Errorf("completed after %d polls", n)
+	}
+}
+
+func TestOrderTrackerWaitTimesOut(t *testing.T) {
+	tr := NewManager("id", "secret", "token").NewOrderTracker(OrderTrackerConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tr.Wait(ctx, "1"); err != context.DeadlineExceeded {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := tr.Watching(); n != 0 || len(tr.waiters) != 0 {
+		t.Errorf("after timing out: %d watched, waiters %v", n, tr.waiters)
+	}
+
+	// An order the caller watched stays watched.
+	tr.Watch("2")
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := tr.Wait(ctx, "2"); err != context.DeadlineExceeded {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := tr.Watching(); n != 1 {
+		t.Errorf("%d watched, want the order watched before Wait", n)
+	}
+}
+
+func TestConfirmationTracker(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"status":"success","data":{"order_ids":["1"]}}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub), WithConfirmation(5*time.Second))
+	tr := m.NewOrderTracker(OrderTrackerConfig{})
+	m.SetConfirmationTracker(tr)
+
+	var rejected []RejectionReason
+	m.OnOrderRejected(func(req OrderRequest, reason RejectionReason, msg string) { rejected = append(rejected, reason) })
+
+	go func() {
+		for tr.Watching() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		tr.Update(&Order{OrderID: "1", Status: "rejected", StatusMessage: "insufficient funds"})
+	}()
+
+	start := time.Now()
+	resp, err := m.PlaceLimitOrder(context.Background(), "NSE_EQ|X", 1, "BUY", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("placement took %v, want it to end on the rejection", elapsed)
+	}
+	if resp.Status != "error" || len(rejected) != 1 {
+		t.Errorf("response = %+v, rejections = %v", resp, rejected)
+	}
+	if n := stub.count(); n != 1 {
+		t.Errorf("%d requests sent, want only the placement", n)
+	}
+}
@@ -0,0 +1,269 @@
+package upstox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TrailingStopConfig describes a client-side trailing stop for one position.
+type TrailingStopConfig struct {
+	InstrumentKey string
+
+	// Trail is the distance kept between the best price seen and the stop.
+	// TrailPct is used instead when Trail is zero.
+	Trail    float64
+	TrailPct float64
+
+	// TickSize rounds the stop away from the market.
+	TickSize float64
+
+	// ExitAttempts is how many exit orders are tried before the stop is
+	// given up, 5 if zero.
+	ExitAttempts int
+}
+
+// trailExitBackoff is the wait after the first failed exit; it doubles with
+// each further failure.
+const trailExitBackoff = time.Second
+
+// TrailingStopExit reports a stop that was breached and the exit sent for
+// it. Err is set if the exit order failed; the stop then stays armed and
+// fires again on the first breaching tick after a backoff, until
+// ExitAttempts is used up. Failed marks that last attempt: the stop is
+// dropped and the position is left open.
+type TrailingStopExit struct {
+	InstrumentKey string
+	Stop          float64
+	Price         float64
+	Quantity      int
+	OrderID       string
+	Err           error
+	Attempt       int
+	Failed        bool
+	At            time.Time
+}
+
+// TrailingStop follows the feed's last traded price for any number of
+// positions, ratchets each stop as the price moves in the position's favour,
+// and squares the position off with a market order once the price crosses
+// the stop. Unlike GTTTrailer the stop lives in this process: nothing
+// protects the position while it is not running.
+//
+// Stops are kept per instrument and fed by a tick listener, so they carry on
+// across feed reconnects; the first tick after a gap is checked like any
+// other. Exits go through even while trading is halted.
+type TrailingStop struct {
+	m   *Manager
+	ctx context.Context
+
+	mu     sync.Mutex
+	stops  map[string]*trailState
+	onExit func(TrailingStopExit)
+}
+
+type trailState struct {
+	cfg      TrailingStopConfig
+	long     bool
+	quantity int
+	product  string
+	best     float64
+	stop     float64
+	exiting  bool
+	attempts int
+	retryAt  time.Time
+}
+
+// NewTrailingStop creates a TrailingStop whose exit orders are sent with ctx.
+func (m *Manager) NewTrailingStop(ctx context.Context) *TrailingStop {
+	return &TrailingStop{m: m, ctx: ctx, stops: make(map[string]*trailState)}
+}
+
+// OnExit registers fn to run after every exit attempt.
+func (t *TrailingStop) OnExit(fn func(TrailingStopExit)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onExit = fn
+}
+
+// Track starts trailing the open position in cfg.InstrumentKey, starting
+// from its last price.
+func (t *TrailingStop) Track(ctx context.Context, cfg TrailingStopConfig) error {
+	positions, err := t.m.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+	for _, pos := range positions {
+		if pos.InstrumentToken == cfg.InstrumentKey && pos.Quantity != 0 {
+			return t.TrackQuantity(cfg, pos.Quantity, pos.Product, pos.LastPrice)
+		}
+	}
+	return fmt.Errorf("no open position in %s", cfg.InstrumentKey)
+}
+
+// TrackQuantity trails a position given directly: quantity is signed,
+// negative for shorts, and price is where the trail starts.
+func (t *TrailingStop) TrackQuantity(cfg TrailingStopConfig, quantity int, product string, price float64) error {
+	if cfg.Trail <= 0 && cfg.TrailPct <= 0 {
+		return fmt.Errorf("trail distance must be positive")
+	}
+	if quantity == 0 {
+		return fmt.Errorf("quantity must be non-zero")
+	}
+	if price <= 0 {
+		return fmt.Errorf("no starting price for %s", cfg.InstrumentKey)
+	}
+
+	s := &trailState{cfg: cfg, long: quantity > 0, quantity: quantity, product: product, best: price}
+	if !s.long {
+		s.quantity = -quantity
+	}
+	s.stop = s.stopFor(price)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stops[cfg.InstrumentKey] = s
+	return nil
+}
+
+func (t *TrailingStop) Untrack(instrumentKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.stops, instrumentKey)
+}
+
+// Stop returns the current stop level for instrumentKey.
+func (t *TrailingStop) Stop(instrumentKey string) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stops[instrumentKey]
+	if !ok {
+		return 0, false
+	}
+	return s.stop, true
+}
+
+// Keys returns the instruments being trailed.
+func (t *TrailingStop) Keys() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]string, 0, len(t.stops))
+	for key := range t.stops {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Attach subscribes the trailed instruments on wsm and feeds its ticks to
+// the stops. Instruments tracked later must be subscribed by the caller.
+func (t *TrailingStop) Attach(wsm *WebSocketManager) (detach func(), err error) {
+	if keys := t.Keys(); len(keys) > 0 {
+		if err := wsm.Subscribe(keys...); err != nil {
+			return nil, fmt.Errorf("failed to subscribe trailed instruments: %w", err)
+		}
+	}
+	return wsm.AddTickListener(func(tick Tick) {
+		t.OnPrice(tick.InstrumentKey, tick.LTP)
+	}), nil
+}
+
+// OnPrice ratchets the stop for instrumentKey or, if price crosses it,
+// sends the exit on its own goroutine.
+func (t *TrailingStop) OnPrice(instrumentKey string, price float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stops[instrumentKey]
+	if !ok || price <= 0 || s.exiting {
+		return
+	}
+
+	if (s.long && price <= s.stop) || (!s.long && price >= s.stop) {
+		if time.Now().Before(s.retryAt) {
+			return
+		}
+		s.exiting = true
+		go t.exit(instrumentKey, s, price)
+		return
+	}
+
+	if (s.long && price > s.best) || (!s.long && price < s.best) {
+		s.best = price
+		if level := s.stopFor(price); (s.long && level > s.stop) || (!s.long && level < s.stop) {
+			s.stop = level
+		}
+	}
+}
+
+func (t *TrailingStop) exit(instrumentKey string, s *trailState, price float64) {
+	t.mu.Lock()
+	side := string(OrderSideSell)
+	if !s.long {
+		side = string(OrderSideBuy)
+	}
+	req := marketOrderRequest(instrumentKey, s.quantity, side)
+	if s.product != "" {
+		req.Product = s.product
+	}
+	req.Force = true
+	s.attempts++
+	report := TrailingStopExit{InstrumentKey: instrumentKey, Stop: s.stop, Price: price, Quantity: s.quantity, Attempt: s.attempts, At: time.Now()}
+	t.mu.Unlock()
+
+	resp, err := t.m.submitOrder(t.ctx, req)
+
+	t.mu.Lock()
+	if err != nil {
+		report.Err = err
+		s.exiting = false
+		attempts := s.cfg.ExitAttempts
+		if attempts <= 0 {
+			attempts = 5
+		}
+		if s.attempts >= attempts {
+			report.Failed = true
+			if t.stops[instrumentKey] == s {
+				delete(t.stops, instrumentKey)
+			}
+			t.m.logger.Error("trailing stop given up", "instrument_key", instrumentKey, "stop", report.Stop, "attempts", s.attempts, "error", err)
+		} else {
+			s.retryAt = time.Now().Add(trailExitBackoff << (s.attempts - 1))
+			t.m.logger.Error("trailing stop exit failed", "instrument_key", instrumentKey, "stop", report.Stop, "attempt", s.attempts, "error", err)
+		}
+	} else {
+		report.OrderID = resp.Data.OrderIDs[0]
+		if t.stops[instrumentKey] == s {
+			delete(t.stops, instrumentKey)
+		}
+	}
+	fn := t.onExit
+	t.mu.Unlock()
+
+	if fn != nil {
+		fn(report)
+	}
+}
+
+func (s *trailState) stopFor(price float64) float64 {
+	distance := s.cfg.Trail
+	if distance <= 0 {
+		distance = price * s.cfg.TrailPct / 100
+	}
+	level := price + distance
+	if s.long {
+		level = price - distance
+	}
+
+	tick := s.cfg.TickSize
+	if tick <= 0 {
+		return level
+	}
+	if s.long {
+		return math.Floor(level/tick+1e-9) * tick
+	}
+	return math.Ceil(level/tick-1e-9) * tick
+}
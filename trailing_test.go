@@ -0,0 +1,118 @@
+package upstox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func waitExit(t *testing.T, exits <-chan TrailingStopExit) TrailingStopExit {
+	t.Helper()
+	select {
+	case exit := <-exits:
+		return exit
+	case <-time.After(time.Second):
+		t.Fatal("no exit reported")
+	}
+	return TrailingStopExit{}
+}
+
+func TestTrailingStopRatchetsAndExits(t *testing.T) {
+	m := NewPaperManager("id", "secret", "token")
+	key := "NSE_EQ|X"
+	m.Paper().OnTick(paperTick(key, 100))
+
+	ts := m.NewTrailingStop(context.Background())
+	exits := make(chan TrailingStopExit, 1)
+	ts.OnExit(func(e TrailingStopExit) { exits <- e })
+
+	if err := ts.TrackQuantity(TrailingStopConfig{InstrumentKey: key, Trail: 2, TickSize: 0.05}, 10, "I", 100); err != nil {
+		t.Fatal(err)
+	}
+	stop := func() float64 {
+		s, _ := ts.Stop(key)
+		return s
+	}
+	if s := stop(); s != 98 {
+		t.Fatalf("initial stop = %v, want 98", s)
+	}
+
+	ts.OnPrice(key, 105)
+	ts.OnPrice(key, 104)
+	if s := stop(); s != 103 {
+		t.Fatalf("stop = %v after a high of 105, want 103", s)
+	}
+
+	m.Paper().OnTick(paperTick(key, 102.9))
+	ts.OnPrice(key, 102.9)
+	exit := waitExit(t, exits)
+	if exit.Err != nil || exit.OrderID == "" || exit.Quantity != 10 || exit.Stop != 103 || exit.Attempt != 1 {
+		t.Fatalf("exit = %+v", exit)
+	}
+	if _, ok := ts.Stop(key); ok {
+		t.Error("stop still armed after exiting")
+	}
+	order, err := m.GetOrderDetails(context.Background(), exit.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.TransactionType != "SELL" || order.OrderType != "MARKET" || order.Product != "I" {
+		t.Errorf("exit order = %+v", order)
+	}
+}
+
+func TestTrailingStopShort(t *testing.T) {
+	ts := NewPaperManager("id", "secret", "token").NewTrailingStop(context.Background())
+	key := "NSE_EQ|X"
+	if err := ts.TrackQuantity(TrailingStopConfig{InstrumentKey: key, TrailPct: 1}, -5, "I", 200); err != nil {
+		t.Fatal(err)
+	}
+	ts.OnPrice(key, 190)
+	ts.OnPrice(key, 195)
+	if s, _ := ts.Stop(key); s != 191.9 {
+		t.Errorf("stop = %v after a low of 190, want 191.9", s)
+	}
+}
+
+func TestTrailingStopGivesUp(t *testing.T) {
+	stub := &apiStub{respond: func(req *http.Request, n int) (*http.Response, error) {
+		return jsonResponse(req, http.StatusBadRequest, `{"status":"error","errors":[{"message":"RMS rejected"}]}`), nil
+	}}
+	m := NewManager("id", "secret", "token", WithTransport(stub))
+	key := "NSE_EQ|X"
+
+	ts := m.NewTrailingStop(context.Background())
+	exits := make(chan TrailingStopExit, 1)
+	ts.OnExit(func(e TrailingStopExit) { exits <- e })
+	if err := ts.TrackQuantity(TrailingStopConfig{InstrumentKey: key, Trail: 1, ExitAttempts: 2}, 10, "I", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.OnPrice(key, 98)
+	if exit := waitExit(t, exits); exit.Err == nil || exit.Failed || exit.Attempt != 1 {
+		t.Fatalf("first exit = %+v", exit)
+	}
+
+	// Inside the backoff nothing is sent.
+	ts.OnPrice(key, 97)
+	select {
+	case exit := <-exits:
+		t.Fatalf("exit during backoff: %+v", exit)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ts.mu.Lock()
+	ts.stops[key].retryAt = time.Time{}
+	ts.mu.Unlock()
+	ts.OnPrice(key, 97)
+	if exit := waitExit(t, exits); !exit.Failed || exit.Attempt != 2 {
+		t.Fatalf("last exit = %+v", exit)
+	}
+	if _, ok := ts.Stop(key); ok {
+		t.Error("stop still armed after giving up")
+	}
+	if n := stub.count(); n != 2 {
+		t.Errorf("%d exit orders sent, want 2", n)
+	}
+}
@@ -116,6 +116,17 @@ type LiveFeedMessage struct {
 	CurrentTS int64                `json:"currentTs"`
 }
 
+// Tick is the price-level view of a single feed update, shared by the batched,
+// channel and aggregation delivery paths.
+type Tick struct {
+	InstrumentKey string
+	LTP           float64
+	LTQ           int64
+	LTT           int64
+	CP            float64
+	ReceivedAt    time.Time
+}
+
 type MarketInfoCallback func(MarketInfoMessage)
 type LiveFeedCallback func(LiveFeedMessage)
 
@@ -133,6 +144,7 @@ type AuthorizeResponse struct {
 	Data   struct {
 		AuthorizedRedirectURI string `json:"authorized_redirect_uri"`
 	} `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
 }
 
 type InstrumentSubscription struct {
@@ -177,10 +189,14 @@ type OrderRequest struct {
 	TriggerPrice      float64 `json:"trigger_price"`
 	IsAMO             bool    `json:"is_amo"`
 	Slice             bool    `json:"slice"`
+
+	// Force bypasses the Manager's duplicate-order window; it is never sent.
+	Force bool `json:"-"`
 }
 
 type OrderResponseData struct {
 	OrderIDs []string `json:"order_ids"`
+	OrderID  string   `json:"order_id,omitempty"` // v2 only; copied into OrderIDs
 }
 
 type OrderMetadata struct {
@@ -208,6 +224,7 @@ type OrderResponse struct {
 	Metadata *OrderMetadata     `json:"metadata,omitempty"`
 	Errors   []OrderError       `json:"errors,omitempty"`
 	Summary  *OrderSummary      `json:"summary,omitempty"`
+	DryRun   bool               `json:"dry_run,omitempty"`
 }
 
 type Position struct {
@@ -273,19 +290,47 @@ type Order struct {
 	OrderRefID        string  `json:"order_ref_id"`
 }
 
+// Trade is a single fill. An order filled in parts has one Trade per fill,
+// each with its own exchange trade ID and price.
+type Trade struct {
+	Exchange          string  `json:"exchange"`
+	Product           string  `json:"product"`
+	TradingSymbol     string  `json:"trading_symbol"`
+	InstrumentToken   string  `json:"instrument_token"`
+	OrderType         string  `json:"order_type"`
+	TransactionType   string  `json:"transaction_type"`
+	Quantity          int     `json:"quantity"`
+	ExchangeOrderID   string  `json:"exchange_order_id"`
+	OrderID           string  `json:"order_id"`
+	ExchangeTimestamp string  `json:"exchange_timestamp"`
+	AveragePrice      float64 `json:"average_price"`
+	TradeID           string  `json:"trade_id"`
+	OrderRefID        string  `json:"order_ref_id"`
+	OrderTimestamp    string  `json:"order_timestamp"`
+}
+
+type TradesResponse struct {
+	Status string       `json:"status"`
+	Data   []Trade      `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
 type PositionResponse struct {
-	Status string     `json:"status"`
-	Data   []Position `json:"data"`
+	Status string       `json:"status"`
+	Data   []Position   `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
 }
 
 type OrderBookResponse struct {
-	Status string  `json:"status"`
-	Data   []Order `json:"data"`
+	Status string       `json:"status"`
+	Data   []Order      `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
 }
 
 type OrderDetailResponse struct {
-	Status string `json:"status"`
-	Data   Order  `json:"data"`
+	Status string       `json:"status"`
+	Data   Order        `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
 }
 
 type MarginData struct {
@@ -304,6 +349,28 @@ type FundsData struct {
 }
 
 type FundsResponse struct {
-	Status string    `json:"status"`
-	Data   FundsData `json:"data"`
+	Status string       `json:"status"`
+	Data   FundsData    `json:"data"`
+	Errors []OrderError `json:"errors,omitempty"`
+}
+
+func (r *OrderResponse) envelope() (string, []OrderError)       { return r.Status, r.Errors }
+func (r *PositionResponse) envelope() (string, []OrderError)    { return r.Status, r.Errors }
+func (r *OrderBookResponse) envelope() (string, []OrderError)   { return r.Status, r.Errors }
+func (r *OrderDetailResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
+func (r *FundsResponse) envelope() (string, []OrderError)       { return r.Status, r.Errors }
+func (r *TradesResponse) envelope() (string, []OrderError)      { return r.Status, r.Errors }
+func (r *AuthorizeResponse) envelope() (string, []OrderError)   { return r.Status, r.Errors }
+
+type LTPQuote struct {
+	LastPrice       float64 `json:"last_price"`
+	InstrumentToken string  `json:"instrument_token"`
 }
+
+type LTPQuoteResponse struct {
+	Status string              `json:"status"`
+	Data   map[string]LTPQuote `json:"data"`
+	Errors []OrderError        `json:"errors,omitempty"`
+}
+
+func (r *LTPQuoteResponse) envelope() (string, []OrderError) { return r.Status, r.Errors }
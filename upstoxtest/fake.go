@@ -0,0 +1,495 @@
+// Package upstoxtest provides an in-memory upstox.UpstoxClient for unit tests.
+//
+// A Fake never touches the network. Orders are accepted as "open" and stay
+// that way until the test calls Fill, Reject or the order is cancelled;
+// positions, holdings, funds, prices and candles return whatever the test
+// set. Every call is recorded and can be made to fail with FailNext.
+package upstoxtest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	upstox "github.com/adeludedperson/go-upstox"
+)
+
+// Call is one recorded method call. Args are the arguments after the context.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+type Fake struct {
+	mu        sync.Mutex
+	seq       int
+	orders    map[string]*upstox.Order
+	order     []string
+	trades    map[string][]upstox.Trade
+	positions []upstox.Position
+	holdings  []upstox.Holding
+	funds     upstox.FundsData
+	prices    map[string]float64
+	candles   map[string][]upstox.Candle
+	feeds     []*upstox.WebSocketManager
+	failures  map[string][]error
+	calls     []Call
+}
+
+var _ upstox.UpstoxClient = (*Fake)(nil)
+
+func NewFake() *Fake {
+	return &Fake{
+		orders:   make(map[string]*upstox.Order),
+		trades:   make(map[string][]upstox.Trade),
+		prices:   make(map[string]float64),
+		candles:  make(map[string][]upstox.Candle),
+		failures: make(map[string][]error),
+	}
+}
+
+// FailNext makes the next call to method (e.g. "PlaceOrder") return err.
+// Repeated calls queue several failures.
+func (f *Fake) FailNext(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], err)
+}
+
+// Calls returns every call made so far, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// CallsTo returns the recorded calls to method.
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, c := range f.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// record logs a call and pops a queued failure for it. Call with f.mu held.
+func (f *Fake) record(method string, args ...any) error {
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	if errs := f.failures[method]; len(errs) > 0 {
+		f.failures[method] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+func (f *Fake) SetPositions(positions ...upstox.Position) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.positions = slices.Clone(positions)
+}
+
+func (f *Fake) SetHoldings(holdings ...upstox.Holding) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holdings = slices.Clone(holdings)
+}
+
+func (f *Fake) SetFunds(funds upstox.FundsData) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.funds = funds
+}
+
+func (f *Fake) SetLTP(instrumentKey string, price float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prices[instrumentKey] = price
+}
+
+// SetCandles sets the candles GetHistoricalCandles returns for instrumentKey,
+// whatever the interval; the from/to range is applied.
+func (f *Fake) SetCandles(instrumentKey string, candles ...upstox.Candle) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.candles[instrumentKey] = slices.Clone(candles)
+}
+
+// PushTick delivers tick to every feed created through the fake, and
+// updates the price GetLTP returns.
+func (f *Fake) PushTick(tick upstox.Tick) {
+	f.mu.Lock()
+	f.prices[tick.InstrumentKey] = tick.LTP
+	feeds := slices.Clone(f.feeds)
+	f.mu.Unlock()
+
+	for _, wsm := range feeds {
+		wsm.InjectTick(tick)
+	}
+}
+
+func (f *Fake) PlaceOrder(ctx context.Context, orderReq upstox.OrderRequest) (*upstox.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("PlaceOrder", orderReq); err != nil {
+		return nil, err
+	}
+	return f.placeLocked(orderReq), nil
+}
+
+func (f *Fake) PlaceLimitOrder(ctx context.Context, instrumentToken string, quantity int, side string, price float64) (*upstox.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("PlaceLimitOrder", instrumentToken, quantity, side, price); err != nil {
+		return nil, err
+	}
+	return f.placeLocked(upstox.OrderRequest{
+		Quantity:        quantity,
+		Product:         string(upstox.ProductIntraday),
+		Validity:        string(upstox.ValidityDay),
+		Price:           price,
+		InstrumentToken: instrumentToken,
+		OrderType:       string(upstox.OrderTypeLimit),
+		TransactionType: side,
+	}), nil
+}
+
+func (f *Fake) PlaceMarketOrder(ctx context.Context, instrumentToken string, quantity int, side string) (*upstox.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("PlaceMarketOrder", instrumentToken, quantity, side); err != nil {
+		return nil, err
+	}
+	return f.placeLocked(marketOrder(instrumentToken, quantity, side)), nil
+}
+
+func marketOrder(instrumentToken string, quantity int, side string) upstox.OrderRequest {
+	return upstox.OrderRequest{
+		Quantity:        quantity,
+		Product:         string(upstox.ProductIntraday),
+		Validity:        string(upstox.ValidityDay),
+		InstrumentToken: instrumentToken,
+		OrderType:       string(upstox.OrderTypeMarket),
+		TransactionType: side,
+	}
+}
+
+// placeLocked books an open order without recording a call, so methods that
+// place orders internally log only themselves.
+func (f *Fake) placeLocked(orderReq upstox.OrderRequest) *upstox.OrderResponse {
+	f.seq++
+	o := &upstox.Order{
+		OrderID:           "FAKE-" + strconv.Itoa(f.seq),
+		Product:           orderReq.Product,
+		Price:             orderReq.Price,
+		Quantity:          orderReq.Quantity,
+		PendingQuantity:   orderReq.Quantity,
+		Status:            "open",
+		Tag:               orderReq.Tag,
+		InstrumentToken:   orderReq.InstrumentToken,
+		OrderType:         orderReq.OrderType,
+		Validity:          orderReq.Validity,
+		TriggerPrice:      orderReq.TriggerPrice,
+		DisclosedQuantity: orderReq.DisclosedQuantity,
+		TransactionType:   orderReq.TransactionType,
+		IsAMO:             orderReq.IsAMO,
+		OrderTimestamp:    time.Now().Format(time.DateTime),
+	}
+	f.orders[o.OrderID] = o
+	f.order = append(f.order, o.OrderID)
+
+	return &upstox.OrderResponse{
+		Status: "success",
+		Data:   &upstox.OrderResponseData{OrderIDs: []string{o.OrderID}},
+	}
+}
+
+// Fill completes an open order at price and records a trade for it.
+func (f *Fake) Fill(orderID string, price float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, err := f.openOrder(orderID)
+	if err != nil {
+		return err
+	}
+	o.Status = "complete"
+	o.AveragePrice = price
+	o.FilledQuantity = o.Quantity
+	o.PendingQuantity = 0
+	o.ExchangeTimestamp = time.Now().Format(time.DateTime)
+	f.trades[orderID] = append(f.trades[orderID], upstox.Trade{
+		Product:           o.Product,
+		InstrumentToken:   o.InstrumentToken,
+		OrderType:         o.OrderType,
+		TransactionType:   o.TransactionType,
+		Quantity:          o.Quantity,
+		OrderID:           orderID,
+		ExchangeTimestamp: o.ExchangeTimestamp,
+		AveragePrice:      price,
+		TradeID:           orderID + "-" + strconv.Itoa(len(f.trades[orderID])+1),
+		OrderTimestamp:    o.OrderTimestamp,
+	})
+	return nil
+}
+
+// Reject marks an open order rejected with message.
+func (f *Fake) Reject(orderID, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	o, err := f.openOrder(orderID)
+	if err != nil {
+		return err
+	}
+	o.Status = "rejected"
+	o.StatusMessage = message
+	o.PendingQuantity = 0
+	return nil
+}
+
+func (f *Fake) openOrder(orderID string) (*upstox.Order, error) {
+	o, ok := f.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", upstox.ErrOrderNotFound, orderID)
+	}
+	if o.Status != "open" && o.Status != "trigger pending" {
+		return nil, fmt.Errorf("%w: order %s is %s", upstox.ErrNotModifiable, orderID, o.Status)
+	}
+	return o, nil
+}
+
+func (f *Fake) ModifyOrder(ctx context.Context, modReq upstox.ModifyOrderRequest) (*upstox.OrderIDResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("ModifyOrder", modReq); err != nil {
+		return nil, err
+	}
+	o, err := f.openOrder(modReq.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	o.Quantity, o.PendingQuantity = modReq.Quantity, modReq.Quantity
+	o.Validity = modReq.Validity
+	o.Price = modReq.Price
+	o.OrderType = modReq.OrderType
+	o.DisclosedQuantity = modReq.DisclosedQuantity
+	o.TriggerPrice = modReq.TriggerPrice
+	return orderIDResponse(modReq.OrderID), nil
+}
+
+func (f *Fake) CancelOrder(ctx context.Context, orderID string) (*upstox.OrderIDResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CancelOrder", orderID); err != nil {
+		return nil, err
+	}
+	o, err := f.openOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	o.Status = "cancelled"
+	o.PendingQuantity = 0
+	return orderIDResponse(orderID), nil
+}
+
+func (f *Fake) CancelAllOrders(ctx context.Context, filters ...upstox.CancelAllFilter) (*upstox.MultiOrderResponse, error) {
+	q := url.Values{}
+	for _, filter := range filters {
+		filter(q)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CancelAllOrders", q); err != nil {
+		return nil, err
+	}
+
+	resp := &upstox.MultiOrderResponse{Status: "success"}
+	for _, id := range f.order {
+		o := f.orders[id]
+		if o.Status != "open" && o.Status != "trigger pending" {
+			continue
+		}
+		if seg := q.Get("segment"); seg != "" && !strings.HasPrefix(o.InstrumentToken, seg+"|") {
+			continue
+		}
+		if tag := q.Get("tag"); tag != "" && o.Tag != tag {
+			continue
+		}
+		o.Status = "cancelled"
+		o.PendingQuantity = 0
+		resp.Data.OrderIDs = append(resp.Data.OrderIDs, id)
+	}
+	resp.Summary.Total = len(resp.Data.OrderIDs)
+	resp.Summary.Success = len(resp.Data.OrderIDs)
+	return resp, nil
+}
+
+func orderIDResponse(orderID string) *upstox.OrderIDResponse {
+	resp := &upstox.OrderIDResponse{Status: "success"}
+	resp.Data.OrderID = orderID
+	return resp
+}
+
+func (f *Fake) GetOrderBook(ctx context.Context) ([]upstox.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOrderBook"); err != nil {
+		return nil, err
+	}
+	orders := make([]upstox.Order, 0, len(f.order))
+	for _, id := range f.order {
+		orders = append(orders, *f.orders[id])
+	}
+	return orders, nil
+}
+
+func (f *Fake) GetOrderDetails(ctx context.Context, orderID string) (*upstox.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetOrderDetails", orderID); err != nil {
+		return nil, err
+	}
+	o, ok := f.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", upstox.ErrOrderNotFound, orderID)
+	}
+	order := *o
+	return &order, nil
+}
+
+func (f *Fake) GetTradesForOrder(ctx context.Context, orderID string) ([]upstox.Trade, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetTradesForOrder", orderID); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.trades[orderID]), nil
+}
+
+func (f *Fake) GetPositions(ctx context.Context) ([]upstox.Position, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetPositions"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.positions), nil
+}
+
+// ClosePosition places a market order against the open position in
+// instrumentToken, as Manager does; the position itself is left for the test
+// to update. Only the ClosePosition call is recorded, not the order it
+// places.
+func (f *Fake) ClosePosition(ctx context.Context, instrumentToken string) (*upstox.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("ClosePosition", instrumentToken); err != nil {
+		return nil, err
+	}
+	return f.closeLocked(instrumentToken)
+}
+
+func (f *Fake) closeLocked(instrumentToken string) (*upstox.OrderResponse, error) {
+	for _, pos := range f.positions {
+		if pos.InstrumentToken != instrumentToken || pos.Quantity == 0 {
+			continue
+		}
+		if pos.Quantity > 0 {
+			return f.placeLocked(marketOrder(instrumentToken, pos.Quantity, string(upstox.OrderSideSell))), nil
+		}
+		return f.placeLocked(marketOrder(instrumentToken, -pos.Quantity, string(upstox.OrderSideBuy))), nil
+	}
+	return nil, fmt.Errorf("no position found for instrument token: %s", instrumentToken)
+}
+
+func (f *Fake) CloseAllPositions(ctx context.Context) ([]upstox.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("CloseAllPositions"); err != nil {
+		return nil, err
+	}
+	var responses []upstox.OrderResponse
+	for _, pos := range f.positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		resp, err := f.closeLocked(pos.InstrumentToken)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+func (f *Fake) GetHoldings(ctx context.Context) ([]upstox.Holding, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetHoldings"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.holdings), nil
+}
+
+func (f *Fake) GetFundsAndMargin(ctx context.Context, segment ...string) (*upstox.FundsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetFundsAndMargin", segment); err != nil {
+		return nil, err
+	}
+	return &upstox.FundsResponse{Status: "success", Data: f.funds}, nil
+}
+
+// GetLTP returns the prices set with SetLTP or PushTick; unknown keys are
+// left out, as the API does.
+func (f *Fake) GetLTP(ctx context.Context, instrumentKeys ...string) (map[string]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetLTP", instrumentKeys); err != nil {
+		return nil, err
+	}
+	prices := make(map[string]float64, len(instrumentKeys))
+	for _, key := range instrumentKeys {
+		if p, ok := f.prices[key]; ok {
+			prices[key] = p
+		}
+	}
+	return prices, nil
+}
+
+func (f *Fake) GetHistoricalCandles(ctx context.Context, instrumentKey string, interval upstox.Interval, from, to time.Time) ([]upstox.Candle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("GetHistoricalCandles", instrumentKey, interval, from, to); err != nil {
+		return nil, err
+	}
+	var candles []upstox.Candle
+	for _, c := range f.candles[instrumentKey] {
+		if !c.Timestamp.Before(from) && !c.Timestamp.After(to) {
+			candles = append(candles, c)
+		}
+	}
+	return candles, nil
+}
+
+// NewWebSocketManager returns a feed that is never connected: do not Start
+// it. Ticks sent with PushTick reach its listeners and callbacks.
+func (f *Fake) NewWebSocketManager(ctx context.Context, instrumentKeys []string, onPriceUpdate func(string, float64, *int32)) (*upstox.WebSocketManager, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("NewWebSocketManager", instrumentKeys); err != nil {
+		return nil, err
+	}
+	wsm := upstox.NewWebSocketManager("", upstox.WebSocketConfig{
+		InstrumentKeys: slices.Clone(instrumentKeys),
+	}, onPriceUpdate)
+	f.feeds = append(f.feeds, wsm)
+	return wsm, nil
+}
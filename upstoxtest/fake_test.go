@@ -0,0 +1,213 @@
+package upstoxtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adeludedperson/go-upstox"
+	"github.com/adeludedperson/go-upstox/upstoxtest"
+)
+
+// exitLongs is the kind of strategy code Fake is for: it only sees the
+// UpstoxClient interface.
+func exitLongs(ctx context.Context, c upstox.UpstoxClient) ([]string, error) {
+	positions, err := c.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, pos := range positions {
+		if pos.Quantity <= 0 {
+			continue
+		}
+		resp, err := c.PlaceMarketOrder(ctx, pos.InstrumentToken, pos.Quantity, string(upstox.OrderSideSell))
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, resp.Data.OrderIDs...)
+	}
+	return ids, nil
+}
+
+func TestFakeAsClient(t *testing.T) {
+	ctx := context.Background()
+	fake := upstoxtest.NewFake()
+	fake.SetPositions(
+		upstox.Position{InstrumentToken: "NSE_EQ|A", Quantity: 5},
+		upstox.Position{InstrumentToken: "NSE_EQ|B", Quantity: -2},
+	)
+
+	ids, err := exitLongs(ctx, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("placed %v, want one exit", ids)
+	}
+
+	calls := fake.CallsTo("PlaceMarketOrder")
+	if len(calls) != 1 {
+		t.Fatalf("PlaceMarketOrder calls = %+v", fake.Calls())
+	}
+	if args := calls[0].Args; args[0] != "NSE_EQ|A" || args[1] != 5 || args[2] != "SELL" {
+		t.Errorf("PlaceMarketOrder args = %v", args)
+	}
+	if n := len(fake.CallsTo("PlaceOrder")); n != 0 {
+		t.Errorf("PlaceMarketOrder also recorded %d PlaceOrder calls", n)
+	}
+
+	order, err := fake.GetOrderDetails(ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "open" || order.OrderType != "MARKET" || order.TransactionType != "SELL" {
+		t.Errorf("order = %+v", order)
+	}
+}
+
+func TestFakeOrderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := upstoxtest.NewFake()
+
+	resp, err := fake.PlaceLimitOrder(ctx, "NSE_EQ|A", 10, "BUY", 99.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := resp.Data.OrderIDs[0]
+	if n := len(fake.CallsTo("PlaceLimitOrder")); n != 1 {
+		t.Errorf("%d PlaceLimitOrder calls recorded", n)
+	}
+
+	if _, err := fake.ModifyOrder(ctx, upstox.ModifyOrderRequest{OrderID: id, Quantity: 10, Price: 100, OrderType: "LIMIT", Validity: "DAY"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.Fill(id, 100); err != nil {
+		t.Fatal(err)
+	}
+	order, _ := fake.GetOrderDetails(ctx, id)
+	if order.Status != "complete" || order.FilledQuantity != 10 || order.AveragePrice != 100 {
+		t.Errorf("filled order = %+v", order)
+	}
+	trades, _ := fake.GetTradesForOrder(ctx, id)
+	if len(trades) != 1 || trades[0].Quantity != 10 {
+		t.Errorf("trades = %+v", trades)
+	}
+
+	if _, err := fake.CancelOrder(ctx, id); !errors.Is(err, upstox.ErrNotModifiable) {
+		t.Errorf("cancelling a filled order: %v", err)
+	}
+	if _, err := fake.CancelOrder(ctx, "missing"); !errors.Is(err, upstox.ErrOrderNotFound) {
+		t.Errorf("cancelling an unknown order: %v", err)
+	}
+
+	other, _ := fake.PlaceOrder(ctx, upstox.OrderRequest{InstrumentToken: "NSE_EQ|A", Quantity: 1, TransactionType: "BUY", OrderType: "LIMIT", Price: 90, Tag: "grid"})
+	if err := fake.Reject(other.Data.OrderIDs[0], "insufficient funds"); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := fake.GetOrderDetails(ctx, other.Data.OrderIDs[0]); o.RejectionReason() != upstox.RejectionInsufficientMargin {
+		t.Errorf("rejection reason = %s", o.RejectionReason())
+	}
+
+	fake.PlaceOrder(ctx, upstox.OrderRequest{InstrumentToken: "NSE_FO|B", Quantity: 1, TransactionType: "BUY", OrderType: "LIMIT", Price: 90})
+	fake.PlaceOrder(ctx, upstox.OrderRequest{InstrumentToken: "NSE_EQ|C", Quantity: 1, TransactionType: "BUY", OrderType: "LIMIT", Price: 90})
+	cancelled, err := fake.CancelAllOrders(ctx, upstox.CancelSegment("NSE_EQ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancelled.Data.OrderIDs) != 1 {
+		t.Errorf("cancelled %v, want only the NSE_EQ order", cancelled.Data.OrderIDs)
+	}
+}
+
+func TestFakeFailNext(t *testing.T) {
+	ctx := context.Background()
+	fake := upstoxtest.NewFake()
+	boom := errors.New("boom")
+
+	fake.FailNext("PlaceLimitOrder", boom)
+	if _, err := fake.PlaceLimitOrder(ctx, "NSE_EQ|A", 1, "BUY", 10); !errors.Is(err, boom) {
+		t.Fatalf("error = %v, want boom", err)
+	}
+	if _, err := fake.PlaceLimitOrder(ctx, "NSE_EQ|A", 1, "BUY", 10); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if orders, _ := fake.GetOrderBook(ctx); len(orders) != 1 {
+		t.Errorf("order book has %d orders, want 1", len(orders))
+	}
+
+	fake.FailNext("GetLTP", boom)
+	if _, err := fake.GetLTP(ctx, "NSE_EQ|A"); !errors.Is(err, boom) {
+		t.Errorf("GetLTP error = %v", err)
+	}
+}
+
+func TestFakeClosePositions(t *testing.T) {
+	ctx := context.Background()
+	fake := upstoxtest.NewFake()
+	fake.SetPositions(
+		upstox.Position{InstrumentToken: "NSE_EQ|A", Quantity: 5},
+		upstox.Position{InstrumentToken: "NSE_EQ|B", Quantity: -2},
+		upstox.Position{InstrumentToken: "NSE_EQ|C"},
+	)
+
+	responses, err := fake.CloseAllPositions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("%d exit orders, want 2", len(responses))
+	}
+	for _, c := range fake.Calls() {
+		if c.Method != "CloseAllPositions" {
+			t.Errorf("CloseAllPositions recorded a %s call", c.Method)
+		}
+	}
+
+	orders, _ := fake.GetOrderBook(ctx)
+	if orders[0].TransactionType != "SELL" || orders[0].Quantity != 5 || orders[1].TransactionType != "BUY" || orders[1].Quantity != 2 {
+		t.Errorf("exit orders = %+v", orders)
+	}
+
+	if _, err := fake.ClosePosition(ctx, "NSE_EQ|C"); err == nil {
+		t.Error("closed a flat position")
+	}
+	if n := len(fake.CallsTo("ClosePosition")); n != 1 {
+		t.Errorf("%d ClosePosition calls recorded", n)
+	}
+}
+
+func TestFakeMarketData(t *testing.T) {
+	ctx := context.Background()
+	fake := upstoxtest.NewFake()
+	day := time.Date(2025, 1, 2, 9, 15, 0, 0, upstox.IST)
+	fake.SetCandles("NSE_EQ|A",
+		upstox.Candle{Timestamp: day, Close: 1},
+		upstox.Candle{Timestamp: day.Add(time.Minute), Close: 2},
+		upstox.Candle{Timestamp: day.Add(2 * time.Minute), Close: 3},
+	)
+	candles, err := fake.GetHistoricalCandles(ctx, "NSE_EQ|A", upstox.I1, day.Add(time.Minute), day.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candles) != 2 || candles[0].Close != 2 {
+		t.Errorf("candles = %+v", candles)
+	}
+
+	wsm, err := fake.NewWebSocketManager(ctx, []string{"NSE_EQ|A"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ticks []upstox.Tick
+	wsm.AddTickListener(func(tick upstox.Tick) { ticks = append(ticks, tick) })
+
+	fake.PushTick(upstox.Tick{InstrumentKey: "NSE_EQ|A", LTP: 101.5})
+	if len(ticks) != 1 || ticks[0].LTP != 101.5 {
+		t.Errorf("ticks = %+v", ticks)
+	}
+	prices, _ := fake.GetLTP(ctx, "NSE_EQ|A", "NSE_EQ|Z")
+	if len(prices) != 1 || prices["NSE_EQ|A"] != 101.5 {
+		t.Errorf("prices = %v", prices)
+	}
+}
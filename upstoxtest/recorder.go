@@ -0,0 +1,264 @@
+package upstoxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// ModeReplay answers requests from the golden file and fails any request
+	// it has no recording for.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real API and records the exchanges;
+	// Save writes them out.
+	ModeRecord
+	// ModeAuto replays if the golden file exists and records otherwise.
+	ModeAuto
+)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Body       string      `json:"body,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Response   string      `json:"response"`
+}
+
+// Recorder is an http.RoundTripper that records API exchanges to a golden
+// file and replays them, so tests run offline and deterministically:
+//
+//	rec, err := upstoxtest.NewRecorder("testdata/orders.json", upstoxtest.ModeAuto)
+//	m := upstox.NewManager(id, secret, token, upstox.WithTransport(rec))
+//	defer rec.Save()
+//
+// The Authorization header is never stored. Tokens, secrets and codes in
+// query strings and JSON or form bodies are replaced by "REDACTED", as is any
+// string passed to Redact. Replay matches on method, redacted URL and body,
+// and hands out repeated identical requests in recorded order.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	secrets      []string
+}
+
+const redacted = "REDACTED"
+
+var (
+	secretParams = []string{"access_token", "extended_token", "client_secret", "code", "client_id"}
+	secretJSON   = regexp.MustCompile(`"(access_token|extended_token|client_secret|code)"(\s*:\s*)"[^"]*"`)
+)
+
+// NewRecorder loads path for replay, or prepares to record to it. Recording
+// uses http.DefaultTransport unless Transport is set afterwards.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, transport: http.DefaultTransport}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if mode == ModeAuto {
+			r.mode = ModeReplay
+		}
+	case errors.Is(err, os.ErrNotExist) && mode != ModeReplay:
+		r.mode = ModeRecord
+		return r, nil
+	default:
+		return nil, fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	if r.mode == ModeReplay {
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("failed to parse golden file %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+// Transport sets the RoundTripper used while recording.
+func (r *Recorder) Transport(rt http.RoundTripper) {
+	r.transport = rt
+}
+
+// Recording reports whether requests go to the real API.
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// Redact adds literal strings, such as the access token or client ID, to
+// scrub from everything recorded.
+func (r *Recorder) Redact(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	r.mu.Lock()
+	key := Interaction{Method: req.Method, URL: r.redactURL(req.URL), Body: r.redactBody(string(body))}
+	r.mu.Unlock()
+
+	if r.mode == ModeReplay {
+		return r.replay(req, key)
+	}
+	return r.record(req, key)
+}
+
+func (r *Recorder) replay(req *http.Request, key Interaction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, it := range r.interactions {
+		if r.used[i] || it.Method != key.Method || it.URL != key.URL || it.Body != key.Body {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", it.StatusCode, http.StatusText(it.StatusCode)),
+			StatusCode:    it.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        it.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(it.Response)),
+			ContentLength: int64(len(it.Response)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s", key.Method, key.URL)
+}
+
+func (r *Recorder) record(req *http.Request, key Interaction) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key.StatusCode = resp.StatusCode
+	key.Header = header
+	key.Response = r.redactBody(string(body))
+	r.interactions = append(r.interactions, key)
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the golden file. It does nothing
+// when replaying.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode interactions: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create golden file directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write golden file: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded interactions no request has replayed, for
+// tests that want to assert every expected call was made. It is always
+// empty while recording.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode != ModeReplay {
+		return nil
+	}
+	var unused []Interaction
+	for i, it := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, it)
+		}
+	}
+	return unused
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	clone := *u
+	q := clone.Query()
+	for _, p := range secretParams {
+		if q.Has(p) {
+			q.Set(p, redacted)
+		}
+	}
+	clone.RawQuery = q.Encode()
+	return r.redactSecrets(clone.String())
+}
+
+func (r *Recorder) redactBody(body string) string {
+	if body == "" {
+		return ""
+	}
+	body = secretJSON.ReplaceAllString(body, `"$1"$2"`+redacted+`"`)
+	if form, err := url.ParseQuery(body); err == nil && !strings.HasPrefix(strings.TrimSpace(body), "{") {
+		changed := false
+		for _, p := range secretParams {
+			if form.Has(p) {
+				form.Set(p, redacted)
+				changed = true
+			}
+		}
+		if changed {
+			body = form.Encode()
+		}
+	}
+	return r.redactSecrets(body)
+}
+
+func (r *Recorder) redactSecrets(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
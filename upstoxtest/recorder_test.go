@@ -0,0 +1,133 @@
+package upstoxtest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adeludedperson/go-upstox"
+	"github.com/adeludedperson/go-upstox/upstoxtest"
+)
+
+const testToken = "test-access-token"
+
+// stubAPI answers the positions and order placement calls the way the real
+// API does, standing in for it while recording.
+type stubAPI struct{}
+
+func (stubAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/portfolio/short-term-positions"):
+		body = `{"status":"success","data":[{"instrument_token":"NSE_EQ|INE062A01020","product":"I","quantity":10,"average_price":812.5,"last_price":815.1,"unrealised":26,"realised":0}]}`
+	case strings.HasSuffix(req.URL.Path, "/order/place"):
+		body = `{"status":"success","data":{"order_ids":["250101000000001"]},"metadata":{"latency":12}}`
+	default:
+		body = `{"status":"error","errors":[{"errorCode":"UDAPI100060","message":"Resource not Found."}]}`
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func exercise(t *testing.T, m *upstox.Manager) {
+	t.Helper()
+	ctx := context.Background()
+
+	positions, err := m.GetPositions(ctx)
+	if err != nil {
+		t.Fatalf("GetPositions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Quantity != 10 || positions[0].AveragePrice != 812.5 {
+		t.Fatalf("GetPositions = %+v", positions)
+	}
+
+	resp, err := m.PlaceLimitOrder(ctx, "NSE_EQ|INE062A01020", 1, "BUY", 810)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	if got := resp.Data.OrderIDs; len(got) != 1 || got[0] != "250101000000001" {
+		t.Fatalf("order IDs = %v", got)
+	}
+}
+
+func TestRecorderReplay(t *testing.T) {
+	rec, err := upstoxtest.NewRecorder("testdata/positions_and_order.json", upstoxtest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := upstox.NewManager("client-id", "client-secret", testToken, upstox.WithTransport(rec))
+
+	exercise(t, m)
+	if unused := rec.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions: %+v", unused)
+	}
+}
+
+func TestRecorderReplayUnknownRequest(t *testing.T) {
+	rec, err := upstoxtest.NewRecorder("testdata/positions_and_order.json", upstoxtest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := upstox.NewManager("client-id", "client-secret", testToken, upstox.WithTransport(rec))
+
+	if _, err := m.GetHoldings(context.Background()); err == nil {
+		t.Fatal("GetHoldings succeeded without a recording")
+	}
+	if got := len(rec.Unused()); got != 2 {
+		t.Errorf("Unused() has %d interactions, want 2", got)
+	}
+}
+
+func TestRecorderRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.json")
+
+	rec, err := upstoxtest.NewRecorder(path, upstoxtest.ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Recording() {
+		t.Fatal("ModeAuto without a golden file should record")
+	}
+	rec.Transport(stubAPI{})
+	rec.Redact("client-id")
+	m := upstox.NewManager("client-id", "client-secret", testToken, upstox.WithTransport(rec))
+
+	exercise(t, m)
+	if unused := rec.Unused(); unused != nil {
+		t.Errorf("Unused() while recording = %+v", unused)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{testToken, "client-id"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("golden file contains %q", secret)
+		}
+	}
+
+	replay, err := upstoxtest.NewRecorder(path, upstoxtest.ModeAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Recording() {
+		t.Fatal("ModeAuto with a golden file should replay")
+	}
+	exercise(t, upstox.NewManager("client-id", "client-secret", testToken, upstox.WithTransport(replay)))
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions: %+v", unused)
+	}
+}
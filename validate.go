@@ -0,0 +1,55 @@
+package upstox
+
+import "fmt"
+
+// validateOrderRequest performs the checks that do not need instrument
+// metadata, so obviously malformed orders never reach the API.
+func validateOrderRequest(req OrderRequest) error {
+	if req.InstrumentToken == "" {
+		return fmt.Errorf("instrument token is required")
+	}
+	if req.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive, got %d", req.Quantity)
+	}
+	if req.TransactionType != string(OrderSideBuy) && req.TransactionType != string(OrderSideSell) {
+		return fmt.Errorf("invalid transaction type: %q", req.TransactionType)
+	}
+	if req.DisclosedQuantity < 0 || req.DisclosedQuantity > req.Quantity {
+		return fmt.Errorf("disclosed quantity %d must be between 0 and quantity %d", req.DisclosedQuantity, req.Quantity)
+	}
+
+	switch OrderType(req.OrderType) {
+	case OrderTypeMarket:
+		if req.Price != 0 {
+			return fmt.Errorf("market orders must not carry a price")
+		}
+	case OrderTypeLimit:
+		if req.Price <= 0 {
+			return fmt.Errorf("limit orders require a positive price")
+		}
+	case OrderTypeSL:
+		if req.Price <= 0 || req.TriggerPrice <= 0 {
+			return fmt.Errorf("SL orders require both price and trigger price")
+		}
+	case OrderTypeSLM:
+		if req.TriggerPrice <= 0 {
+			return fmt.Errorf("SL-M orders require a trigger price")
+		}
+	default:
+		return fmt.Errorf("invalid order type: %q", req.OrderType)
+	}
+
+	switch ProductType(req.Product) {
+	case ProductIntraday, ProductDelivery, ProductMTF:
+	default:
+		return fmt.Errorf("invalid product: %q", req.Product)
+	}
+
+	switch ValidityType(req.Validity) {
+	case ValidityDay, ValidityIOC:
+	default:
+		return fmt.Errorf("invalid validity: %q", req.Validity)
+	}
+
+	return validateVariety(req)
+}
@@ -0,0 +1,119 @@
+package upstox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNotModifiable = errors.New("order cannot be modified")
+
+type Variety string
+
+const (
+	VarietySimple Variety = "SIMPLE"
+	VarietyAMO    Variety = "AMO"
+	VarietyCO     Variety = "CO"
+	VarietyOCO    Variety = "OCO"
+)
+
+// Variety is AMO for after-market requests and SIMPLE otherwise; the API
+// derives it from IsAMO rather than taking it as a field.
+func (r OrderRequest) Variety() Variety {
+	if r.IsAMO {
+		return VarietyAMO
+	}
+	return VarietySimple
+}
+
+// OrderVariety returns the order's variety, falling back to IsAMO when the
+// API leaves the field empty.
+func (o *Order) OrderVariety() Variety {
+	if o.Variety != "" {
+		return Variety(o.Variety)
+	}
+	if o.IsAMO {
+		return VarietyAMO
+	}
+	return VarietySimple
+}
+
+// validateVariety checks the variety and validity combinations the exchange
+// rejects.
+func validateVariety(req OrderRequest) error {
+	if req.Variety() == VarietyAMO && ValidityType(req.Validity) == ValidityIOC {
+		return fmt.Errorf("AMO orders must be DAY orders, not IOC")
+	}
+	return nil
+}
+
+// validateModify applies the rules for amending an existing order: it must
+// still be working, IOC orders cannot be amended at all, and the new quantity
+// cannot drop below what has already filled.
+func validateModify(order *Order, modReq ModifyOrderRequest) error {
+	switch order.Status {
+	case "complete", "cancelled", "rejected":
+		return fmt.Errorf("%w: order %s is %s", ErrNotModifiable, order.OrderID, order.Status)
+	}
+	if ValidityType(order.Validity) == ValidityIOC {
+		return fmt.Errorf("%w: order %s is IOC", ErrNotModifiable, order.OrderID)
+	}
+	switch order.OrderVariety() {
+	case VarietyCO, VarietyOCO:
+		return fmt.Errorf("%w: %s orders are amended through their legs", ErrNotModifiable, order.OrderVariety())
+	}
+	if modReq.Quantity != 0 && modReq.Quantity < order.FilledQuantity {
+		return fmt.Errorf("%w: quantity %d is below filled quantity %d", ErrNotModifiable, modReq.Quantity, order.FilledQuantity)
+	}
+	return nil
+}
+
+// validateModifyRequest checks a modification on its own, without the order.
+func validateModifyRequest(modReq ModifyOrderRequest) error {
+	if modReq.OrderID == "" {
+		return fmt.Errorf("order ID is required")
+	}
+	if modReq.Quantity < 0 {
+		return fmt.Errorf("quantity must not be negative, got %d", modReq.Quantity)
+	}
+
+	switch ValidityType(modReq.Validity) {
+	case ValidityDay, ValidityIOC:
+	default:
+		return fmt.Errorf("invalid validity: %q", modReq.Validity)
+	}
+
+	switch OrderType(modReq.OrderType) {
+	case OrderTypeMarket:
+		if modReq.Price != 0 {
+			return fmt.Errorf("market orders must not carry a price")
+		}
+	case OrderTypeLimit:
+		if modReq.Price <= 0 {
+			return fmt.Errorf("limit orders require a positive price")
+		}
+	case OrderTypeSL:
+		if modReq.Price <= 0 || modReq.TriggerPrice <= 0 {
+			return fmt.Errorf("SL orders require both price and trigger price")
+		}
+	case OrderTypeSLM:
+		if modReq.TriggerPrice <= 0 {
+			return fmt.Errorf("SL-M orders require a trigger price")
+		}
+	default:
+		return fmt.Errorf("invalid order type: %q", modReq.OrderType)
+	}
+	return nil
+}
+
+// modifyOrder amends order after checking the modification against the
+// order's current state.
+func (m *Manager) modifyOrder(ctx context.Context, order *Order, modReq ModifyOrderRequest) (*OrderIDResponse, error) {
+	if err := validateModifyRequest(modReq); err != nil {
+		return nil, fmt.Errorf("invalid modification: %w", err)
+	}
+	if err := validateModify(order, modReq); err != nil {
+		return nil, err
+	}
+	return m.sendModify(ctx, modReq)
+}
@@ -0,0 +1,216 @@
+package upstox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Watchlist keeps named groups of instruments (keys or trading symbols) and
+// subscribes whole groups on a feed. Editing an active group subscribes or
+// unsubscribes the difference straight away; an instrument shared by several
+// active groups stays subscribed until the last of them is dropped. Changes
+// to instruments subscribed outside the watchlist are not tracked, so an
+// instrument that is also subscribed directly is dropped with its group.
+//
+// When created with a path, groups are loaded from and saved to that file as
+// JSON after every edit.
+type Watchlist struct {
+	feed *WebSocketManager
+	path string
+
+	mu     sync.Mutex
+	groups map[string][]string
+	active map[string]bool
+}
+
+// NewWatchlist binds a watchlist to feed. path may be empty for an in-memory
+// watchlist; a missing file starts empty.
+func NewWatchlist(feed *WebSocketManager, path string) (*Watchlist, error) {
+	w := &Watchlist{
+		feed:   feed,
+		path:   path,
+		groups: make(map[string][]string),
+		active: make(map[string]bool),
+	}
+	if path == "" {
+		return w, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watchlist: %w", err)
+	}
+	if err := json.Unmarshal(data, &w.groups); err != nil {
+		return nil, fmt.Errorf("failed to parse watchlist %s: %w", path, err)
+	}
+	return w, nil
+}
+
+func (w *Watchlist) Groups() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make([]string, 0, len(w.groups))
+	for name := range w.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (w *Watchlist) Group(name string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.groups[name])
+}
+
+// SetGroup replaces the members of a group, creating it if needed.
+func (w *Watchlist) SetGroup(name string, members ...string) error {
+	return w.edit(func() {
+		w.groups[name] = slices.Compact(slices.Sorted(slices.Values(members)))
+	})
+}
+
+func (w *Watchlist) AddToGroup(name string, members ...string) error {
+	return w.edit(func() {
+		merged := append(slices.Clone(w.groups[name]), members...)
+		w.groups[name] = slices.Compact(slices.Sorted(slices.Values(merged)))
+	})
+}
+
+func (w *Watchlist) RemoveFromGroup(name string, members ...string) error {
+	return w.edit(func() {
+		w.groups[name] = slices.DeleteFunc(slices.Clone(w.groups[name]), func(s string) bool {
+			return slices.Contains(members, s)
+		})
+	})
+}
+
+// DeleteGroup removes a group, unsubscribing it first if it is active.
+func (w *Watchlist) DeleteGroup(name string) error {
+	return w.edit(func() {
+		delete(w.groups, name)
+		delete(w.active, name)
+	})
+}
+
+func (w *Watchlist) SubscribeGroup(name string) error {
+	w.mu.Lock()
+	_, ok := w.groups[name]
+	w.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown watchlist group %q", name)
+	}
+
+	return w.edit(func() { w.active[name] = true })
+}
+
+func (w *Watchlist) UnsubscribeGroup(name string) error {
+	return w.edit(func() { delete(w.active, name) })
+}
+
+// edit applies change and then brings the socket and the file in line with
+// the new state. If the socket refuses the change, the groups are restored
+// and nothing is saved.
+func (w *Watchlist) edit(change func()) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	before, err := w.activeKeys()
+	if err != nil {
+		return err
+	}
+	groups, active := maps.Clone(w.groups), maps.Clone(w.active)
+	change()
+	after, err := w.activeKeys()
+	if err != nil {
+		// Leave the watchlist as it was rather than out of step with the socket.
+		w.groups, w.active = groups, active
+		return err
+	}
+
+	var add, drop []string
+	for key := range after {
+		if !before[key] {
+			add = append(add, key)
+		}
+	}
+	for key := range before {
+		if !after[key] {
+			drop = append(drop, key)
+		}
+	}
+
+	if len(add) > 0 {
+		if err := w.feed.Subscribe(add...); err != nil {
+			w.groups, w.active = groups, active
+			return fmt.Errorf("failed to subscribe watchlist instruments: %w", err)
+		}
+	}
+	if len(drop) > 0 {
+		if err := w.feed.Unsubscribe(drop...); err != nil {
+			w.groups, w.active = groups, active
+			if len(add) > 0 {
+				w.feed.Unsubscribe(add...)
+			}
+			return fmt.Errorf("failed to unsubscribe watchlist instruments: %w", err)
+		}
+	}
+
+	return w.save()
+}
+
+func (w *Watchlist) activeKeys() (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for name := range w.active {
+		resolved, err := w.feed.resolve(w.groups[name])
+		if err != nil {
+			return nil, fmt.Errorf("watchlist group %q: %w", name, err)
+		}
+		for _, key := range resolved {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
+
+// save writes the groups through a temporary file so a crash never leaves a
+// truncated watchlist behind.
+func (w *Watchlist) save() error {
+	if w.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(w.groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watchlist: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), ".watchlist-*")
+	if err != nil {
+		return fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), w.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save watchlist: %w", err)
+	}
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
-	"log"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,23 +16,92 @@ import (
 )
 
 type WebSocketManager struct {
-	ws                   *websocket.Conn
-	url                  string
-	config               WebSocketConfig
-	onPriceUpdate        func(symbol string, price float64, ltq *int32)
-	reconnectAttempts    int
-	maxReconnectAttempts int
-	reconnectDelay       time.Duration
-	isConnecting         bool
-	shouldReconnect      bool
-	mu                   sync.RWMutex
-	ctx                  context.Context
-	cancel               context.CancelFunc
+	ws                *websocket.Conn
+	url               string
+	config            WebSocketConfig
+	onPriceUpdate     func(symbol string, price float64, ltq *int32)
+	onLiveFeed        LiveFeedCallback
+	onDepth           DepthCallback
+	onMarketInfo      MarketInfoCallback
+	onRawMessage      func(messageType int, data []byte)
+	logger            Logger
+	hooks             feedHooks
+	reconnectAttempts int
+	isConnecting      bool
+	shouldReconnect   bool
+	mu                sync.RWMutex
+	writeMu           sync.Mutex
+	ctx               context.Context
+	cancel            context.CancelFunc
+
+	// authorize fetches a fresh feed URL; it is set when the manager is created
+	// through Manager.NewWebSocketManager and used after a token rotation.
+	authorize func() (string, error)
+	urlStale  bool
+	release   func()
+
+	// feedResponse is reused for every frame. It is only touched by the read
+	// goroutine, and nothing derived from it outlives processMessage.
+	feedResponse pb.FeedResponse
+
+	fastPrice  PriceHandler
+	symbols    map[string]string
+	lookup     InstrumentLookup
+	batcher    *tickBatcher
+	conflator  *tickConflator
+	dispatcher *TickDispatcher
+	feed       *feedChannel
+	listeners  []*tickListener
+
+	syntheticsByLeg map[string][]*SyntheticInstrument
+	synthetics      map[string]*SyntheticInstrument
+
+	subs          *subscriptionTable
+	modes         map[string]SubscriptionMode
+	segmentStatus map[string]MarketStatus
+
+	decodeLatency   LatencyHistogram
+	dispatchLatency LatencyHistogram
 }
 
+var feedUnmarshalOptions = proto.UnmarshalOptions{Merge: true}
+
 type WebSocketConfig struct {
 	InstrumentKeys []string
 	Token          string
+
+	// Mode is the subscription mode used for InstrumentKeys; empty means ltpc.
+	Mode SubscriptionMode
+
+	// Reconnect governs reconnection after the connection drops.
+	Reconnect ReconnectPolicy
+
+	// FeedBuffer sizes the channel returned by Feeds and FeedPolicy picks
+	// what happens when it is full.
+	FeedBuffer int
+	FeedPolicy BackpressurePolicy
+
+	// PingInterval is how often the connection is pinged, and StaleTimeout
+	// how long it may stay silent, with neither frames nor pongs, before it is
+	// treated as dead and reconnected. Zero uses 10s and 30s; negative
+	// disables.
+	PingInterval time.Duration
+	StaleTimeout time.Duration
+
+	// ReadBufferSize sizes the socket read buffer; zero keeps the gorilla
+	// default. Large buffers pay off for full_d30 frames on many instruments.
+	ReadBufferSize int
+}
+
+// HighThroughputConfig subscribes instrumentKeys in full_d30 with a 1 MiB
+// read buffer. It sets nothing else; see the package documentation for the
+// batching and fast-price options worth pairing it with.
+func HighThroughputConfig(instrumentKeys []string) WebSocketConfig {
+	return WebSocketConfig{
+		InstrumentKeys: instrumentKeys,
+		Mode:           ModeFullD30,
+		ReadBufferSize: 1 << 20,
+	}
 }
 
 type SubscriptionMessage struct {
@@ -49,22 +118,25 @@ type SubscriptionMessageData struct {
 func NewWebSocketManager(url string, config WebSocketConfig, onPriceUpdate func(string, float64, *int32)) *WebSocketManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WebSocketManager{
-		url:                  url,
-		config:               config,
-		onPriceUpdate:        onPriceUpdate,
-		maxReconnectAttempts: 3,
-		reconnectDelay:       time.Second,
-		shouldReconnect:      true,
-		ctx:                  ctx,
-		cancel:               cancel,
+		url:             url,
+		config:          config,
+		onPriceUpdate:   onPriceUpdate,
+		shouldReconnect: true,
+		logger:          nopLogger{},
+		ctx:             ctx,
+		cancel:          cancel,
+		subs:            newSubscriptionTable(),
 	}
 }
 
 func (wsm *WebSocketManager) connect() error {
-	wsm.mu.Lock()
-	defer wsm.mu.Unlock()
+	if err := wsm.refreshURL(); err != nil {
+		return err
+	}
 
+	wsm.mu.Lock()
 	if wsm.isConnecting || wsm.ws != nil {
+		wsm.mu.Unlock()
 		return nil
 	}
 
@@ -72,44 +144,58 @@ func (wsm *WebSocketManager) connect() error {
 
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		ReadBufferSize:   wsm.config.ReadBufferSize,
 	}
 
 	conn, resp, err := dialer.Dial(wsm.url, nil)
 	if err != nil {
 		wsm.isConnecting = false
+		wsm.mu.Unlock()
 		if resp != nil {
-			log.Printf("WebSocket handshake failed with status: %s", resp.Status)
+			wsm.logger.Error("websocket handshake failed", "status", resp.Status)
 		}
 		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 
 	wsm.ws = conn
 	wsm.reconnectAttempts = 0
-	wsm.reconnectDelay = time.Second
 	wsm.isConnecting = false
+	wsm.mu.Unlock()
 
-	go wsm.handleMessages()
+	go wsm.handleMessages(conn)
 
-	// Only subscribe if we have instrument keys
-	if len(wsm.config.InstrumentKeys) > 0 {
-		return wsm.subscribe()
+	// Replay every subscription in its own mode, so instruments added or
+	// switched since the first connect survive a reconnect unchanged.
+	if err := wsm.resubscribe(conn); err != nil {
+		return err
 	}
 
+	wsm.fireConnect()
 	return nil
 }
 
 func (wsm *WebSocketManager) subscribe() error {
+	return wsm.resubscribe(wsm.ws)
+}
+
+// sendSubscription writes one control message on conn. Writes are serialised
+// because gorilla connections support only one concurrent writer.
+func (wsm *WebSocketManager) sendSubscription(conn *websocket.Conn, method string, mode SubscriptionMode, instrumentKeys []string) error {
 	guid, err := generateGUID()
 	if err != nil {
 		return fmt.Errorf("failed to generate GUID: %w", err)
 	}
 
+	if mode == "" {
+		mode = ModeLTPC
+	}
+
 	subscribeMsg := SubscriptionMessage{
 		GUID:   guid,
-		Method: "sub",
+		Method: method,
 		Data: SubscriptionMessageData{
-			Mode:           "ltpc",
-			InstrumentKeys: wsm.config.InstrumentKeys,
+			Mode:           string(mode),
+			InstrumentKeys: instrumentKeys,
 		},
 	}
 
@@ -119,123 +205,162 @@ func (wsm *WebSocketManager) subscribe() error {
 	}
 
 	// Per Upstox V3 docs: "The WebSocket request message should be sent in binary format"
-	return wsm.ws.WriteMessage(websocket.BinaryMessage, msgBytes)
+	wsm.writeMu.Lock()
+	err = conn.WriteMessage(websocket.BinaryMessage, msgBytes)
+	wsm.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, key := range instrumentKeys {
+		if method == "unsub" {
+			wsm.subs.remove(key)
+		} else {
+			wsm.subs.set(key, mode, now)
+		}
+	}
+	return nil
 }
 
-func (wsm *WebSocketManager) handleMessages() {
-	defer func() {
-		wsm.mu.Lock()
-		wsm.ws = nil
-		wsm.mu.Unlock()
-	}()
+func (wsm *WebSocketManager) handleMessages(conn *websocket.Conn) {
+	labelGoroutine("feed_read")
+
+	stopHeartbeat := wsm.startHeartbeat(conn)
+	defer stopHeartbeat()
+	defer wsm.dropConn(conn)
 
 	for {
 		select {
 		case <-wsm.ctx.Done():
 			return
 		default:
-			messageType, data, err := wsm.ws.ReadMessage()
+			messageType, data, err := conn.ReadMessage()
 			if err != nil {
-				log.Printf("WebSocket read error: %v", err)
-				wsm.handleDisconnect()
+				wsm.log().Warn("websocket read failed", "error", err)
+				if wsm.ctx.Err() != nil {
+					err = ErrFeedStopped
+				}
+				wsm.dropConn(conn)
+				wsm.fireDisconnect(err)
+				wsm.handleDisconnect(err)
 				return
 			}
+			wsm.extendReadDeadline(conn)
+
+			wsm.mu.RLock()
+			onRaw := wsm.onRawMessage
+			wsm.mu.RUnlock()
+			if onRaw != nil {
+				onRaw(messageType, data)
+			}
 
 			if messageType == websocket.BinaryMessage {
 				wsm.processMessage(data)
 			} else if messageType == websocket.TextMessage {
-				log.Printf("Unexpected text message: %s", string(data))
+				wsm.log().Debug("unexpected text message", "data", string(data))
 			}
 		}
 	}
 }
 
-func (wsm *WebSocketManager) processMessage(data []byte) {
+// OnRawMessage registers fn to see every frame exactly as read from the
+// socket, with its gorilla/websocket message type, before it is decoded, e.g.
+// to archive frames for later replay through HandleFrame. Decoding and
+// delivery carry on as usual. fn runs on the read goroutine and may retain
+// data but must not modify it.
+func (wsm *WebSocketManager) OnRawMessage(fn func(messageType int, data []byte)) {
+	wsm.mu.Lock()
+	defer wsm.mu.Unlock()
+	wsm.onRawMessage = fn
+}
 
-	var feedResponse pb.FeedResponse
-	if err := proto.Unmarshal(data, &feedResponse); err != nil {
-		log.Printf("Failed to unmarshal protobuf message: %v", err)
+// HandleFrame processes one binary feed frame exactly as if it had been read
+// from the socket. It exists for replay tooling and benchmarks and must not be
+// called concurrently with a live connection's read loop.
+func (wsm *WebSocketManager) HandleFrame(data []byte) {
+	wsm.processMessage(data)
+}
+
+func (wsm *WebSocketManager) processMessage(data []byte) {
+	wsm.mu.RLock()
+	fastPrice := wsm.fastPrice
+	wsm.mu.RUnlock()
+
+	start := time.Now()
+	if fastPrice != nil {
+		if err := wsm.processFastPrice(data, fastPrice); err != nil {
+			wsm.log().Error("failed to scan feed frame", "error", err)
+			wsm.fireError(fmt.Errorf("failed to decode feed frame: %w", err))
+		}
+		wsm.decodeLatency.Since(start)
 		return
 	}
 
-	// log.Printf("Processed feed response with %d symbols", len(feedResponse.Feeds))
-	// log.Printf("Feed Response: %+v", feedResponse)
+	feedResponse := wsm.resetFeedResponse()
+	if err := feedUnmarshalOptions.Unmarshal(data, feedResponse); err != nil {
+		wsm.log().Error("failed to unmarshal feed frame", "error", err)
+		wsm.fireError(fmt.Errorf("failed to decode feed frame: %w", err))
+		return
+	}
+	wsm.decodeLatency.Since(start)
 
+	if feedResponse.Type == pb.Type_market_info {
+		wsm.updateMarketInfo(feedResponse)
+		return
+	}
 	if feedResponse.Type != pb.Type_live_feed && feedResponse.Type != pb.Type_initial_feed {
 		return
 	}
 
-	for symbol, feed := range feedResponse.Feeds {
-		var ltp float64
-		var ltq *int32
-
-		switch feedUnion := feed.FeedUnion.(type) {
-		case *pb.Feed_Ltpc:
-			ltp = float64(feedUnion.Ltpc.Ltp)
-			if feedUnion.Ltpc.Ltq != 0 {
-				ltqVal := int32(feedUnion.Ltpc.Ltq)
-				ltq = &ltqVal
-			}
+	receivedAt := time.Now()
+	defer wsm.dispatchLatency.Since(receivedAt)
 
-		case *pb.Feed_FullFeed:
-			fullFeed := feedUnion.FullFeed
-			switch fullFeedUnion := fullFeed.FullFeedUnion.(type) {
-			case *pb.FullFeed_MarketFF:
-				if fullFeedUnion.MarketFF.Ltpc != nil {
-					ltp = float64(fullFeedUnion.MarketFF.Ltpc.Ltp)
-					if fullFeedUnion.MarketFF.Ltpc.Ltq != 0 {
-						ltqVal := int32(fullFeedUnion.MarketFF.Ltpc.Ltq)
-						ltq = &ltqVal
-					}
-				}
-			case *pb.FullFeed_IndexFF:
-				if fullFeedUnion.IndexFF.Ltpc != nil {
-					ltp = float64(fullFeedUnion.IndexFF.Ltpc.Ltp)
-					if fullFeedUnion.IndexFF.Ltpc.Ltq != 0 {
-						ltqVal := int32(fullFeedUnion.IndexFF.Ltpc.Ltq)
-						ltq = &ltqVal
-					}
-				}
-			}
+	wsm.mu.RLock()
+	onLiveFeed, onDepth := wsm.onLiveFeed, wsm.onDepth
+	wsm.mu.RUnlock()
+	if onLiveFeed != nil {
+		onLiveFeed(liveFeedMessage(feedResponse))
+	}
 
-		case *pb.Feed_FirstLevelWithGreeks:
-			if feedUnion.FirstLevelWithGreeks.Ltpc != nil {
-				ltp = float64(feedUnion.FirstLevelWithGreeks.Ltpc.Ltp)
-				if feedUnion.FirstLevelWithGreeks.Ltpc.Ltq != 0 {
-					ltqVal := int32(feedUnion.FirstLevelWithGreeks.Ltpc.Ltq)
-					ltq = &ltqVal
-				}
+	for symbol, feed := range feedResponse.Feeds {
+		if g := feedGreeksMessage(feed); g != nil {
+			wsm.subs.setGreeks(symbol, OptionGreeks{Delta: g.Delta, Theta: g.Theta, Gamma: g.Gamma, Vega: g.Vega, Rho: g.Rho})
+		}
+		if onDepth != nil {
+			if depth := feedDepth(symbol, feed, receivedAt); depth != nil {
+				onDepth(depth)
 			}
 		}
 
-		if ltp > 0 && wsm.onPriceUpdate != nil {
-			wsm.onPriceUpdate(symbol, ltp, ltq)
+		ltpc := feedLTPCMessage(feed)
+		if ltpc == nil || ltpc.Ltp <= 0 {
+			continue
 		}
-	}
-}
 
-func (wsm *WebSocketManager) handleDisconnect() {
-	if !wsm.shouldReconnect {
-		return
-	}
-
-	if wsm.reconnectAttempts < wsm.maxReconnectAttempts {
-		wsm.reconnectAttempts++
-		wsm.reconnectDelay *= 2
-
-		log.Printf("Reconnecting attempt %d in %v", wsm.reconnectAttempts, wsm.reconnectDelay)
-
-		time.AfterFunc(wsm.reconnectDelay, func() {
-			if err := wsm.connect(); err != nil {
-				log.Printf("Reconnection failed: %v", err)
-			}
+		wsm.dispatch(Tick{
+			InstrumentKey: symbol,
+			LTP:           ltpc.Ltp,
+			LTQ:           ltpc.Ltq,
+			LTT:           ltpc.Ltt,
+			CP:            ltpc.Cp,
+			ReceivedAt:    receivedAt,
 		})
-	} else {
-		log.Printf("Max reconnection attempts reached")
-		wsm.Stop()
 	}
 }
 
+// resetFeedResponse clears the reusable FeedResponse while keeping the feeds
+// map's buckets, so a merge-unmarshal of the next frame does not have to grow
+// a fresh map for every tick.
+func (wsm *WebSocketManager) resetFeedResponse() *pb.FeedResponse {
+	fr := &wsm.feedResponse
+	feeds := fr.Feeds
+	clear(feeds)
+	fr.Reset()
+	fr.Feeds = feeds
+	return fr
+}
+
 func (wsm *WebSocketManager) Start() error {
 	wsm.shouldReconnect = true
 	return wsm.connect()
@@ -245,6 +370,17 @@ func (wsm *WebSocketManager) Stop() {
 	wsm.shouldReconnect = false
 	wsm.cancel()
 
+	wsm.mu.RLock()
+	feed := wsm.feed
+	wsm.mu.RUnlock()
+	if feed != nil {
+		feed.close()
+	}
+
+	if wsm.release != nil {
+		wsm.release()
+	}
+
 	wsm.mu.Lock()
 	defer wsm.mu.Unlock()
 
@@ -270,6 +406,11 @@ func generateGUID() (string, error) {
 func (wsm *WebSocketManager) UpdateInstruments(instrumentKeys []string) error {
 	wsm.mu.Lock()
 	wsm.config.InstrumentKeys = instrumentKeys
+	for key := range wsm.modes {
+		if !slices.Contains(instrumentKeys, key) {
+			delete(wsm.modes, key)
+		}
+	}
 	wsm.mu.Unlock()
 
 	if wsm.ws != nil {
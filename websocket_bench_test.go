@@ -0,0 +1,87 @@
+package upstox
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/adeludedperson/go-upstox/pb"
+)
+
+// The decode/dispatch numbers quoted in the package documentation come from
+// these benchmarks. Frames are synthetic, so no credentials or network access
+// are needed:
+//
+//	go test -run '^$' -bench HandleFrame -benchmem
+
+func BenchmarkHandleFrameLTPC(b *testing.B) {
+	frame := marshalFrame(b, map[string]*pb.Feed{
+		"NSE_EQ|INE002A01018": {
+			FeedUnion:   &pb.Feed_Ltpc{Ltpc: &pb.LTPC{Ltp: 1000, Ltt: 1700000000000, Ltq: 10, Cp: 990}},
+			RequestMode: pb.RequestMode_ltpc,
+		},
+	})
+	benchmarkHandleFrame(b, frame, func(*WebSocketManager) {})
+}
+
+func BenchmarkHandleFrameFullD30(b *testing.B) {
+	for _, instruments := range []int{1, 50, 200} {
+		frame := fullD30Frame(b, instruments)
+		b.Run(fmt.Sprintf("%d/callback", instruments), func(b *testing.B) {
+			benchmarkHandleFrame(b, frame, func(*WebSocketManager) {})
+		})
+		b.Run(fmt.Sprintf("%d/fast_price", instruments), func(b *testing.B) {
+			benchmarkHandleFrame(b, frame, func(ws *WebSocketManager) {
+				ws.OnFastPrice(func(string, float64, int64) {})
+			})
+		})
+	}
+}
+
+func benchmarkHandleFrame(b *testing.B, frame []byte, setup func(*WebSocketManager)) {
+	ws := NewWebSocketManager("", WebSocketConfig{}, func(string, float64, *int32) {})
+	setup(ws)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(frame)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ws.HandleFrame(frame)
+	}
+}
+
+func fullD30Frame(b *testing.B, instruments int) []byte {
+	feeds := make(map[string]*pb.Feed, instruments)
+	for i := 0; i < instruments; i++ {
+		quotes := make([]*pb.Quote, 30)
+		for lvl := range quotes {
+			quotes[lvl] = &pb.Quote{
+				BidQ: int64(100 + lvl), BidP: 1000 - float64(lvl)*0.05,
+				AskQ: int64(120 + lvl), AskP: 1000.05 + float64(lvl)*0.05,
+			}
+		}
+		feeds[fmt.Sprintf("NSE_FO|%d", 40000+i)] = &pb.Feed{
+			FeedUnion: &pb.Feed_FullFeed{FullFeed: &pb.FullFeed{
+				FullFeedUnion: &pb.FullFeed_MarketFF{MarketFF: &pb.MarketFullFeed{
+					Ltpc:        &pb.LTPC{Ltp: 1000, Ltt: 1700000000000, Ltq: 75, Cp: 990},
+					MarketLevel: &pb.MarketLevel{BidAskQuote: quotes},
+					MarketOHLC: &pb.MarketOHLC{Ohlc: []*pb.OHLC{
+						{Interval: "1d", Open: 990, High: 1010, Low: 985, Close: 1000, Vol: 123456},
+						{Interval: "I1", Open: 999, High: 1001, Low: 998, Close: 1000, Vol: 1234},
+					}},
+					Atp: 999.5, Vtt: 123456, Oi: 654321, Iv: 0.18, Tbq: 50000, Tsq: 48000,
+				}},
+			}},
+			RequestMode: pb.RequestMode_full_d30,
+		}
+	}
+	return marshalFrame(b, feeds)
+}
+
+func marshalFrame(b *testing.B, feeds map[string]*pb.Feed) []byte {
+	frame, err := proto.Marshal(&pb.FeedResponse{Type: pb.Type_live_feed, Feeds: feeds, CurrentTs: 1700000000000})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return frame
+}